@@ -0,0 +1,6 @@
+/**
+ * Content of a fixture as bytes, encoding strings as UTF-8
+ */
+export function fixtureBytes(content: Uint8Array | string): Buffer {
+  return typeof content === 'string' ? Buffer.from(content, 'utf8') : Buffer.from(content);
+}
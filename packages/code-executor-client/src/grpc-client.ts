@@ -1,5 +1,6 @@
-import { credentials, ChannelCredentials, Client } from '@grpc/grpc-js';
-import { ExecuteOptions, ExecutionResult, HealthCheckResult, GrpcClientConfig } from './types';
+import { credentials, ChannelCredentials, Client, Metadata } from '@grpc/grpc-js';
+import { BenchmarkOptions, BenchmarkResult, ComplexityFit, ComplexityOptions, ComplexityResult, ExecuteOptions, ExecutionResult, HealthCheckResult, GrpcClientConfig, LanguageInfo, ReplayOptions, ReplayResult } from './types';
+import { fixtureBytes } from './fixtures';
 
 // Import generated types (will be available after running generate script)
 let ExecutorProto: any;
@@ -19,6 +20,24 @@ try {
     timeoutSeconds: number;
     memoryLimitMb: number;
     cpuLimit: number;
+    cpuTimeLimitMs: number;
+    usageIntervalMs: number;
+    combinedOutput: boolean;
+    artifacts: string[];
+    fixtures: { name: string; content: Uint8Array; id: string }[];
+    args: string[];
+    env: { [key: string]: string };
+    manifest?: { name: string; content: string };
+    determinism?: ProtoDeterminism;
+  }
+
+  interface ProtoDeterminism {
+    seed: number;
+    fakeTimeUnix: number;
+    pinCpu: boolean;
+    timezone: string;
+    locale: string;
+    cpuSet: string;
   }
 
   interface ExecuteResponse {
@@ -28,7 +47,37 @@ try {
     timeout: boolean;
     memoryExceeded: boolean;
     executionTimeMs: number;
+    cpuTimeMs: number;
+    cpuUserTimeMs: number;
+    cpuSystemTimeMs: number;
+    cpuTimeExceeded: boolean;
+    startupTimeMs: number;
+    imagePullTimeMs: number;
     memoryUsedMb: number;
+    stdoutTruncated: boolean;
+    stderrTruncated: boolean;
+    stdoutBytes: number;
+    stderrBytes: number;
+    output: { stream: string; data: string; offsetMs: number }[];
+    usage: {
+      offsetMs: number;
+      cpuPercent: number;
+      rssBytes: number;
+      pids: number;
+      ioReadBytes: number;
+      ioWriteBytes: number;
+    }[];
+    artifacts: { path: string; size: number; content: Uint8Array }[];
+    artifactsTruncated: boolean;
+    fixtures: { name: string; size: number; sha256: string }[];
+    version: string;
+    imageDigest: string;
+    dependenciesCached: boolean;
+    installTimeMs: number;
+    buildCached: boolean;
+    compileTimeMs: number;
+    determinism?: ProtoDeterminism;
+    executionId: string;
   }
 
   interface HealthRequest {}
@@ -36,6 +85,7 @@ try {
   interface HealthResponse {
     status: string;
     version: string;
+    missingImages: string[];
   }
 
   // Placeholder client
@@ -51,6 +101,7 @@ try {
  */
 export class GrpcClient {
   private client: CodeExecutorClient;
+  private metadata: Metadata;
 
   constructor(config: GrpcClientConfig) {
     const address = `${config.host}:${config.port}`;
@@ -65,6 +116,11 @@ export class GrpcClient {
       creds,
       config.options
     );
+
+    this.metadata = new Metadata();
+    if (config.apiKey) {
+      this.metadata.set('authorization', `Bearer ${config.apiKey}`);
+    }
   }
 
   /**
@@ -79,27 +135,194 @@ export class GrpcClient {
         timeoutSeconds: opts.timeoutSeconds || 30,
         memoryLimitMb: opts.memoryLimitMb || 128,
         cpuLimit: opts.cpuLimit || 0.5,
+        cpuTimeLimitMs: opts.cpuTimeLimitMs || 0,
+        usageIntervalMs: opts.usageIntervalMs || 0,
+        combinedOutput: opts.combinedOutput || false,
+        artifacts: opts.artifacts || [],
+        fixtures: (opts.fixtures || []).map((fixture) => ({
+          name: fixture.name,
+          content: fixture.content !== undefined ? fixtureBytes(fixture.content) : new Uint8Array(),
+          id: fixture.id || '',
+        })),
+        args: opts.args || [],
+        env: opts.env || {},
+        manifest: opts.manifest,
+        determinism: opts.determinism && {
+          seed: opts.determinism.seed || 0,
+          fakeTimeUnix: opts.determinism.fakeTime ? Math.floor(opts.determinism.fakeTime.getTime() / 1000) : 0,
+          pinCpu: opts.determinism.pinCpu || false,
+          timezone: '',
+          locale: '',
+          cpuSet: opts.determinism.cpuSet || '',
+        },
+      };
+
+      this.client.execute(request, this.metadata, (error, response) => {
+        if (error) {
+          reject(error);
+          return;
+        }
+
+        resolve(toResult(response));
+      });
+    });
+  }
+
+  /**
+   * Replay a stored execution and compare the result with the stored one
+   */
+  async replay(executionId: string, opts: ReplayOptions = {}): Promise<ReplayResult> {
+    return new Promise((resolve, reject) => {
+      const request = {
+        executionId,
+        latestImage: opts.latestImage || false,
+        version: opts.version || '',
+      };
+
+      this.client.replay(request, this.metadata, (error, response) => {
+        if (error) {
+          reject(error);
+          return;
+        }
+
+        resolve({
+          executionId: response.executionId,
+          identical: response.identical,
+          differences: (response.differences || []).map((difference: any) => ({
+            field: difference.field,
+            stored: difference.stored,
+            replayed: difference.replayed,
+          })),
+          result: toResult(response.result),
+        });
+      });
+    });
+  }
+
+  /**
+   * Run a submission on generated inputs of increasing size and fit its CPU time and memory to complexity classes
+   */
+  async analyzeComplexity(opts: ComplexityOptions): Promise<ComplexityResult> {
+    return new Promise((resolve, reject) => {
+      const request = {
+        language: opts.language,
+        code: opts.code,
+        generator: opts.generator,
+        sizes: opts.sizes,
+        repeats: opts.repeats || 0,
+        timeoutSeconds: opts.timeoutSeconds || 0,
+        memoryLimitMb: opts.memoryLimitMb || 0,
+        cpuLimit: opts.cpuLimit || 0,
+      };
+
+      this.client.analyzeComplexity(request, this.metadata, (error, response) => {
+        if (error) {
+          reject(error);
+          return;
+        }
+
+        const toFit = (fit: any): ComplexityFit | undefined =>
+          fit
+            ? {
+                class: fit.class,
+                confidence: fit.confidence,
+                candidates: (fit.candidates || []).map((candidate: any) => ({
+                  class: candidate.class,
+                  probability: candidate.probability,
+                })),
+              }
+            : undefined;
+
+        resolve({
+          measurements: (response.measurements || []).map((measurement: any) => ({
+            size: Number(measurement.size),
+            inputBytes: Number(measurement.inputBytes),
+            cpuTimeUs: Number(measurement.cpuTimeUs),
+            memoryBytes: Number(measurement.memoryBytes),
+            error: measurement.error || undefined,
+          })),
+          cpu: toFit(response.cpu),
+          memory: toFit(response.memory),
+        });
+      });
+    });
+  }
+
+  /**
+   * Run a submission repeatedly in a warm container and summarize its CPU time and memory, optionally against a reference solution
+   */
+  async benchmark(opts: BenchmarkOptions): Promise<BenchmarkResult> {
+    return new Promise((resolve, reject) => {
+      const request = {
+        language: opts.language,
+        code: opts.code,
+        input: opts.input || '',
+        args: opts.args || [],
+        runs: opts.runs || 0,
+        warmup: opts.warmup || 0,
+        referenceCode: opts.referenceCode || '',
+        timeoutSeconds: opts.timeoutSeconds || 0,
+        memoryLimitMb: opts.memoryLimitMb || 0,
+        cpuLimit: opts.cpuLimit || 0,
+        cpuTimeLimitMs: opts.cpuTimeLimitMs || 0,
       };
 
-      this.client.execute(request, (error, response) => {
+      this.client.benchmark(request, this.metadata, (error, response) => {
         if (error) {
           reject(error);
           return;
         }
 
+        const toSummary = (summary: any) => ({
+          min: summary?.min || 0,
+          median: summary?.median || 0,
+          p90: summary?.p90 || 0,
+          stddev: summary?.stddev || 0,
+          noisy: summary?.noisy || false,
+        });
+        const toMeasurements = (measurements: any) => ({
+          runs: measurements.runs || 0,
+          cpuTimeUs: toSummary(measurements.cpuTimeUs),
+          memoryBytes: toSummary(measurements.memoryBytes),
+          error: measurements.error || undefined,
+        });
+
         resolve({
-          stdout: response.stdout,
-          stderr: response.stderr,
-          exitCode: response.exitCode,
-          timeout: response.timeout,
-          memoryExceeded: response.memoryExceeded,
-          executionTimeMs: response.executionTimeMs,
-          memoryUsedMb: response.memoryUsedMb,
+          submission: toMeasurements(response.submission || {}),
+          reference: response.reference ? toMeasurements(response.reference) : undefined,
+          score: response.score || undefined,
+          noisy: response.noisy || false,
         });
       });
     });
   }
 
+  /**
+   * List the supported languages, their versions and which are installed
+   */
+  async languages(): Promise<LanguageInfo[]> {
+    return new Promise((resolve, reject) => {
+      this.client.listLanguages({}, this.metadata, (error, response) => {
+        if (error) {
+          reject(error);
+          return;
+        }
+
+        resolve((response.languages || []).map((language: any) => ({
+          name: language.name,
+          aliases: language.aliases || [],
+          versions: (language.versions || []).map((version: any) => ({
+            version: version.version,
+            image: version.image,
+            digest: version.digest || undefined,
+            installed: version.installed,
+            default: version.default,
+          })),
+        })));
+      });
+    });
+  }
+
   /**
    * Health check using gRPC
    */
@@ -114,6 +337,7 @@ export class GrpcClient {
         resolve({
           status: response.status,
           version: response.version,
+          missingImages: response.missingImages,
         });
       });
     });
@@ -127,6 +351,74 @@ export class GrpcClient {
   }
 }
 
+/**
+ * Map an execute response to the result type
+ */
+function toResult(response: any): ExecutionResult {
+  return {
+    stdout: response.stdout,
+    stderr: response.stderr,
+    exitCode: response.exitCode,
+    timeout: response.timeout,
+    memoryExceeded: response.memoryExceeded,
+    executionTimeMs: response.executionTimeMs,
+    cpuTimeMs: Number(response.cpuTimeMs),
+    cpuUserTimeMs: Number(response.cpuUserTimeMs),
+    cpuSystemTimeMs: Number(response.cpuSystemTimeMs),
+    cpuTimeExceeded: response.cpuTimeExceeded,
+    startupTimeMs: Number(response.startupTimeMs),
+    imagePullTimeMs: Number(response.imagePullTimeMs),
+    memoryUsedMb: response.memoryUsedMb,
+    stdoutTruncated: response.stdoutTruncated,
+    stderrTruncated: response.stderrTruncated,
+    stdoutBytes: Number(response.stdoutBytes),
+    stderrBytes: Number(response.stderrBytes),
+    output: (response.output || []).map((chunk: any) => ({
+      stream: chunk.stream,
+      data: chunk.data,
+      offsetMs: Number(chunk.offsetMs),
+    })),
+    usage: (response.usage || []).map((point: any) => ({
+      offsetMs: Number(point.offsetMs),
+      cpuPercent: point.cpuPercent,
+      rssBytes: Number(point.rssBytes),
+      pids: Number(point.pids),
+      ioReadBytes: Number(point.ioReadBytes),
+      ioWriteBytes: Number(point.ioWriteBytes),
+    })),
+    artifacts: (response.artifacts || []).map((artifact: any) => ({
+      path: artifact.path,
+      size: Number(artifact.size),
+      content: artifact.content,
+    })),
+    artifactsTruncated: response.artifactsTruncated,
+    fixtures: (response.fixtures || []).map((digest: any) => ({
+      name: digest.name,
+      size: Number(digest.size),
+      sha256: digest.sha256,
+    })),
+    version: response.version,
+    imageDigest: response.imageDigest,
+    dependenciesCached: response.dependenciesCached,
+    installTimeMs: Number(response.installTimeMs),
+    buildCached: response.buildCached,
+    compileTimeMs: Number(response.compileTimeMs),
+    determinism: response.determinism
+      ? {
+          seed: response.determinism.seed,
+          fakeTime: Number(response.determinism.fakeTimeUnix)
+            ? new Date(Number(response.determinism.fakeTimeUnix) * 1000)
+            : undefined,
+          pinCpu: response.determinism.pinCpu,
+          timezone: response.determinism.timezone,
+          locale: response.determinism.locale,
+          cpuSet: response.determinism.cpuSet || undefined,
+        }
+      : undefined,
+    executionId: response.executionId || undefined,
+  };
+}
+
 /**
  * Convenience function for gRPC code execution
  */
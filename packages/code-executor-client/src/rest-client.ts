@@ -1,15 +1,20 @@
-import { ExecuteOptions, ExecutionResult, HealthCheckResult, RestClientConfig } from './types';
+import { Artifact, BenchmarkOptions, BenchmarkResult, ComplexityOptions, ComplexityResult, ExecuteOptions, ExecutionResult, FixtureUpload, HealthCheckResult, LanguageInfo, ReplayOptions, ReplayResult, RestClientConfig } from './types';
+import { fixtureBytes } from './fixtures';
 
 /**
  * REST client for code execution service
  */
 export class RestClient {
   private baseUrl: string;
+  private origin: string;
+  private apiKey?: string;
 
   constructor(config: RestClientConfig) {
     const protocol = config.protocol || 'http';
     const basePath = config.basePath || '';
-    this.baseUrl = `${protocol}://${config.host}:${config.port}${basePath}`;
+    this.origin = `${protocol}://${config.host}:${config.port}`;
+    this.baseUrl = `${this.origin}${basePath}`;
+    this.apiKey = config.apiKey;
   }
 
   /**
@@ -20,6 +25,7 @@ export class RestClient {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
       },
       body: JSON.stringify({
         language: opts.language,
@@ -28,6 +34,47 @@ export class RestClient {
         timeout_seconds: opts.timeoutSeconds || 30,
         memory_limit_mb: opts.memoryLimitMb || 128,
         cpu_limit: opts.cpuLimit || 0.5,
+        cpu_time_limit_ms: opts.cpuTimeLimitMs,
+        usage_interval_ms: opts.usageIntervalMs,
+        combined_output: opts.combinedOutput || false,
+        artifacts: opts.artifacts || [],
+        fixtures: (opts.fixtures || []).map((fixture) => ({
+          name: fixture.name,
+          content_base64: fixture.content !== undefined ? fixtureBytes(fixture.content).toString('base64') : undefined,
+          id: fixture.id,
+        })),
+        args: opts.args || [],
+        env: opts.env || {},
+        manifest: opts.manifest,
+        determinism: opts.determinism && {
+          seed: opts.determinism.seed,
+          fake_time: opts.determinism.fakeTime?.toISOString(),
+          pin_cpu: opts.determinism.pinCpu,
+          cpu_set: opts.determinism.cpuSet,
+        },
+      }),
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    return this.toResult(await response.json());
+  }
+
+  /**
+   * Replay a stored execution and compare the result with the stored one
+   */
+  async replay(executionId: string, opts: ReplayOptions = {}): Promise<ReplayResult> {
+    const response = await fetch(`${this.baseUrl}/executions/${encodeURIComponent(executionId)}/replay`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
+      },
+      body: JSON.stringify({
+        latest_image: opts.latestImage || false,
+        version: opts.version,
       }),
     });
 
@@ -36,18 +83,169 @@ export class RestClient {
     }
 
     const result = await response.json();
-    
+
     return {
-      stdout: result.stdout || '',
-      stderr: result.stderr || '',
-      exitCode: result.exit_code || 0,
-      timeout: result.timeout || false,
-      memoryExceeded: result.memory_exceeded || false,
-      executionTimeMs: result.execution_time_ms || 0,
-      memoryUsedMb: result.memory_used_mb || 0,
+      executionId: result.execution_id,
+      identical: result.identical || false,
+      differences: result.differences || [],
+      result: this.toResult(result.result),
+    };
+  }
+
+  /**
+   * Run a submission on generated inputs of increasing size and fit its CPU time and memory to complexity classes
+   */
+  async analyzeComplexity(opts: ComplexityOptions): Promise<ComplexityResult> {
+    const response = await fetch(`${this.baseUrl}/complexity`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
+      },
+      body: JSON.stringify({
+        language: opts.language,
+        code: opts.code,
+        generator: opts.generator,
+        sizes: opts.sizes,
+        repeats: opts.repeats,
+        timeout_seconds: opts.timeoutSeconds,
+        memory_limit_mb: opts.memoryLimitMb,
+        cpu_limit: opts.cpuLimit,
+      }),
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const result = await response.json();
+
+    return {
+      measurements: (result.measurements || []).map((measurement: any) => ({
+        size: measurement.size,
+        inputBytes: measurement.input_bytes || 0,
+        cpuTimeUs: measurement.cpu_time_us || 0,
+        memoryBytes: measurement.memory_bytes || 0,
+        error: measurement.error || undefined,
+      })),
+      cpu: result.cpu,
+      memory: result.memory,
+    };
+  }
+
+  /**
+   * Run a submission repeatedly in a warm container and summarize its CPU time and memory, optionally against a reference solution
+   */
+  async benchmark(opts: BenchmarkOptions): Promise<BenchmarkResult> {
+    const response = await fetch(`${this.baseUrl}/benchmark`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
+      },
+      body: JSON.stringify({
+        language: opts.language,
+        code: opts.code,
+        input: opts.input,
+        args: opts.args,
+        runs: opts.runs,
+        warmup: opts.warmup,
+        reference_code: opts.referenceCode,
+        timeout_seconds: opts.timeoutSeconds,
+        memory_limit_mb: opts.memoryLimitMb,
+        cpu_limit: opts.cpuLimit,
+        cpu_time_limit_ms: opts.cpuTimeLimitMs,
+      }),
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const result = await response.json();
+    const toMeasurements = (measurements: any) => ({
+      runs: measurements.runs,
+      cpuTimeUs: measurements.cpu_time_us,
+      memoryBytes: measurements.memory_bytes,
+      error: measurements.error || undefined,
+    });
+
+    return {
+      submission: toMeasurements(result.submission),
+      reference: result.reference ? toMeasurements(result.reference) : undefined,
+      score: result.score,
+      noisy: result.noisy,
+    };
+  }
+
+  /**
+   * Download the content of an artifact, fetching it from the server if it was stored for download
+   */
+  async downloadArtifact(artifact: Artifact): Promise<Uint8Array> {
+    if (artifact.content || !artifact.url) {
+      return artifact.content || new Uint8Array();
+    }
+
+    const response = await fetch(artifact.url, {
+      method: 'GET',
+      headers: this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {},
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    return new Uint8Array(await response.arrayBuffer());
+  }
+
+  /**
+   * Upload a fixture executions can reference by ID, requires a trusted caller
+   */
+  async uploadFixture(content: Uint8Array | string): Promise<FixtureUpload> {
+    const response = await fetch(`${this.baseUrl}/fixtures`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/octet-stream',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
+      },
+      body: fixtureBytes(content),
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const result = await response.json();
+
+    return {
+      id: result.id,
+      size: result.size,
+      sha256: result.sha256,
     };
   }
 
+  /**
+   * List the supported languages, their versions and which are installed
+   */
+  async languages(): Promise<LanguageInfo[]> {
+    const response = await fetch(`${this.baseUrl}/languages`, {
+      method: 'GET',
+      headers: this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {},
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const result = await response.json();
+
+    return (result.languages || []).map((language: any) => ({
+      name: language.name,
+      aliases: language.aliases || [],
+      versions: language.versions || [],
+    }));
+  }
+
   /**
    * Health check using REST API
    */
@@ -67,6 +265,91 @@ export class RestClient {
       version: result.version || 'unknown',
     };
   }
+
+  /**
+   * Readiness check, not ready while required execution images are missing
+   */
+  async ready(): Promise<HealthCheckResult> {
+    const response = await fetch(`${this.baseUrl}/ready`, {
+      method: 'GET',
+    });
+
+    // 503 carries the missing images in the body
+    if (!response.ok && response.status !== 503) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const result = await response.json();
+
+    return {
+      status: result.status || 'unknown',
+      version: result.version || 'unknown',
+      missingImages: result.missing_images,
+    };
+  }
+
+  /**
+   * Map an execution response to the result type
+   */
+  private toResult(result: any): ExecutionResult {
+    return {
+      stdout: result.stdout || '',
+      stderr: result.stderr || '',
+      exitCode: result.exit_code || 0,
+      timeout: result.timeout || false,
+      memoryExceeded: result.memory_exceeded || false,
+      executionTimeMs: result.execution_time_ms || 0,
+      cpuTimeMs: result.cpu_time_ms || 0,
+      cpuUserTimeMs: result.cpu_user_time_ms || 0,
+      cpuSystemTimeMs: result.cpu_system_time_ms || 0,
+      cpuTimeExceeded: result.cpu_time_exceeded || false,
+      startupTimeMs: result.startup_time_ms || 0,
+      imagePullTimeMs: result.image_pull_time_ms || 0,
+      memoryUsedMb: result.memory_used_mb || 0,
+      stdoutTruncated: result.stdout_truncated || false,
+      stderrTruncated: result.stderr_truncated || false,
+      stdoutBytes: result.stdout_bytes || 0,
+      stderrBytes: result.stderr_bytes || 0,
+      output: (result.output || []).map((chunk: any) => ({
+        stream: chunk.stream,
+        data: chunk.data,
+        offsetMs: chunk.offset_ms || 0,
+      })),
+      usage: (result.usage || []).map((point: any) => ({
+        offsetMs: point.t_ms || 0,
+        cpuPercent: point.cpu_percent || 0,
+        rssBytes: point.rss_bytes || 0,
+        pids: point.pids || 0,
+        ioReadBytes: point.io_read_bytes || 0,
+        ioWriteBytes: point.io_write_bytes || 0,
+      })),
+      artifacts: (result.artifacts || []).map((artifact: any) => ({
+        path: artifact.path,
+        size: artifact.size || 0,
+        content: artifact.content_base64 ? Buffer.from(artifact.content_base64, 'base64') : undefined,
+        url: artifact.url ? `${this.origin}${artifact.url}` : undefined,
+      })),
+      artifactsTruncated: result.artifacts_truncated || false,
+      fixtures: result.fixtures || [],
+      version: result.version || '',
+      imageDigest: result.image_digest || '',
+      dependenciesCached: result.dependencies_cached || false,
+      installTimeMs: result.install_time_ms || 0,
+      buildCached: result.build_cached || false,
+      compileTimeMs: result.compile_time_ms || 0,
+      determinism: result.determinism
+        ? {
+            seed: result.determinism.seed,
+            fakeTime: result.determinism.fake_time ? new Date(result.determinism.fake_time) : undefined,
+            pinCpu: result.determinism.pin_cpu || false,
+            timezone: result.determinism.timezone,
+            locale: result.determinism.locale,
+            cpuSet: result.determinism.cpu_set,
+          }
+        : undefined,
+      executionId: result.execution_id || undefined,
+    };
+  }
 }
 
 /**
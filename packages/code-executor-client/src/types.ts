@@ -1,10 +1,73 @@
 export interface ExecuteOptions {
-  language: string;
+  language: string; // optionally with a version, e.g. python@3.12
   code: string;
   input?: string;
   timeoutSeconds?: number;
   memoryLimitMb?: number;
   cpuLimit?: number;
+  cpuTimeLimitMs?: number; // CPU time limit of the run, enforced alongside the wall-clock timeout
+  usageIntervalMs?: number; // sample a resource usage series at this interval, at least 50ms
+  combinedOutput?: boolean; // also return stdout and stderr interleaved in arrival order
+  artifacts?: string[]; // glob patterns of files in /tmp to return after the run
+  fixtures?: Fixture[]; // data files placed read-only in /tmp before the run
+  args?: string[]; // program arguments, e.g. sys.argv[1:]
+  env?: Record<string, string>; // environment variables; PATH, LD_* and similar are rejected
+  manifest?: Manifest; // dependency manifest installed before the run
+  determinism?: Determinism; // fix seeds, time zone and locale for reproducible results
+}
+
+export interface Determinism {
+  seed?: number; // seed of the language runtime, 1 if unset
+  fakeTime?: Date; // start of a faked wall clock, if the server enables it
+  pinCpu?: boolean; // run on a single CPU
+  timezone?: string; // reported by the run
+  locale?: string; // reported by the run
+  cpuSet?: string; // CPU the run was pinned to
+}
+
+export interface Manifest {
+  name: 'requirements.txt' | 'package.json' | 'go.mod' | 'Cargo.toml';
+  content: string;
+}
+
+export interface Fixture {
+  name: string; // file name in /tmp
+  content?: Uint8Array | string; // inline content
+  id?: string; // fixture uploaded to the server's fixture store
+}
+
+export interface FixtureUpload {
+  id: string; // reference for Fixture.id
+  size: number;
+  sha256: string;
+}
+
+export interface FixtureDigest {
+  name: string;
+  size: number;
+  sha256: string; // hex-encoded SHA-256 of the content the program ran with
+}
+
+export interface Artifact {
+  path: string; // relative to /tmp
+  size: number;
+  content?: Uint8Array; // inline content, unless the server stores artifacts for download
+  url?: string; // download URL, if the server stores artifacts
+}
+
+export interface UsagePoint {
+  offsetMs: number; // time since the program started
+  cpuPercent: number; // CPU use since the previous point, 100 is one full core
+  rssBytes: number;
+  pids: number;
+  ioReadBytes: number; // bytes read from block devices since the program started
+  ioWriteBytes: number;
+}
+
+export interface OutputChunk {
+  stream: 'stdout' | 'stderr';
+  data: string;
+  offsetMs: number; // time since the program started
 }
 
 export interface ExecutionResult {
@@ -13,18 +76,148 @@ export interface ExecutionResult {
   exitCode: number;
   timeout: boolean;
   memoryExceeded: boolean;
-  executionTimeMs: number;
+  executionTimeMs: number; // wall time of the run
+  cpuTimeMs: number; // CPU time of the run, user plus system
+  cpuUserTimeMs: number;
+  cpuSystemTimeMs: number;
+  cpuTimeExceeded: boolean; // the run used more CPU time than cpuTimeLimitMs
+  startupTimeMs: number; // time from creating the container to starting the program
+  imagePullTimeMs: number; // time spent pulling a missing image
   memoryUsedMb: number;
+  stdoutTruncated: boolean; // stdout exceeded its limit and was cut off
+  stderrTruncated: boolean; // stderr exceeded its limit and was cut off
+  stdoutBytes: number; // bytes written to stdout, including those cut off
+  stderrBytes: number; // bytes written to stderr, including those cut off
+  output: OutputChunk[]; // interleaved output, empty unless combinedOutput was requested
+  usage: UsagePoint[]; // resource usage over the run, empty unless usageIntervalMs was set
+  artifacts: Artifact[]; // files matching the requested patterns
+  artifactsTruncated: boolean; // matching files were skipped because of the artifact limits
+  fixtures: FixtureDigest[]; // integrity of the fixtures the program ran with
+  version: string; // language version the code ran with
+  imageDigest: string; // content digest of the image the code ran in
+  dependenciesCached: boolean; // dependencies of the manifest were installed by an earlier run
+  installTimeMs: number; // time spent installing dependencies, not part of the timeout
+  buildCached: boolean; // the compiled program came from the build cache
+  compileTimeMs: number; // time spent in a separate compile step, zero when cached
+  determinism?: Determinism; // settings of a deterministic run, to repeat it exactly
+  executionId?: string; // stored record the execution can be replayed from, if the server keeps records
+}
+
+export interface ReplayOptions {
+  latestImage?: boolean; // run on the current image instead of the recorded one
+  version?: string; // run on the current image of another version, e.g. to check an upgrade
+}
+
+export interface Difference {
+  field: string; // result field, e.g. stdout or artifacts/<path>
+  stored: string;
+  replayed: string;
+}
+
+export interface ReplayResult {
+  executionId: string;
+  identical: boolean; // the replay produced the stored result
+  differences: Difference[];
+  result: ExecutionResult;
+}
+
+export interface ComplexityProgram {
+  language: string; // optionally with a version, e.g. python@3.12
+  code: string;
+}
+
+export interface ComplexityOptions {
+  language: string;
+  code: string; // submission, reading the generated input from stdin
+  generator: ComplexityProgram; // prints the input of the size given as its first argument
+  sizes: number[]; // 3 to 20 input sizes in increasing order
+  repeats?: number; // runs per size, the fastest counts
+  timeoutSeconds?: number;
+  memoryLimitMb?: number;
+  cpuLimit?: number;
+}
+
+export interface ComplexityMeasurement {
+  size: number;
+  inputBytes: number;
+  cpuTimeUs: number; // CPU time of the fastest run
+  memoryBytes: number; // peak memory of the fastest run
+  error?: string; // why the submission failed at this size, which ends the analysis
+}
+
+export interface ComplexityFit {
+  class: string; // O(1), O(log n), O(n), O(n log n), O(n^2) or O(2^n)
+  confidence: number; // probability of the class among the candidates
+  candidates: { class: string; probability: number }[]; // most likely first
+}
+
+export interface ComplexityResult {
+  measurements: ComplexityMeasurement[];
+  cpu?: ComplexityFit; // missing if the submission failed before three sizes
+  memory?: ComplexityFit;
+}
+
+export interface BenchmarkOptions {
+  language: string;
+  code: string;
+  input?: string;
+  args?: string[];
+  runs?: number; // measured runs, 3 to 50 (default: 10)
+  warmup?: number; // runs before the measured ones (default: 1)
+  referenceCode?: string; // reference solution in the same language to compare against
+  timeoutSeconds?: number; // timeout of every run
+  memoryLimitMb?: number;
+  cpuLimit?: number;
+  cpuTimeLimitMs?: number;
+}
+
+export interface BenchmarkSummary {
+  min: number;
+  median: number;
+  p90: number;
+  stddev: number;
+  noisy: boolean; // the standard deviation exceeds 10% of the mean
+}
+
+export interface BenchmarkMeasurements {
+  runs: number; // measured runs completed
+  cpuTimeUs: BenchmarkSummary;
+  memoryBytes: BenchmarkSummary; // peak resident memory
+  error?: string; // why the program failed, which ends its benchmark
+}
+
+export interface BenchmarkResult {
+  submission: BenchmarkMeasurements;
+  reference?: BenchmarkMeasurements; // missing without a reference solution
+  score?: number; // median CPU time of the reference relative to the submission's, above 1 if the submission is faster
+  noisy: boolean; // the CPU time of the submission or the reference is noisy
+}
+
+export interface LanguageVersion {
+  version: string;
+  image: string;
+  digest?: string; // content digest of the installed image
+  installed: boolean;
+  default: boolean;
+}
+
+export interface LanguageInfo {
+  name: string;
+  aliases: string[];
+  versions: LanguageVersion[];
 }
 
 export interface HealthCheckResult {
   status: string;
   version: string;
+  /** Required execution images that are not installed yet */
+  missingImages?: string[];
 }
 
 export interface ClientConfig {
   host: string;
   port: number;
+  apiKey?: string; // API key sent as bearer token
   // Additional config options can be added here
 }
 
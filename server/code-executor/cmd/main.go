@@ -9,34 +9,304 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/blob"
+	"code-executor/internal/buildcache"
 	"code-executor/internal/docker"
 	grpcserver "code-executor/internal/grpc"
+	"code-executor/internal/ratelimit"
+	"code-executor/internal/records"
 	"code-executor/internal/rest"
+	"code-executor/internal/review"
+	"code-executor/internal/usage"
 	"google.golang.org/grpc"
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerify(os.Args[2:]))
+	}
+
 	var (
 		grpcPort = flag.String("grpc-port", "50051", "gRPC server port")
 		httpPort = flag.String("http-port", "8080", "HTTP server port")
 		mode     = flag.String("mode", "both", "Server mode: grpc, http, or both")
+
+		reviewCacheSize = flag.Int("review-cache-size", 1000, "Maximum number of cached reviews")
+		reviewCacheTTL  = flag.Duration("review-cache-ttl", 24*time.Hour, "How long a cached review stays valid")
+		budgetFile      = flag.String("budget-file", "", "JSON file with model pricing and token budgets")
+		reviewerConfig  = flag.String("reviewer-config", "", "JSON file configuring the model reviews and hints are produced with; without it the offline stub reviewer is used")
+		apiKeysFile     = flag.String("api-keys-file", "", "JSON file with hashed API keys")
+		jwtConfigFile   = flag.String("jwt-config", "", "JSON file configuring verification of platform-issued JWTs")
+		corsOrigins     = flag.String("cors-origins", "", "Comma-separated origins allowed to make cross-origin requests, or * for any")
+
+		rateLimitRPS      = flag.Float64("rate-limit-rps", 0, "Sustained requests per second per caller (0 disables)")
+		rateLimitBurst    = flag.Int("rate-limit-burst", 10, "Requests a caller may make at once")
+		executionsPerHour = flag.Int("quota-executions-per-hour", 0, "Executions per caller in a rolling hour (0 disables)")
+		cpuSecondsPerDay  = flag.Float64("quota-cpu-seconds-per-day", 0, "CPU-seconds per caller in a rolling day (0 disables)")
+
+		auditLogFile = flag.String("audit-log", "", "Append-only file recording every execution and review")
+
+		securityProfileFile = flag.String("security-profile", "", "JSON file overriding the container security profile")
+		runtimeConfigFile   = flag.String("runtime-config", "", "JSON file selecting the OCI runtime per language and trust level")
+
+		artifactDir = flag.String("artifact-dir", "", "Directory storing artifacts for download; without it artifacts are returned inline")
+		artifactTTL = flag.Duration("artifact-ttl", time.Hour, "How long a stored artifact can be downloaded")
+		fixtureDir  = flag.String("fixture-dir", "", "Directory storing uploaded fixtures executions can reference by ID")
+		recordDir   = flag.String("record-dir", "", "Directory storing execution records executions can be replayed from")
+		recordTTL   = flag.Duration("record-ttl", 0, "How long an execution record is kept (0 keeps records)")
+
+		imagePinsFile        = flag.String("image-pins", "", "JSON file pinning execution images to digests")
+		imageRefreshInterval = flag.Duration("image-refresh-interval", 24*time.Hour, "How often unpinned execution images are pulled again (0 disables)")
+		prewarmImages        = flag.Bool("prewarm-images", true, "Pull missing execution images at startup")
+		imageDefinitionsDir  = flag.String("image-definitions", "", "Directory of JSON image definitions adding language versions with extra packages")
+		packageMirrorDir     = flag.String("package-mirror", "", "Directory image builds install packages from")
+		packageCache         = flag.String("package-cache", "", "Docker volume or absolute directory dependency manifests are installed from")
+
+		buildCacheDir    = flag.String("build-cache-dir", "", "Directory caching compiled programs, so unchanged code is not compiled again")
+		buildCacheSizeMB = flag.Int64("build-cache-size-mb", 1024, "Largest total size of cached compiled programs")
+
+		fakeTimeLibrary   = flag.String("faketime-library", "", "Host path of libfaketime, enables faked clocks in deterministic runs")
+		deterministicCPUs = flag.String("deterministic-cpus", "0", "Comma separated CPUs deterministic runs are pinned to")
 	)
 	flag.Parse()
 
+	// Load the container security profile, fields missing from the file keep their defaults
+	securityProfile := docker.DefaultSecurityProfile()
+	if *securityProfileFile != "" {
+		profile, err := docker.LoadSecurityProfile(*securityProfileFile)
+		if err != nil {
+			log.Fatalf("Failed to load security profile: %v", err)
+		}
+		securityProfile = *profile
+	}
+
 	// Initialize Docker manager
-	dockerManager, err := docker.NewManager()
+	dockerManager, err := docker.NewManager(securityProfile)
 	if err != nil {
 		log.Fatalf("Failed to create Docker manager: %v", err)
 	}
 	defer dockerManager.Close()
 
+	// Select OCI runtimes, checking they are registered with the daemon
+	if *runtimeConfigFile != "" {
+		runtimeConfig, err := docker.LoadRuntimeConfig(*runtimeConfigFile)
+		if err != nil {
+			log.Fatalf("Failed to load runtime config: %v", err)
+		}
+		infoCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = dockerManager.ConfigureRuntimes(infoCtx, *runtimeConfig)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to configure runtimes: %v", err)
+		}
+	}
+
+	// Register custom images as language versions, built from the local package mirror
+	if *imageDefinitionsDir != "" {
+		definitions, err := docker.LoadImageDefinitions(*imageDefinitionsDir)
+		if err != nil {
+			log.Fatalf("Failed to load image definitions: %v", err)
+		}
+		if err := docker.RegisterImageDefinitions(definitions); err != nil {
+			log.Fatalf("Failed to register image definitions: %v", err)
+		}
+	}
+	if *packageMirrorDir != "" {
+		if err := dockerManager.UsePackageMirror(*packageMirrorDir); err != nil {
+			log.Fatalf("Failed to configure package mirror: %v", err)
+		}
+	}
+
+	// Install dependency manifests offline from the package cache
+	if *packageCache != "" {
+		if err := dockerManager.UsePackageCache(*packageCache); err != nil {
+			log.Fatalf("Failed to configure package cache: %v", err)
+		}
+	}
+
+	// Cache compiled programs, compiled languages then compile in a separate step
+	var buildCache *buildcache.Cache
+	if *buildCacheDir != "" {
+		buildCache, err = buildcache.Open(*buildCacheDir, *buildCacheSizeMB*1024*1024)
+		if err != nil {
+			log.Fatalf("Failed to open build cache: %v", err)
+		}
+		dockerManager.UseBuildCache(buildCache)
+	}
+
+	// Deterministic runs pin to dedicated CPUs and may fake the clock
+	if err := dockerManager.UseDeterministicCPUs(*deterministicCPUs); err != nil {
+		log.Fatalf("Failed to configure deterministic CPUs: %v", err)
+	}
+	if *fakeTimeLibrary != "" {
+		if err := dockerManager.UseFakeTime(*fakeTimeLibrary); err != nil {
+			log.Fatalf("Failed to configure fake time: %v", err)
+		}
+	}
+
+	// Pin execution images to digests, unpinned images follow their tags
+	if *imagePinsFile != "" {
+		pins, err := docker.LoadImagePins(*imagePinsFile)
+		if err != nil {
+			log.Fatalf("Failed to load image pins: %v", err)
+		}
+		dockerManager.PinImages(pins)
+	}
+
+	// Load token budgets, without a file usage is tracked but not limited
+	budgetConfig := usage.Config{}
+	if *budgetFile != "" {
+		config, err := usage.LoadConfig(*budgetFile)
+		if err != nil {
+			log.Fatalf("Failed to load budget config: %v", err)
+		}
+		budgetConfig = *config
+	}
+	usageTracker := usage.NewTracker(budgetConfig)
+
+	// Initialize reviewer backend and hint ledger shared by both servers.
+	// Once a budget is exhausted requests degrade to the offline stub reviewer.
+	var primary review.Reviewer = review.NewStubReviewer()
+	if *reviewerConfig != "" {
+		config, err := review.LoadModelConfig(*reviewerConfig)
+		if err != nil {
+			log.Fatalf("Failed to load reviewer config: %v", err)
+		}
+		model, err := review.NewModelReviewer(*config)
+		if err != nil {
+			log.Fatalf("Failed to create model reviewer: %v", err)
+		}
+		primary = model
+		log.Printf("Reviews and hints use %s", model.Version())
+	}
+	reviewer := usage.NewBudgetedReviewer(primary, review.NewStubReviewer(), usageTracker)
+	reviewCache := review.NewCache(*reviewCacheSize, *reviewCacheTTL)
+	hints := review.NewHintService(reviewer, review.NewLedger())
+
+	// Load API keys and JWT verification, authentication is disabled without either
+	var authenticators auth.Chain
+	if *apiKeysFile != "" {
+		keyStore, err := auth.LoadKeyStore(*apiKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
+		}
+		authenticators = append(authenticators, keyStore)
+	}
+	if *jwtConfigFile != "" {
+		jwtConfig, err := auth.LoadJWTConfig(*jwtConfigFile)
+		if err != nil {
+			log.Fatalf("Failed to load JWT config: %v", err)
+		}
+		verifier, err := auth.NewJWTVerifier(*jwtConfig)
+		if err != nil {
+			log.Fatalf("Failed to create JWT verifier: %v", err)
+		}
+		authenticators = append(authenticators, verifier)
+	}
+	var authenticator auth.Authenticator
+	if len(authenticators) > 0 {
+		authenticator = authenticators
+	} else {
+		log.Println("WARNING: no API keys or JWT config given, authentication is disabled")
+	}
+
+	// Rate limits and quotas, kept in memory on this replica
+	var limiter *ratelimit.Limiter
+	if *rateLimitRPS > 0 || *executionsPerHour > 0 || *cpuSecondsPerDay > 0 {
+		limiter = ratelimit.NewLimiter(ratelimit.Config{
+			RequestsPerSecond: *rateLimitRPS,
+			Burst:             *rateLimitBurst,
+			ExecutionsPerHour: *executionsPerHour,
+			CPUSecondsPerDay:  *cpuSecondsPerDay,
+		}, ratelimit.NewMemoryStore())
+	}
+
+	// Open the audit log shared by both servers
+	var auditLog *audit.Log
+	if *auditLogFile != "" {
+		auditLog, err = audit.Open(*auditLogFile)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditLog.Close()
+	}
+
+	// Store artifacts for download instead of returning them inline
+	var artifactStore *blob.Store
+	if *artifactDir != "" {
+		artifactStore, err = blob.NewStore(*artifactDir, *artifactTTL)
+		if err != nil {
+			log.Fatalf("Failed to open artifact store: %v", err)
+		}
+		if *artifactTTL > 0 {
+			go sweepArtifacts(artifactStore, *artifactTTL)
+		}
+	}
+
+	// Store uploaded fixtures, kept until removed from the directory
+	var fixtureStore *blob.Store
+	if *fixtureDir != "" {
+		fixtureStore, err = blob.NewStore(*fixtureDir, 0)
+		if err != nil {
+			log.Fatalf("Failed to open fixture store: %v", err)
+		}
+	}
+
+	// Store execution records, so executions can be replayed
+	var recordStore *records.Store
+	if *recordDir != "" {
+		recordStore, err = records.NewStore(*recordDir, *recordTTL)
+		if err != nil {
+			log.Fatalf("Failed to open execution record store: %v", err)
+		}
+		if *recordTTL > 0 {
+			go sweepRecords(recordStore, *recordTTL)
+		}
+	}
+
+	restOptions := rest.Options{
+		Reviewer:      reviewer,
+		ReviewCache:   reviewCache,
+		Hints:         hints,
+		UsageTracker:  usageTracker,
+		Authenticator: authenticator,
+		Limiter:       limiter,
+		AuditLog:      auditLog,
+		ArtifactStore: artifactStore,
+		FixtureStore:  fixtureStore,
+		BuildCache:    buildCache,
+		Records:       recordStore,
+	}
+	if *corsOrigins != "" {
+		restOptions.CORSOrigins = strings.Split(*corsOrigins, ",")
+	}
+
+	grpcOptions := grpcserver.Options{
+		Limiter:      limiter,
+		AuditLog:     auditLog,
+		FixtureStore: fixtureStore,
+		Records:      recordStore,
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Pull and build execution images in the background, the service reports not ready until the required ones are present
+	if *prewarmImages {
+		go dockerManager.PrewarmImages(ctx)
+	}
+	if *imageRefreshInterval > 0 {
+		go dockerManager.RunImageRefresh(ctx, *imageRefreshInterval)
+	}
+
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -44,12 +314,12 @@ func main() {
 	// Start servers based on mode
 	switch *mode {
 	case "grpc":
-		startGRPCServer(ctx, *grpcPort, dockerManager)
+		startGRPCServer(ctx, *grpcPort, dockerManager, hints, authenticator, grpcOptions)
 	case "http":
-		startHTTPServer(ctx, *httpPort, dockerManager)
+		startHTTPServer(ctx, *httpPort, dockerManager, restOptions)
 	case "both":
-		go startGRPCServer(ctx, *grpcPort, dockerManager)
-		go startHTTPServer(ctx, *httpPort, dockerManager)
+		go startGRPCServer(ctx, *grpcPort, dockerManager, hints, authenticator, grpcOptions)
+		go startHTTPServer(ctx, *httpPort, dockerManager, restOptions)
 	default:
 		log.Fatalf("Invalid mode: %s. Use 'grpc', 'http', or 'both'", *mode)
 	}
@@ -64,7 +334,25 @@ func main() {
 	log.Println("Servers shut down complete")
 }
 
-func startGRPCServer(ctx context.Context, port string, dockerManager *docker.Manager) {
+// sweepArtifacts periodically removes expired artifacts
+func sweepArtifacts(store *blob.Store, ttl time.Duration) {
+	for now := range time.Tick(ttl) {
+		if _, err := store.Sweep(now); err != nil {
+			log.Printf("Failed to remove expired artifacts: %v", err)
+		}
+	}
+}
+
+// sweepRecords periodically removes expired execution records
+func sweepRecords(store *records.Store, ttl time.Duration) {
+	for now := range time.Tick(ttl) {
+		if _, err := store.Sweep(now); err != nil {
+			log.Printf("Failed to remove expired execution records: %v", err)
+		}
+	}
+}
+
+func startGRPCServer(ctx context.Context, port string, dockerManager *docker.Manager, hints *review.HintService, authenticator auth.Authenticator, opts grpcserver.Options) {
 	log.Printf("Starting gRPC server on port %s...", port)
 
 	listener, err := net.Listen("tcp", ":"+port)
@@ -72,8 +360,23 @@ func startGRPCServer(ctx context.Context, port string, dockerManager *docker.Man
 		log.Fatalf("Failed to listen on port %s: %v", port, err)
 	}
 
-	s := grpc.NewServer()
-	grpcserver.RegisterServer(s, dockerManager)
+	// Authenticate before rate limiting, so limits apply per caller rather than per IP
+	var serverOpts []grpc.ServerOption
+	if authenticator != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(grpcserver.UnaryAuthInterceptor(authenticator)),
+			grpc.ChainStreamInterceptor(grpcserver.StreamAuthInterceptor(authenticator)),
+		)
+	}
+	if opts.Limiter != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(grpcserver.UnaryRateLimitInterceptor(opts.Limiter)),
+			grpc.ChainStreamInterceptor(grpcserver.StreamRateLimitInterceptor(opts.Limiter)),
+		)
+	}
+
+	s := grpc.NewServer(serverOpts...)
+	grpcserver.RegisterServer(s, dockerManager, hints, opts)
 
 	go func() {
 		<-ctx.Done()
@@ -86,10 +389,10 @@ func startGRPCServer(ctx context.Context, port string, dockerManager *docker.Man
 	}
 }
 
-func startHTTPServer(ctx context.Context, port string, dockerManager *docker.Manager) {
+func startHTTPServer(ctx context.Context, port string, dockerManager *docker.Manager, opts rest.Options) {
 	log.Printf("Starting HTTP server on port %s...", port)
 
-	restServer := rest.NewServer(dockerManager)
+	restServer := rest.NewServer(dockerManager, opts)
 	
 	httpServer := &http.Server{
 		Addr:    ":" + port,
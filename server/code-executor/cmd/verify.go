@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"code-executor/internal/audit"
+)
+
+// runVerify implements the verify subcommand, which checks the hash chain
+// of an audit log or export and returns the process exit code
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	partial := flags.Bool("partial", false, "Accept a log that does not start at the first entry, such as an export")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s verify [-partial] <audit-log|->\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open audit log: %v\n", err)
+			return 2
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := audit.Verify(input, *partial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAILED after %d valid entries: %v\n", result.Entries, err)
+		return 1
+	}
+	if result.Entries == 0 {
+		fmt.Println("OK: no entries")
+		return 0
+	}
+
+	fmt.Printf("OK: %d entries, seq %d to %d\n", result.Entries, result.FirstSeq, result.LastSeq)
+	fmt.Printf("Head hash: %s\n", result.HeadHash)
+	return 0
+}
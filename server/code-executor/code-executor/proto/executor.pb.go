@@ -23,15 +23,24 @@ const (
 
 // Code execution request
 type ExecuteRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Language       string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`                                    // Programming language (python, javascript, go, etc.)
-	Code           string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`                                            // Code to execute
-	Input          string                 `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`                                          // Optional stdin input
-	TimeoutSeconds int32                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // Execution timeout (default: 30s)
-	MemoryLimitMb  int64                  `protobuf:"varint,5,opt,name=memory_limit_mb,json=memoryLimitMb,proto3" json:"memory_limit_mb,omitempty"`  // Memory limit in MB (default: 128MB)
-	CpuLimit       float64                `protobuf:"fixed64,6,opt,name=cpu_limit,json=cpuLimit,proto3" json:"cpu_limit,omitempty"`                  // CPU limit as fraction (default: 0.5)
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Language        string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`                                                                  // Programming language (python, javascript, go, etc.), optionally with a version like python@3.12
+	Code            string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`                                                                          // Code to execute
+	Input           string                 `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`                                                                        // Optional stdin input
+	TimeoutSeconds  int32                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`                               // Execution timeout (default: 30s)
+	MemoryLimitMb   int64                  `protobuf:"varint,5,opt,name=memory_limit_mb,json=memoryLimitMb,proto3" json:"memory_limit_mb,omitempty"`                                // Memory limit in MB (default: 128MB)
+	CpuLimit        float64                `protobuf:"fixed64,6,opt,name=cpu_limit,json=cpuLimit,proto3" json:"cpu_limit,omitempty"`                                                // CPU limit as fraction (default: 0.5)
+	CombinedOutput  bool                   `protobuf:"varint,7,opt,name=combined_output,json=combinedOutput,proto3" json:"combined_output,omitempty"`                               // Also return stdout and stderr interleaved in arrival order
+	Artifacts       []string               `protobuf:"bytes,8,rep,name=artifacts,proto3" json:"artifacts,omitempty"`                                                                // Glob patterns of files in /tmp to return after the run
+	Fixtures        []*Fixture             `protobuf:"bytes,9,rep,name=fixtures,proto3" json:"fixtures,omitempty"`                                                                  // Data files placed read-only in /tmp before the run
+	Args            []string               `protobuf:"bytes,10,rep,name=args,proto3" json:"args,omitempty"`                                                                         // Program arguments, e.g. sys.argv[1:]
+	Env             map[string]string      `protobuf:"bytes,11,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Environment variables of the program
+	Manifest        *Manifest              `protobuf:"bytes,12,opt,name=manifest,proto3" json:"manifest,omitempty"`                                                                 // Dependency manifest installed before the run
+	Determinism     *Determinism           `protobuf:"bytes,13,opt,name=determinism,proto3" json:"determinism,omitempty"`                                                           // Fix seeds, time zone and locale for reproducible results
+	CpuTimeLimitMs  int64                  `protobuf:"varint,14,opt,name=cpu_time_limit_ms,json=cpuTimeLimitMs,proto3" json:"cpu_time_limit_ms,omitempty"`                          // CPU time limit of the run (default: none)
+	UsageIntervalMs int64                  `protobuf:"varint,15,opt,name=usage_interval_ms,json=usageIntervalMs,proto3" json:"usage_interval_ms,omitempty"`                         // Sample a resource usage series at this interval (default: none, at least 50)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ExecuteRequest) Reset() {
@@ -106,34 +115,97 @@ func (x *ExecuteRequest) GetCpuLimit() float64 {
 	return 0
 }
 
-// Code execution response
-type ExecuteResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Stdout          string                 `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`                                             // Standard output
-	Stderr          string                 `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`                                             // Standard error
-	ExitCode        int32                  `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`                        // Exit code
-	Timeout         bool                   `protobuf:"varint,4,opt,name=timeout,proto3" json:"timeout,omitempty"`                                          // Whether execution timed out
-	MemoryExceeded  bool                   `protobuf:"varint,5,opt,name=memory_exceeded,json=memoryExceeded,proto3" json:"memory_exceeded,omitempty"`      // Whether memory limit was exceeded
-	ExecutionTimeMs int64                  `protobuf:"varint,6,opt,name=execution_time_ms,json=executionTimeMs,proto3" json:"execution_time_ms,omitempty"` // Execution time in milliseconds
-	MemoryUsedMb    int64                  `protobuf:"varint,7,opt,name=memory_used_mb,json=memoryUsedMb,proto3" json:"memory_used_mb,omitempty"`          // Memory used in MB
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *ExecuteRequest) GetCombinedOutput() bool {
+	if x != nil {
+		return x.CombinedOutput
+	}
+	return false
 }
 
-func (x *ExecuteResponse) Reset() {
-	*x = ExecuteResponse{}
+func (x *ExecuteRequest) GetArtifacts() []string {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetFixtures() []*Fixture {
+	if x != nil {
+		return x.Fixtures
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetManifest() *Manifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetDeterminism() *Determinism {
+	if x != nil {
+		return x.Determinism
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetCpuTimeLimitMs() int64 {
+	if x != nil {
+		return x.CpuTimeLimitMs
+	}
+	return 0
+}
+
+func (x *ExecuteRequest) GetUsageIntervalMs() int64 {
+	if x != nil {
+		return x.UsageIntervalMs
+	}
+	return 0
+}
+
+// Settings of a deterministic run. Requests set the seed, fake time and CPU
+// pinning, responses also report what the run fixed.
+type Determinism struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seed          uint32                 `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`                                       // Seed of the language runtime, 1 if zero
+	FakeTimeUnix  int64                  `protobuf:"varint,2,opt,name=fake_time_unix,json=fakeTimeUnix,proto3" json:"fake_time_unix,omitempty"` // Start of a faked wall clock in Unix seconds, the real clock if zero
+	PinCpu        bool                   `protobuf:"varint,3,opt,name=pin_cpu,json=pinCpu,proto3" json:"pin_cpu,omitempty"`                     // Run on a single CPU
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`                                // Time zone of the run
+	Locale        string                 `protobuf:"bytes,5,opt,name=locale,proto3" json:"locale,omitempty"`                                    // Locale of the run
+	CpuSet        string                 `protobuf:"bytes,6,opt,name=cpu_set,json=cpuSet,proto3" json:"cpu_set,omitempty"`                      // CPU the run was pinned to
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Determinism) Reset() {
+	*x = Determinism{}
 	mi := &file_executor_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecuteResponse) String() string {
+func (x *Determinism) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteResponse) ProtoMessage() {}
+func (*Determinism) ProtoMessage() {}
 
-func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
+func (x *Determinism) ProtoReflect() protoreflect.Message {
 	mi := &file_executor_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -145,81 +217,76 @@ func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
-func (*ExecuteResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Determinism.ProtoReflect.Descriptor instead.
+func (*Determinism) Descriptor() ([]byte, []int) {
 	return file_executor_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *ExecuteResponse) GetStdout() string {
-	if x != nil {
-		return x.Stdout
-	}
-	return ""
-}
-
-func (x *ExecuteResponse) GetStderr() string {
+func (x *Determinism) GetSeed() uint32 {
 	if x != nil {
-		return x.Stderr
+		return x.Seed
 	}
-	return ""
+	return 0
 }
 
-func (x *ExecuteResponse) GetExitCode() int32 {
+func (x *Determinism) GetFakeTimeUnix() int64 {
 	if x != nil {
-		return x.ExitCode
+		return x.FakeTimeUnix
 	}
 	return 0
 }
 
-func (x *ExecuteResponse) GetTimeout() bool {
+func (x *Determinism) GetPinCpu() bool {
 	if x != nil {
-		return x.Timeout
+		return x.PinCpu
 	}
 	return false
 }
 
-func (x *ExecuteResponse) GetMemoryExceeded() bool {
+func (x *Determinism) GetTimezone() string {
 	if x != nil {
-		return x.MemoryExceeded
+		return x.Timezone
 	}
-	return false
+	return ""
 }
 
-func (x *ExecuteResponse) GetExecutionTimeMs() int64 {
+func (x *Determinism) GetLocale() string {
 	if x != nil {
-		return x.ExecutionTimeMs
+		return x.Locale
 	}
-	return 0
+	return ""
 }
 
-func (x *ExecuteResponse) GetMemoryUsedMb() int64 {
+func (x *Determinism) GetCpuSet() string {
 	if x != nil {
-		return x.MemoryUsedMb
+		return x.CpuSet
 	}
-	return 0
+	return ""
 }
 
-// Health check request
-type HealthRequest struct {
+// Dependency manifest, e.g. requirements.txt, installed from the package cache
+type Manifest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`       // File name, selects the package manager
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // Manifest content
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
+func (x *Manifest) Reset() {
+	*x = Manifest{}
 	mi := &file_executor_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *Manifest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*Manifest) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+func (x *Manifest) ProtoReflect() protoreflect.Message {
 	mi := &file_executor_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -231,34 +298,49 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Manifest.ProtoReflect.Descriptor instead.
+func (*Manifest) Descriptor() ([]byte, []int) {
 	return file_executor_proto_rawDescGZIP(), []int{2}
 }
 
-// Health check response
-type HealthResponse struct {
+func (x *Manifest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Manifest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// Data file attached to an execution, either inline or by ID
+type Fixture struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`   // Status of the service
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"` // Service version
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`       // File name in /tmp
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // Inline content
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`           // Fixture uploaded to the fixture store
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
+func (x *Fixture) Reset() {
+	*x = Fixture{}
 	mi := &file_executor_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *Fixture) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*Fixture) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+func (x *Fixture) ProtoReflect() protoreflect.Message {
 	mi := &file_executor_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -270,52 +352,2233 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Fixture.ProtoReflect.Descriptor instead.
+func (*Fixture) Descriptor() ([]byte, []int) {
 	return file_executor_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *HealthResponse) GetStatus() string {
+func (x *Fixture) GetName() string {
 	if x != nil {
-		return x.Status
+		return x.Name
 	}
 	return ""
 }
 
-func (x *HealthResponse) GetVersion() string {
+func (x *Fixture) GetContent() []byte {
 	if x != nil {
-		return x.Version
+		return x.Content
+	}
+	return nil
+}
+
+func (x *Fixture) GetId() string {
+	if x != nil {
+		return x.Id
 	}
 	return ""
 }
 
-var File_executor_proto protoreflect.FileDescriptor
+// Integrity of a fixture the program ran with
+type FixtureDigest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`     // File name in /tmp
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`    // Size in bytes
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"` // Hex-encoded SHA-256 of the content
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_executor_proto_rawDesc = "" +
-	"\n" +
-	"\x0eexecutor.proto\x12\bexecutor\"\xc4\x01\n" +
-	"\x0eExecuteRequest\x12\x1a\n" +
-	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
-	"\x04code\x18\x02 \x01(\tR\x04code\x12\x14\n" +
-	"\x05input\x18\x03 \x01(\tR\x05input\x12'\n" +
-	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\x12&\n" +
-	"\x0fmemory_limit_mb\x18\x05 \x01(\x03R\rmemoryLimitMb\x12\x1b\n" +
-	"\tcpu_limit\x18\x06 \x01(\x01R\bcpuLimit\"\xf3\x01\n" +
-	"\x0fExecuteResponse\x12\x16\n" +
-	"\x06stdout\x18\x01 \x01(\tR\x06stdout\x12\x16\n" +
-	"\x06stderr\x18\x02 \x01(\tR\x06stderr\x12\x1b\n" +
-	"\texit_code\x18\x03 \x01(\x05R\bexitCode\x12\x18\n" +
-	"\atimeout\x18\x04 \x01(\bR\atimeout\x12'\n" +
-	"\x0fmemory_exceeded\x18\x05 \x01(\bR\x0ememoryExceeded\x12*\n" +
-	"\x11execution_time_ms\x18\x06 \x01(\x03R\x0fexecutionTimeMs\x12$\n" +
-	"\x0ememory_used_mb\x18\a \x01(\x03R\fmemoryUsedMb\"\x0f\n" +
-	"\rHealthRequest\"B\n" +
-	"\x0eHealthResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion2\x8b\x01\n" +
+func (x *FixtureDigest) Reset() {
+	*x = FixtureDigest{}
+	mi := &file_executor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixtureDigest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixtureDigest) ProtoMessage() {}
+
+func (x *FixtureDigest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixtureDigest.ProtoReflect.Descriptor instead.
+func (*FixtureDigest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FixtureDigest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FixtureDigest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FixtureDigest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// Piece of output of one stream
+type OutputChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        string                 `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`                      // "stdout" or "stderr"
+	Data          string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`                          // Output text
+	OffsetMs      int64                  `protobuf:"varint,3,opt,name=offset_ms,json=offsetMs,proto3" json:"offset_ms,omitempty"` // Milliseconds since the program started
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutputChunk) Reset() {
+	*x = OutputChunk{}
+	mi := &file_executor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputChunk) ProtoMessage() {}
+
+func (x *OutputChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputChunk.ProtoReflect.Descriptor instead.
+func (*OutputChunk) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OutputChunk) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *OutputChunk) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *OutputChunk) GetOffsetMs() int64 {
+	if x != nil {
+		return x.OffsetMs
+	}
+	return 0
+}
+
+// Sample of the resource usage of a run
+type UsagePoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OffsetMs      int64                  `protobuf:"varint,1,opt,name=offset_ms,json=offsetMs,proto3" json:"offset_ms,omitempty"`               // Milliseconds since the program started
+	CpuPercent    float64                `protobuf:"fixed64,2,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`        // CPU use since the previous point, 100 is one full core
+	RssBytes      int64                  `protobuf:"varint,3,opt,name=rss_bytes,json=rssBytes,proto3" json:"rss_bytes,omitempty"`               // Resident memory
+	Pids          int64                  `protobuf:"varint,4,opt,name=pids,proto3" json:"pids,omitempty"`                                       // Processes and threads of the program
+	IoReadBytes   int64                  `protobuf:"varint,5,opt,name=io_read_bytes,json=ioReadBytes,proto3" json:"io_read_bytes,omitempty"`    // Bytes read from block devices since the program started
+	IoWriteBytes  int64                  `protobuf:"varint,6,opt,name=io_write_bytes,json=ioWriteBytes,proto3" json:"io_write_bytes,omitempty"` // Bytes written to block devices since the program started
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsagePoint) Reset() {
+	*x = UsagePoint{}
+	mi := &file_executor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsagePoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsagePoint) ProtoMessage() {}
+
+func (x *UsagePoint) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsagePoint.ProtoReflect.Descriptor instead.
+func (*UsagePoint) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UsagePoint) GetOffsetMs() int64 {
+	if x != nil {
+		return x.OffsetMs
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetRssBytes() int64 {
+	if x != nil {
+		return x.RssBytes
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetPids() int64 {
+	if x != nil {
+		return x.Pids
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetIoReadBytes() int64 {
+	if x != nil {
+		return x.IoReadBytes
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetIoWriteBytes() int64 {
+	if x != nil {
+		return x.IoWriteBytes
+	}
+	return 0
+}
+
+// File produced by the program
+type Artifact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`       // Path relative to /tmp
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`      // Size in bytes
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"` // File content
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_executor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Artifact) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Artifact) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Artifact) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// Code execution response
+type ExecuteResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Stdout             string                 `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`                                                     // Standard output
+	Stderr             string                 `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`                                                     // Standard error
+	ExitCode           int32                  `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`                                // Exit code
+	Timeout            bool                   `protobuf:"varint,4,opt,name=timeout,proto3" json:"timeout,omitempty"`                                                  // Whether execution timed out
+	MemoryExceeded     bool                   `protobuf:"varint,5,opt,name=memory_exceeded,json=memoryExceeded,proto3" json:"memory_exceeded,omitempty"`              // Whether memory limit was exceeded
+	ExecutionTimeMs    int64                  `protobuf:"varint,6,opt,name=execution_time_ms,json=executionTimeMs,proto3" json:"execution_time_ms,omitempty"`         // Wall time of the run in milliseconds
+	MemoryUsedMb       int64                  `protobuf:"varint,7,opt,name=memory_used_mb,json=memoryUsedMb,proto3" json:"memory_used_mb,omitempty"`                  // Memory used in MB
+	StdoutTruncated    bool                   `protobuf:"varint,8,opt,name=stdout_truncated,json=stdoutTruncated,proto3" json:"stdout_truncated,omitempty"`           // Whether stdout exceeded its limit and was cut off
+	StderrTruncated    bool                   `protobuf:"varint,9,opt,name=stderr_truncated,json=stderrTruncated,proto3" json:"stderr_truncated,omitempty"`           // Whether stderr exceeded its limit and was cut off
+	StdoutBytes        int64                  `protobuf:"varint,10,opt,name=stdout_bytes,json=stdoutBytes,proto3" json:"stdout_bytes,omitempty"`                      // Bytes written to stdout, including those cut off
+	StderrBytes        int64                  `protobuf:"varint,11,opt,name=stderr_bytes,json=stderrBytes,proto3" json:"stderr_bytes,omitempty"`                      // Bytes written to stderr, including those cut off
+	Output             []*OutputChunk         `protobuf:"bytes,12,rep,name=output,proto3" json:"output,omitempty"`                                                    // Both streams in arrival order, if combined output was requested
+	Artifacts          []*Artifact            `protobuf:"bytes,13,rep,name=artifacts,proto3" json:"artifacts,omitempty"`                                              // Files in /tmp matching the requested patterns
+	ArtifactsTruncated bool                   `protobuf:"varint,14,opt,name=artifacts_truncated,json=artifactsTruncated,proto3" json:"artifacts_truncated,omitempty"` // Whether matching files were skipped because of the artifact limits
+	Fixtures           []*FixtureDigest       `protobuf:"bytes,15,rep,name=fixtures,proto3" json:"fixtures,omitempty"`                                                // Integrity of the fixtures the program ran with
+	Version            string                 `protobuf:"bytes,16,opt,name=version,proto3" json:"version,omitempty"`                                                  // Language version the code ran with
+	ImageDigest        string                 `protobuf:"bytes,17,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`                       // Content digest of the image the code ran in
+	DependenciesCached bool                   `protobuf:"varint,18,opt,name=dependencies_cached,json=dependenciesCached,proto3" json:"dependencies_cached,omitempty"` // Dependencies were installed by an earlier run
+	InstallTimeMs      int64                  `protobuf:"varint,19,opt,name=install_time_ms,json=installTimeMs,proto3" json:"install_time_ms,omitempty"`              // Time spent installing dependencies, not part of the timeout
+	BuildCached        bool                   `protobuf:"varint,20,opt,name=build_cached,json=buildCached,proto3" json:"build_cached,omitempty"`                      // The compiled program came from the build cache
+	CompileTimeMs      int64                  `protobuf:"varint,21,opt,name=compile_time_ms,json=compileTimeMs,proto3" json:"compile_time_ms,omitempty"`              // Time spent in a separate compile step, zero when cached
+	Determinism        *Determinism           `protobuf:"bytes,22,opt,name=determinism,proto3" json:"determinism,omitempty"`                                          // Settings of a deterministic run
+	ExecutionId        string                 `protobuf:"bytes,23,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`                       // Stored record the execution can be replayed from
+	CpuTimeMs          int64                  `protobuf:"varint,24,opt,name=cpu_time_ms,json=cpuTimeMs,proto3" json:"cpu_time_ms,omitempty"`                          // CPU time of the run in milliseconds
+	CpuUserTimeMs      int64                  `protobuf:"varint,25,opt,name=cpu_user_time_ms,json=cpuUserTimeMs,proto3" json:"cpu_user_time_ms,omitempty"`            // CPU time of the run in user mode
+	CpuSystemTimeMs    int64                  `protobuf:"varint,26,opt,name=cpu_system_time_ms,json=cpuSystemTimeMs,proto3" json:"cpu_system_time_ms,omitempty"`      // CPU time of the run in kernel mode
+	CpuTimeExceeded    bool                   `protobuf:"varint,27,opt,name=cpu_time_exceeded,json=cpuTimeExceeded,proto3" json:"cpu_time_exceeded,omitempty"`        // Whether the run used more CPU time than its limit
+	StartupTimeMs      int64                  `protobuf:"varint,28,opt,name=startup_time_ms,json=startupTimeMs,proto3" json:"startup_time_ms,omitempty"`              // Time from creating the container to starting the program
+	ImagePullTimeMs    int64                  `protobuf:"varint,29,opt,name=image_pull_time_ms,json=imagePullTimeMs,proto3" json:"image_pull_time_ms,omitempty"`      // Time spent pulling a missing image
+	Usage              []*UsagePoint          `protobuf:"bytes,30,rep,name=usage,proto3" json:"usage,omitempty"`                                                      // Resource usage over the run, if a usage interval was requested
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ExecuteResponse) Reset() {
+	*x = ExecuteResponse{}
+	mi := &file_executor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteResponse) ProtoMessage() {}
+
+func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteResponse) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetTimeout() bool {
+	if x != nil {
+		return x.Timeout
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetMemoryExceeded() bool {
+	if x != nil {
+		return x.MemoryExceeded
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetExecutionTimeMs() int64 {
+	if x != nil {
+		return x.ExecutionTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetMemoryUsedMb() int64 {
+	if x != nil {
+		return x.MemoryUsedMb
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetStdoutTruncated() bool {
+	if x != nil {
+		return x.StdoutTruncated
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetStderrTruncated() bool {
+	if x != nil {
+		return x.StderrTruncated
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetStdoutBytes() int64 {
+	if x != nil {
+		return x.StdoutBytes
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetStderrBytes() int64 {
+	if x != nil {
+		return x.StderrBytes
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetOutput() []*OutputChunk {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetArtifactsTruncated() bool {
+	if x != nil {
+		return x.ArtifactsTruncated
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetFixtures() []*FixtureDigest {
+	if x != nil {
+		return x.Fixtures
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetDependenciesCached() bool {
+	if x != nil {
+		return x.DependenciesCached
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetInstallTimeMs() int64 {
+	if x != nil {
+		return x.InstallTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetBuildCached() bool {
+	if x != nil {
+		return x.BuildCached
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetCompileTimeMs() int64 {
+	if x != nil {
+		return x.CompileTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetDeterminism() *Determinism {
+	if x != nil {
+		return x.Determinism
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetCpuTimeMs() int64 {
+	if x != nil {
+		return x.CpuTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetCpuUserTimeMs() int64 {
+	if x != nil {
+		return x.CpuUserTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetCpuSystemTimeMs() int64 {
+	if x != nil {
+		return x.CpuSystemTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetCpuTimeExceeded() bool {
+	if x != nil {
+		return x.CpuTimeExceeded
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetStartupTimeMs() int64 {
+	if x != nil {
+		return x.StartupTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetImagePullTimeMs() int64 {
+	if x != nil {
+		return x.ImagePullTimeMs
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetUsage() []*UsagePoint {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// Languages request
+type ListLanguagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLanguagesRequest) Reset() {
+	*x = ListLanguagesRequest{}
+	mi := &file_executor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLanguagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLanguagesRequest) ProtoMessage() {}
+
+func (x *ListLanguagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLanguagesRequest.ProtoReflect.Descriptor instead.
+func (*ListLanguagesRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{9}
+}
+
+// Version of a language
+type VersionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`      // Version selected with language@version
+	Image         string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`          // Image providing the version
+	Digest        string                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`        // Content digest of the installed image
+	Installed     bool                   `protobuf:"varint,4,opt,name=installed,proto3" json:"installed,omitempty"` // Whether the image is available locally
+	Default       bool                   `protobuf:"varint,5,opt,name=default,proto3" json:"default,omitempty"`     // Whether the version is used without one in the request
+	Pinned        string                 `protobuf:"bytes,6,opt,name=pinned,proto3" json:"pinned,omitempty"`        // Digest the image is pinned to, empty if it follows the tag
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionInfo) Reset() {
+	*x = VersionInfo{}
+	mi := &file_executor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionInfo) ProtoMessage() {}
+
+func (x *VersionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionInfo.ProtoReflect.Descriptor instead.
+func (*VersionInfo) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *VersionInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *VersionInfo) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *VersionInfo) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *VersionInfo) GetInstalled() bool {
+	if x != nil {
+		return x.Installed
+	}
+	return false
+}
+
+func (x *VersionInfo) GetDefault() bool {
+	if x != nil {
+		return x.Default
+	}
+	return false
+}
+
+func (x *VersionInfo) GetPinned() string {
+	if x != nil {
+		return x.Pinned
+	}
+	return ""
+}
+
+// Supported language
+type LanguageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`         // Language name
+	Aliases       []string               `protobuf:"bytes,2,rep,name=aliases,proto3" json:"aliases,omitempty"`   // Other names of the language
+	Versions      []*VersionInfo         `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty"` // Supported versions
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LanguageInfo) Reset() {
+	*x = LanguageInfo{}
+	mi := &file_executor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LanguageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LanguageInfo) ProtoMessage() {}
+
+func (x *LanguageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LanguageInfo.ProtoReflect.Descriptor instead.
+func (*LanguageInfo) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LanguageInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LanguageInfo) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *LanguageInfo) GetVersions() []*VersionInfo {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// Languages response
+type ListLanguagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Languages     []*LanguageInfo        `protobuf:"bytes,1,rep,name=languages,proto3" json:"languages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLanguagesResponse) Reset() {
+	*x = ListLanguagesResponse{}
+	mi := &file_executor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLanguagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLanguagesResponse) ProtoMessage() {}
+
+func (x *ListLanguagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLanguagesResponse.ProtoReflect.Descriptor instead.
+func (*ListLanguagesResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListLanguagesResponse) GetLanguages() []*LanguageInfo {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+// Health check request
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_executor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{13}
+}
+
+// Health check response
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                    // Status of the service, not_ready while required images are missing
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`                                  // Service version
+	MissingImages []string               `protobuf:"bytes,3,rep,name=missing_images,json=missingImages,proto3" json:"missing_images,omitempty"` // Required execution images that are not installed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_executor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetMissingImages() []string {
+	if x != nil {
+		return x.MissingImages
+	}
+	return nil
+}
+
+// Hint request
+type HintRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubmissionId  string                 `protobuf:"bytes,1,opt,name=submission_id,json=submissionId,proto3" json:"submission_id,omitempty"`     // Submission the hint belongs to
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`                                 // Programming language of the submission
+	Code          string                 `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`                                         // Submitted code
+	LastExecution *ExecuteResponse       `protobuf:"bytes,4,opt,name=last_execution,json=lastExecution,proto3" json:"last_execution,omitempty"`  // Latest execution result of the submission
+	RevealedLevel int32                  `protobuf:"varint,5,opt,name=revealed_level,json=revealedLevel,proto3" json:"revealed_level,omitempty"` // Highest hint level already revealed (0 for none), must match the ledger
+	UserId        string                 `protobuf:"bytes,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                       // Learner requesting the hint, for budgeting
+	CourseId      string                 `protobuf:"bytes,7,opt,name=course_id,json=courseId,proto3" json:"course_id,omitempty"`                 // Course of the exercise, for budgeting
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HintRequest) Reset() {
+	*x = HintRequest{}
+	mi := &file_executor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HintRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HintRequest) ProtoMessage() {}
+
+func (x *HintRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HintRequest.ProtoReflect.Descriptor instead.
+func (*HintRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *HintRequest) GetSubmissionId() string {
+	if x != nil {
+		return x.SubmissionId
+	}
+	return ""
+}
+
+func (x *HintRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *HintRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *HintRequest) GetLastExecution() *ExecuteResponse {
+	if x != nil {
+		return x.LastExecution
+	}
+	return nil
+}
+
+func (x *HintRequest) GetRevealedLevel() int32 {
+	if x != nil {
+		return x.RevealedLevel
+	}
+	return 0
+}
+
+func (x *HintRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *HintRequest) GetCourseId() string {
+	if x != nil {
+		return x.CourseId
+	}
+	return ""
+}
+
+// Hint response
+type HintResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hint          string                 `protobuf:"bytes,1,opt,name=hint,proto3" json:"hint,omitempty"`                                      // Hint text
+	Level         int32                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`                                   // Level of this hint
+	MaxLevel      int32                  `protobuf:"varint,3,opt,name=max_level,json=maxLevel,proto3" json:"max_level,omitempty"`             // Most specific level available
+	HintsUsed     int32                  `protobuf:"varint,4,opt,name=hints_used,json=hintsUsed,proto3" json:"hints_used,omitempty"`          // Number of hints revealed for the submission
+	InputTokens   int64                  `protobuf:"varint,5,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`    // Model input tokens used for this hint
+	OutputTokens  int64                  `protobuf:"varint,6,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"` // Model output tokens used for this hint
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HintResponse) Reset() {
+	*x = HintResponse{}
+	mi := &file_executor_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HintResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HintResponse) ProtoMessage() {}
+
+func (x *HintResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HintResponse.ProtoReflect.Descriptor instead.
+func (*HintResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *HintResponse) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+func (x *HintResponse) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *HintResponse) GetMaxLevel() int32 {
+	if x != nil {
+		return x.MaxLevel
+	}
+	return 0
+}
+
+func (x *HintResponse) GetHintsUsed() int32 {
+	if x != nil {
+		return x.HintsUsed
+	}
+	return 0
+}
+
+func (x *HintResponse) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *HintResponse) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+// Replay request
+type ReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`  // Stored execution to run again
+	LatestImage   bool                   `protobuf:"varint,2,opt,name=latest_image,json=latestImage,proto3" json:"latest_image,omitempty"` // Run on the current image instead of the recorded one
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`                             // Run on the current image of another version, e.g. to check an upgrade
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayRequest) Reset() {
+	*x = ReplayRequest{}
+	mi := &file_executor_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayRequest) ProtoMessage() {}
+
+func (x *ReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayRequest.ProtoReflect.Descriptor instead.
+func (*ReplayRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReplayRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ReplayRequest) GetLatestImage() bool {
+	if x != nil {
+		return x.LatestImage
+	}
+	return false
+}
+
+func (x *ReplayRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// Field of the result that changed between the stored run and a replay
+type Difference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`       // Result field, e.g. stdout or artifacts/<path>
+	Stored        string                 `protobuf:"bytes,2,opt,name=stored,proto3" json:"stored,omitempty"`     // Value of the stored run
+	Replayed      string                 `protobuf:"bytes,3,opt,name=replayed,proto3" json:"replayed,omitempty"` // Value of the replay
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Difference) Reset() {
+	*x = Difference{}
+	mi := &file_executor_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Difference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Difference) ProtoMessage() {}
+
+func (x *Difference) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Difference.ProtoReflect.Descriptor instead.
+func (*Difference) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Difference) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Difference) GetStored() string {
+	if x != nil {
+		return x.Stored
+	}
+	return ""
+}
+
+func (x *Difference) GetReplayed() string {
+	if x != nil {
+		return x.Replayed
+	}
+	return ""
+}
+
+// Replay response
+type ReplayResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"` // Stored execution that was replayed
+	Identical     bool                   `protobuf:"varint,2,opt,name=identical,proto3" json:"identical,omitempty"`                       // The replay produced the stored result
+	Differences   []*Difference          `protobuf:"bytes,3,rep,name=differences,proto3" json:"differences,omitempty"`
+	Result        *ExecuteResponse       `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"` // Result of the replay
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayResponse) Reset() {
+	*x = ReplayResponse{}
+	mi := &file_executor_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayResponse) ProtoMessage() {}
+
+func (x *ReplayResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayResponse.ProtoReflect.Descriptor instead.
+func (*ReplayResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReplayResponse) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ReplayResponse) GetIdentical() bool {
+	if x != nil {
+		return x.Identical
+	}
+	return false
+}
+
+func (x *ReplayResponse) GetDifferences() []*Difference {
+	if x != nil {
+		return x.Differences
+	}
+	return nil
+}
+
+func (x *ReplayResponse) GetResult() *ExecuteResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// Code in a language
+type Program struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Language      string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"` // Programming language, optionally with a version like python@3.12
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Program) Reset() {
+	*x = Program{}
+	mi := &file_executor_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Program) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Program) ProtoMessage() {}
+
+func (x *Program) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Program.ProtoReflect.Descriptor instead.
+func (*Program) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Program) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Program) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// Complexity analysis request
+type ComplexityRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Language       string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`                                    // Language of the submission
+	Code           string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`                                            // Submission, reading the generated input from stdin
+	Generator      *Program               `protobuf:"bytes,3,opt,name=generator,proto3" json:"generator,omitempty"`                                  // Prints the input of the size given as its first argument
+	Sizes          []int64                `protobuf:"varint,4,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`                                  // Input sizes in increasing order (3 to 20)
+	Repeats        int32                  `protobuf:"varint,5,opt,name=repeats,proto3" json:"repeats,omitempty"`                                     // Runs per size, the fastest counts (default: 1, max: 5)
+	TimeoutSeconds int32                  `protobuf:"varint,6,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // Timeout of every run (default: 30s)
+	MemoryLimitMb  int64                  `protobuf:"varint,7,opt,name=memory_limit_mb,json=memoryLimitMb,proto3" json:"memory_limit_mb,omitempty"`  // Memory limit of every run (default: 128MB)
+	CpuLimit       float64                `protobuf:"fixed64,8,opt,name=cpu_limit,json=cpuLimit,proto3" json:"cpu_limit,omitempty"`                  // CPU limit of every run (default: 0.5)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ComplexityRequest) Reset() {
+	*x = ComplexityRequest{}
+	mi := &file_executor_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplexityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplexityRequest) ProtoMessage() {}
+
+func (x *ComplexityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplexityRequest.ProtoReflect.Descriptor instead.
+func (*ComplexityRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ComplexityRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ComplexityRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ComplexityRequest) GetGenerator() *Program {
+	if x != nil {
+		return x.Generator
+	}
+	return nil
+}
+
+func (x *ComplexityRequest) GetSizes() []int64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *ComplexityRequest) GetRepeats() int32 {
+	if x != nil {
+		return x.Repeats
+	}
+	return 0
+}
+
+func (x *ComplexityRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ComplexityRequest) GetMemoryLimitMb() int64 {
+	if x != nil {
+		return x.MemoryLimitMb
+	}
+	return 0
+}
+
+func (x *ComplexityRequest) GetCpuLimit() float64 {
+	if x != nil {
+		return x.CpuLimit
+	}
+	return 0
+}
+
+// Usage of the submission at an input size
+type ComplexityMeasurement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	InputBytes    int64                  `protobuf:"varint,2,opt,name=input_bytes,json=inputBytes,proto3" json:"input_bytes,omitempty"`    // Size of the generated input
+	CpuTimeUs     int64                  `protobuf:"varint,3,opt,name=cpu_time_us,json=cpuTimeUs,proto3" json:"cpu_time_us,omitempty"`     // CPU time of the fastest run in microseconds
+	MemoryBytes   int64                  `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"` // Peak memory of the fastest run
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                                 // Why the submission failed at this size, which ends the analysis
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComplexityMeasurement) Reset() {
+	*x = ComplexityMeasurement{}
+	mi := &file_executor_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplexityMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplexityMeasurement) ProtoMessage() {}
+
+func (x *ComplexityMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplexityMeasurement.ProtoReflect.Descriptor instead.
+func (*ComplexityMeasurement) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ComplexityMeasurement) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ComplexityMeasurement) GetInputBytes() int64 {
+	if x != nil {
+		return x.InputBytes
+	}
+	return 0
+}
+
+func (x *ComplexityMeasurement) GetCpuTimeUs() int64 {
+	if x != nil {
+		return x.CpuTimeUs
+	}
+	return 0
+}
+
+func (x *ComplexityMeasurement) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *ComplexityMeasurement) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Class that explains the measurements with its probability
+type ComplexityCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Class         string                 `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"` // O(1), O(log n), O(n), O(n log n), O(n^2) or O(2^n)
+	Probability   float64                `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComplexityCandidate) Reset() {
+	*x = ComplexityCandidate{}
+	mi := &file_executor_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplexityCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplexityCandidate) ProtoMessage() {}
+
+func (x *ComplexityCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplexityCandidate.ProtoReflect.Descriptor instead.
+func (*ComplexityCandidate) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ComplexityCandidate) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *ComplexityCandidate) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+// Class that best explains a series of measurements
+type ComplexityFit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Class         string                 `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"` // Probability of the class among the candidates
+	Candidates    []*ComplexityCandidate `protobuf:"bytes,3,rep,name=candidates,proto3" json:"candidates,omitempty"`   // Most likely first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComplexityFit) Reset() {
+	*x = ComplexityFit{}
+	mi := &file_executor_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplexityFit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplexityFit) ProtoMessage() {}
+
+func (x *ComplexityFit) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplexityFit.ProtoReflect.Descriptor instead.
+func (*ComplexityFit) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ComplexityFit) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *ComplexityFit) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *ComplexityFit) GetCandidates() []*ComplexityCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+// Complexity analysis response
+type ComplexityResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Measurements  []*ComplexityMeasurement `protobuf:"bytes,1,rep,name=measurements,proto3" json:"measurements,omitempty"`
+	Cpu           *ComplexityFit           `protobuf:"bytes,2,opt,name=cpu,proto3" json:"cpu,omitempty"` // Unset if the submission failed before three sizes
+	Memory        *ComplexityFit           `protobuf:"bytes,3,opt,name=memory,proto3" json:"memory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComplexityResponse) Reset() {
+	*x = ComplexityResponse{}
+	mi := &file_executor_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComplexityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComplexityResponse) ProtoMessage() {}
+
+func (x *ComplexityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComplexityResponse.ProtoReflect.Descriptor instead.
+func (*ComplexityResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ComplexityResponse) GetMeasurements() []*ComplexityMeasurement {
+	if x != nil {
+		return x.Measurements
+	}
+	return nil
+}
+
+func (x *ComplexityResponse) GetCpu() *ComplexityFit {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *ComplexityResponse) GetMemory() *ComplexityFit {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+// Benchmark request
+type BenchmarkRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Language       string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`                                         // Programming language, optionally with a version like python@3.12
+	Code           string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`                                                 // Submission
+	Input          string                 `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`                                               // Stdin input of every run
+	Args           []string               `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`                                                 // Program arguments of every run
+	Runs           int32                  `protobuf:"varint,5,opt,name=runs,proto3" json:"runs,omitempty"`                                                // Measured runs (default: 10, 3 to 50)
+	Warmup         *int32                 `protobuf:"varint,6,opt,name=warmup,proto3,oneof" json:"warmup,omitempty"`                                      // Runs before the measured ones, 0 for none (default: 1, max: 10)
+	ReferenceCode  string                 `protobuf:"bytes,7,opt,name=reference_code,json=referenceCode,proto3" json:"reference_code,omitempty"`          // Reference solution in the same language to compare against
+	TimeoutSeconds int32                  `protobuf:"varint,8,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`      // Timeout of every run (default: 30s)
+	MemoryLimitMb  int64                  `protobuf:"varint,9,opt,name=memory_limit_mb,json=memoryLimitMb,proto3" json:"memory_limit_mb,omitempty"`       // Memory limit of every run (default: 128MB)
+	CpuLimit       float64                `protobuf:"fixed64,10,opt,name=cpu_limit,json=cpuLimit,proto3" json:"cpu_limit,omitempty"`                      // CPU limit of every run (default: 0.5)
+	CpuTimeLimitMs int64                  `protobuf:"varint,11,opt,name=cpu_time_limit_ms,json=cpuTimeLimitMs,proto3" json:"cpu_time_limit_ms,omitempty"` // CPU time limit of every run (default: none)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BenchmarkRequest) Reset() {
+	*x = BenchmarkRequest{}
+	mi := &file_executor_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkRequest) ProtoMessage() {}
+
+func (x *BenchmarkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkRequest.ProtoReflect.Descriptor instead.
+func (*BenchmarkRequest) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BenchmarkRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *BenchmarkRequest) GetRuns() int32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetWarmup() int32 {
+	if x != nil && x.Warmup != nil {
+		return *x.Warmup
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetReferenceCode() string {
+	if x != nil {
+		return x.ReferenceCode
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetMemoryLimitMb() int64 {
+	if x != nil {
+		return x.MemoryLimitMb
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetCpuLimit() float64 {
+	if x != nil {
+		return x.CpuLimit
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetCpuTimeLimitMs() int64 {
+	if x != nil {
+		return x.CpuTimeLimitMs
+	}
+	return 0
+}
+
+// Distribution of a measurement over the measured runs
+type BenchmarkSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
+	Median        float64                `protobuf:"fixed64,2,opt,name=median,proto3" json:"median,omitempty"`
+	P90           float64                `protobuf:"fixed64,3,opt,name=p90,proto3" json:"p90,omitempty"`
+	Stddev        float64                `protobuf:"fixed64,4,opt,name=stddev,proto3" json:"stddev,omitempty"`
+	Noisy         bool                   `protobuf:"varint,5,opt,name=noisy,proto3" json:"noisy,omitempty"` // The standard deviation exceeds 10% of the mean
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkSummary) Reset() {
+	*x = BenchmarkSummary{}
+	mi := &file_executor_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkSummary) ProtoMessage() {}
+
+func (x *BenchmarkSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkSummary.ProtoReflect.Descriptor instead.
+func (*BenchmarkSummary) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *BenchmarkSummary) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *BenchmarkSummary) GetMedian() float64 {
+	if x != nil {
+		return x.Median
+	}
+	return 0
+}
+
+func (x *BenchmarkSummary) GetP90() float64 {
+	if x != nil {
+		return x.P90
+	}
+	return 0
+}
+
+func (x *BenchmarkSummary) GetStddev() float64 {
+	if x != nil {
+		return x.Stddev
+	}
+	return 0
+}
+
+func (x *BenchmarkSummary) GetNoisy() bool {
+	if x != nil {
+		return x.Noisy
+	}
+	return false
+}
+
+// Usage of a program over the measured runs
+type BenchmarkMeasurements struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          int32                  `protobuf:"varint,1,opt,name=runs,proto3" json:"runs,omitempty"` // Measured runs completed
+	CpuTimeUs     *BenchmarkSummary      `protobuf:"bytes,2,opt,name=cpu_time_us,json=cpuTimeUs,proto3" json:"cpu_time_us,omitempty"`
+	MemoryBytes   *BenchmarkSummary      `protobuf:"bytes,3,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"` // Peak resident memory
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                                // Why the program failed, which ends its benchmark
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkMeasurements) Reset() {
+	*x = BenchmarkMeasurements{}
+	mi := &file_executor_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkMeasurements) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkMeasurements) ProtoMessage() {}
+
+func (x *BenchmarkMeasurements) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkMeasurements.ProtoReflect.Descriptor instead.
+func (*BenchmarkMeasurements) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *BenchmarkMeasurements) GetRuns() int32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *BenchmarkMeasurements) GetCpuTimeUs() *BenchmarkSummary {
+	if x != nil {
+		return x.CpuTimeUs
+	}
+	return nil
+}
+
+func (x *BenchmarkMeasurements) GetMemoryBytes() *BenchmarkSummary {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return nil
+}
+
+func (x *BenchmarkMeasurements) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Benchmark response
+type BenchmarkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Submission    *BenchmarkMeasurements `protobuf:"bytes,1,opt,name=submission,proto3" json:"submission,omitempty"`
+	Reference     *BenchmarkMeasurements `protobuf:"bytes,2,opt,name=reference,proto3" json:"reference,omitempty"` // Unset without a reference solution
+	Score         float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`       // Median CPU time of the reference relative to the submission's
+	Noisy         bool                   `protobuf:"varint,4,opt,name=noisy,proto3" json:"noisy,omitempty"`        // The CPU time of the submission or the reference is noisy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkResponse) Reset() {
+	*x = BenchmarkResponse{}
+	mi := &file_executor_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkResponse) ProtoMessage() {}
+
+func (x *BenchmarkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkResponse.ProtoReflect.Descriptor instead.
+func (*BenchmarkResponse) Descriptor() ([]byte, []int) {
+	return file_executor_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *BenchmarkResponse) GetSubmission() *BenchmarkMeasurements {
+	if x != nil {
+		return x.Submission
+	}
+	return nil
+}
+
+func (x *BenchmarkResponse) GetReference() *BenchmarkMeasurements {
+	if x != nil {
+		return x.Reference
+	}
+	return nil
+}
+
+func (x *BenchmarkResponse) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *BenchmarkResponse) GetNoisy() bool {
+	if x != nil {
+		return x.Noisy
+	}
+	return false
+}
+
+var File_executor_proto protoreflect.FileDescriptor
+
+const file_executor_proto_rawDesc = "" +
+	"\n" +
+	"\x0eexecutor.proto\x12\bexecutor\"\xfb\x04\n" +
+	"\x0eExecuteRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x14\n" +
+	"\x05input\x18\x03 \x01(\tR\x05input\x12'\n" +
+	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\x12&\n" +
+	"\x0fmemory_limit_mb\x18\x05 \x01(\x03R\rmemoryLimitMb\x12\x1b\n" +
+	"\tcpu_limit\x18\x06 \x01(\x01R\bcpuLimit\x12'\n" +
+	"\x0fcombined_output\x18\a \x01(\bR\x0ecombinedOutput\x12\x1c\n" +
+	"\tartifacts\x18\b \x03(\tR\tartifacts\x12-\n" +
+	"\bfixtures\x18\t \x03(\v2\x11.executor.FixtureR\bfixtures\x12\x12\n" +
+	"\x04args\x18\n" +
+	" \x03(\tR\x04args\x123\n" +
+	"\x03env\x18\v \x03(\v2!.executor.ExecuteRequest.EnvEntryR\x03env\x12.\n" +
+	"\bmanifest\x18\f \x01(\v2\x12.executor.ManifestR\bmanifest\x127\n" +
+	"\vdeterminism\x18\r \x01(\v2\x15.executor.DeterminismR\vdeterminism\x12)\n" +
+	"\x11cpu_time_limit_ms\x18\x0e \x01(\x03R\x0ecpuTimeLimitMs\x12*\n" +
+	"\x11usage_interval_ms\x18\x0f \x01(\x03R\x0fusageIntervalMs\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xad\x01\n" +
+	"\vDeterminism\x12\x12\n" +
+	"\x04seed\x18\x01 \x01(\rR\x04seed\x12$\n" +
+	"\x0efake_time_unix\x18\x02 \x01(\x03R\ffakeTimeUnix\x12\x17\n" +
+	"\apin_cpu\x18\x03 \x01(\bR\x06pinCpu\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x16\n" +
+	"\x06locale\x18\x05 \x01(\tR\x06locale\x12\x17\n" +
+	"\acpu_set\x18\x06 \x01(\tR\x06cpuSet\"8\n" +
+	"\bManifest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"G\n" +
+	"\aFixture\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\"O\n" +
+	"\rFixtureDigest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\"V\n" +
+	"\vOutputChunk\x12\x16\n" +
+	"\x06stream\x18\x01 \x01(\tR\x06stream\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12\x1b\n" +
+	"\toffset_ms\x18\x03 \x01(\x03R\boffsetMs\"\xc5\x01\n" +
+	"\n" +
+	"UsagePoint\x12\x1b\n" +
+	"\toffset_ms\x18\x01 \x01(\x03R\boffsetMs\x12\x1f\n" +
+	"\vcpu_percent\x18\x02 \x01(\x01R\n" +
+	"cpuPercent\x12\x1b\n" +
+	"\trss_bytes\x18\x03 \x01(\x03R\brssBytes\x12\x12\n" +
+	"\x04pids\x18\x04 \x01(\x03R\x04pids\x12\"\n" +
+	"\rio_read_bytes\x18\x05 \x01(\x03R\vioReadBytes\x12$\n" +
+	"\x0eio_write_bytes\x18\x06 \x01(\x03R\fioWriteBytes\"L\n" +
+	"\bArtifact\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"\xb6\t\n" +
+	"\x0fExecuteResponse\x12\x16\n" +
+	"\x06stdout\x18\x01 \x01(\tR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x02 \x01(\tR\x06stderr\x12\x1b\n" +
+	"\texit_code\x18\x03 \x01(\x05R\bexitCode\x12\x18\n" +
+	"\atimeout\x18\x04 \x01(\bR\atimeout\x12'\n" +
+	"\x0fmemory_exceeded\x18\x05 \x01(\bR\x0ememoryExceeded\x12*\n" +
+	"\x11execution_time_ms\x18\x06 \x01(\x03R\x0fexecutionTimeMs\x12$\n" +
+	"\x0ememory_used_mb\x18\a \x01(\x03R\fmemoryUsedMb\x12)\n" +
+	"\x10stdout_truncated\x18\b \x01(\bR\x0fstdoutTruncated\x12)\n" +
+	"\x10stderr_truncated\x18\t \x01(\bR\x0fstderrTruncated\x12!\n" +
+	"\fstdout_bytes\x18\n" +
+	" \x01(\x03R\vstdoutBytes\x12!\n" +
+	"\fstderr_bytes\x18\v \x01(\x03R\vstderrBytes\x12-\n" +
+	"\x06output\x18\f \x03(\v2\x15.executor.OutputChunkR\x06output\x120\n" +
+	"\tartifacts\x18\r \x03(\v2\x12.executor.ArtifactR\tartifacts\x12/\n" +
+	"\x13artifacts_truncated\x18\x0e \x01(\bR\x12artifactsTruncated\x123\n" +
+	"\bfixtures\x18\x0f \x03(\v2\x17.executor.FixtureDigestR\bfixtures\x12\x18\n" +
+	"\aversion\x18\x10 \x01(\tR\aversion\x12!\n" +
+	"\fimage_digest\x18\x11 \x01(\tR\vimageDigest\x12/\n" +
+	"\x13dependencies_cached\x18\x12 \x01(\bR\x12dependenciesCached\x12&\n" +
+	"\x0finstall_time_ms\x18\x13 \x01(\x03R\rinstallTimeMs\x12!\n" +
+	"\fbuild_cached\x18\x14 \x01(\bR\vbuildCached\x12&\n" +
+	"\x0fcompile_time_ms\x18\x15 \x01(\x03R\rcompileTimeMs\x127\n" +
+	"\vdeterminism\x18\x16 \x01(\v2\x15.executor.DeterminismR\vdeterminism\x12!\n" +
+	"\fexecution_id\x18\x17 \x01(\tR\vexecutionId\x12\x1e\n" +
+	"\vcpu_time_ms\x18\x18 \x01(\x03R\tcpuTimeMs\x12'\n" +
+	"\x10cpu_user_time_ms\x18\x19 \x01(\x03R\rcpuUserTimeMs\x12+\n" +
+	"\x12cpu_system_time_ms\x18\x1a \x01(\x03R\x0fcpuSystemTimeMs\x12*\n" +
+	"\x11cpu_time_exceeded\x18\x1b \x01(\bR\x0fcpuTimeExceeded\x12&\n" +
+	"\x0fstartup_time_ms\x18\x1c \x01(\x03R\rstartupTimeMs\x12+\n" +
+	"\x12image_pull_time_ms\x18\x1d \x01(\x03R\x0fimagePullTimeMs\x12*\n" +
+	"\x05usage\x18\x1e \x03(\v2\x14.executor.UsagePointR\x05usage\"\x16\n" +
+	"\x14ListLanguagesRequest\"\xa5\x01\n" +
+	"\vVersionInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x14\n" +
+	"\x05image\x18\x02 \x01(\tR\x05image\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\tR\x06digest\x12\x1c\n" +
+	"\tinstalled\x18\x04 \x01(\bR\tinstalled\x12\x18\n" +
+	"\adefault\x18\x05 \x01(\bR\adefault\x12\x16\n" +
+	"\x06pinned\x18\x06 \x01(\tR\x06pinned\"o\n" +
+	"\fLanguageInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aaliases\x18\x02 \x03(\tR\aaliases\x121\n" +
+	"\bversions\x18\x03 \x03(\v2\x15.executor.VersionInfoR\bversions\"M\n" +
+	"\x15ListLanguagesResponse\x124\n" +
+	"\tlanguages\x18\x01 \x03(\v2\x16.executor.LanguageInfoR\tlanguages\"\x0f\n" +
+	"\rHealthRequest\"i\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12%\n" +
+	"\x0emissing_images\x18\x03 \x03(\tR\rmissingImages\"\x81\x02\n" +
+	"\vHintRequest\x12#\n" +
+	"\rsubmission_id\x18\x01 \x01(\tR\fsubmissionId\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12@\n" +
+	"\x0elast_execution\x18\x04 \x01(\v2\x19.executor.ExecuteResponseR\rlastExecution\x12%\n" +
+	"\x0erevealed_level\x18\x05 \x01(\x05R\rrevealedLevel\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tcourse_id\x18\a \x01(\tR\bcourseId\"\xbc\x01\n" +
+	"\fHintResponse\x12\x12\n" +
+	"\x04hint\x18\x01 \x01(\tR\x04hint\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\x12\x1b\n" +
+	"\tmax_level\x18\x03 \x01(\x05R\bmaxLevel\x12\x1d\n" +
+	"\n" +
+	"hints_used\x18\x04 \x01(\x05R\thintsUsed\x12!\n" +
+	"\finput_tokens\x18\x05 \x01(\x03R\vinputTokens\x12#\n" +
+	"\routput_tokens\x18\x06 \x01(\x03R\foutputTokens\"o\n" +
+	"\rReplayRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12!\n" +
+	"\flatest_image\x18\x02 \x01(\bR\vlatestImage\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"V\n" +
+	"\n" +
+	"Difference\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06stored\x18\x02 \x01(\tR\x06stored\x12\x1a\n" +
+	"\breplayed\x18\x03 \x01(\tR\breplayed\"\xbc\x01\n" +
+	"\x0eReplayResponse\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x1c\n" +
+	"\tidentical\x18\x02 \x01(\bR\tidentical\x126\n" +
+	"\vdifferences\x18\x03 \x03(\v2\x14.executor.DifferenceR\vdifferences\x121\n" +
+	"\x06result\x18\x04 \x01(\v2\x19.executor.ExecuteResponseR\x06result\"9\n" +
+	"\aProgram\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"\x92\x02\n" +
+	"\x11ComplexityRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12/\n" +
+	"\tgenerator\x18\x03 \x01(\v2\x11.executor.ProgramR\tgenerator\x12\x14\n" +
+	"\x05sizes\x18\x04 \x03(\x03R\x05sizes\x12\x18\n" +
+	"\arepeats\x18\x05 \x01(\x05R\arepeats\x12'\n" +
+	"\x0ftimeout_seconds\x18\x06 \x01(\x05R\x0etimeoutSeconds\x12&\n" +
+	"\x0fmemory_limit_mb\x18\a \x01(\x03R\rmemoryLimitMb\x12\x1b\n" +
+	"\tcpu_limit\x18\b \x01(\x01R\bcpuLimit\"\xa5\x01\n" +
+	"\x15ComplexityMeasurement\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vinput_bytes\x18\x02 \x01(\x03R\n" +
+	"inputBytes\x12\x1e\n" +
+	"\vcpu_time_us\x18\x03 \x01(\x03R\tcpuTimeUs\x12!\n" +
+	"\fmemory_bytes\x18\x04 \x01(\x03R\vmemoryBytes\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"M\n" +
+	"\x13ComplexityCandidate\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"\x84\x01\n" +
+	"\rComplexityFit\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\x12=\n" +
+	"\n" +
+	"candidates\x18\x03 \x03(\v2\x1d.executor.ComplexityCandidateR\n" +
+	"candidates\"\xb5\x01\n" +
+	"\x12ComplexityResponse\x12C\n" +
+	"\fmeasurements\x18\x01 \x03(\v2\x1f.executor.ComplexityMeasurementR\fmeasurements\x12)\n" +
+	"\x03cpu\x18\x02 \x01(\v2\x17.executor.ComplexityFitR\x03cpu\x12/\n" +
+	"\x06memory\x18\x03 \x01(\v2\x17.executor.ComplexityFitR\x06memory\"\xe8\x02\n" +
+	"\x10BenchmarkRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x14\n" +
+	"\x05input\x18\x03 \x01(\tR\x05input\x12\x12\n" +
+	"\x04args\x18\x04 \x03(\tR\x04args\x12\x12\n" +
+	"\x04runs\x18\x05 \x01(\x05R\x04runs\x12\x1b\n" +
+	"\x06warmup\x18\x06 \x01(\x05H\x00R\x06warmup\x88\x01\x01\x12%\n" +
+	"\x0ereference_code\x18\a \x01(\tR\rreferenceCode\x12'\n" +
+	"\x0ftimeout_seconds\x18\b \x01(\x05R\x0etimeoutSeconds\x12&\n" +
+	"\x0fmemory_limit_mb\x18\t \x01(\x03R\rmemoryLimitMb\x12\x1b\n" +
+	"\tcpu_limit\x18\n" +
+	" \x01(\x01R\bcpuLimit\x12)\n" +
+	"\x11cpu_time_limit_ms\x18\v \x01(\x03R\x0ecpuTimeLimitMsB\t\n" +
+	"\a_warmup\"|\n" +
+	"\x10BenchmarkSummary\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x16\n" +
+	"\x06median\x18\x02 \x01(\x01R\x06median\x12\x10\n" +
+	"\x03p90\x18\x03 \x01(\x01R\x03p90\x12\x16\n" +
+	"\x06stddev\x18\x04 \x01(\x01R\x06stddev\x12\x14\n" +
+	"\x05noisy\x18\x05 \x01(\bR\x05noisy\"\xbc\x01\n" +
+	"\x15BenchmarkMeasurements\x12\x12\n" +
+	"\x04runs\x18\x01 \x01(\x05R\x04runs\x12:\n" +
+	"\vcpu_time_us\x18\x02 \x01(\v2\x1a.executor.BenchmarkSummaryR\tcpuTimeUs\x12=\n" +
+	"\fmemory_bytes\x18\x03 \x01(\v2\x1a.executor.BenchmarkSummaryR\vmemoryBytes\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xbf\x01\n" +
+	"\x11BenchmarkResponse\x12?\n" +
+	"\n" +
+	"submission\x18\x01 \x01(\v2\x1f.executor.BenchmarkMeasurementsR\n" +
+	"submission\x12=\n" +
+	"\treference\x18\x02 \x01(\v2\x1f.executor.BenchmarkMeasurementsR\treference\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12\x14\n" +
+	"\x05noisy\x18\x04 \x01(\bR\x05noisy2\xea\x03\n" +
 	"\fCodeExecutor\x12>\n" +
 	"\aExecute\x12\x18.executor.ExecuteRequest\x1a\x19.executor.ExecuteResponse\x12;\n" +
-	"\x06Health\x12\x17.executor.HealthRequest\x1a\x18.executor.HealthResponseB\x15Z\x13code-executor/protob\x06proto3"
+	"\x06Health\x12\x17.executor.HealthRequest\x1a\x18.executor.HealthResponse\x128\n" +
+	"\aGetHint\x12\x15.executor.HintRequest\x1a\x16.executor.HintResponse\x12P\n" +
+	"\rListLanguages\x12\x1e.executor.ListLanguagesRequest\x1a\x1f.executor.ListLanguagesResponse\x12;\n" +
+	"\x06Replay\x12\x17.executor.ReplayRequest\x1a\x18.executor.ReplayResponse\x12N\n" +
+	"\x11AnalyzeComplexity\x12\x1b.executor.ComplexityRequest\x1a\x1c.executor.ComplexityResponse\x12D\n" +
+	"\tBenchmark\x12\x1a.executor.BenchmarkRequest\x1a\x1b.executor.BenchmarkResponseB\x15Z\x13code-executor/protob\x06proto3"
 
 var (
 	file_executor_proto_rawDescOnce sync.Once
@@ -329,23 +2592,83 @@ func file_executor_proto_rawDescGZIP() []byte {
 	return file_executor_proto_rawDescData
 }
 
-var file_executor_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_executor_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_executor_proto_goTypes = []any{
-	(*ExecuteRequest)(nil),  // 0: executor.ExecuteRequest
-	(*ExecuteResponse)(nil), // 1: executor.ExecuteResponse
-	(*HealthRequest)(nil),   // 2: executor.HealthRequest
-	(*HealthResponse)(nil),  // 3: executor.HealthResponse
+	(*ExecuteRequest)(nil),        // 0: executor.ExecuteRequest
+	(*Determinism)(nil),           // 1: executor.Determinism
+	(*Manifest)(nil),              // 2: executor.Manifest
+	(*Fixture)(nil),               // 3: executor.Fixture
+	(*FixtureDigest)(nil),         // 4: executor.FixtureDigest
+	(*OutputChunk)(nil),           // 5: executor.OutputChunk
+	(*UsagePoint)(nil),            // 6: executor.UsagePoint
+	(*Artifact)(nil),              // 7: executor.Artifact
+	(*ExecuteResponse)(nil),       // 8: executor.ExecuteResponse
+	(*ListLanguagesRequest)(nil),  // 9: executor.ListLanguagesRequest
+	(*VersionInfo)(nil),           // 10: executor.VersionInfo
+	(*LanguageInfo)(nil),          // 11: executor.LanguageInfo
+	(*ListLanguagesResponse)(nil), // 12: executor.ListLanguagesResponse
+	(*HealthRequest)(nil),         // 13: executor.HealthRequest
+	(*HealthResponse)(nil),        // 14: executor.HealthResponse
+	(*HintRequest)(nil),           // 15: executor.HintRequest
+	(*HintResponse)(nil),          // 16: executor.HintResponse
+	(*ReplayRequest)(nil),         // 17: executor.ReplayRequest
+	(*Difference)(nil),            // 18: executor.Difference
+	(*ReplayResponse)(nil),        // 19: executor.ReplayResponse
+	(*Program)(nil),               // 20: executor.Program
+	(*ComplexityRequest)(nil),     // 21: executor.ComplexityRequest
+	(*ComplexityMeasurement)(nil), // 22: executor.ComplexityMeasurement
+	(*ComplexityCandidate)(nil),   // 23: executor.ComplexityCandidate
+	(*ComplexityFit)(nil),         // 24: executor.ComplexityFit
+	(*ComplexityResponse)(nil),    // 25: executor.ComplexityResponse
+	(*BenchmarkRequest)(nil),      // 26: executor.BenchmarkRequest
+	(*BenchmarkSummary)(nil),      // 27: executor.BenchmarkSummary
+	(*BenchmarkMeasurements)(nil), // 28: executor.BenchmarkMeasurements
+	(*BenchmarkResponse)(nil),     // 29: executor.BenchmarkResponse
+	nil,                           // 30: executor.ExecuteRequest.EnvEntry
 }
 var file_executor_proto_depIdxs = []int32{
-	0, // 0: executor.CodeExecutor.Execute:input_type -> executor.ExecuteRequest
-	2, // 1: executor.CodeExecutor.Health:input_type -> executor.HealthRequest
-	1, // 2: executor.CodeExecutor.Execute:output_type -> executor.ExecuteResponse
-	3, // 3: executor.CodeExecutor.Health:output_type -> executor.HealthResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3,  // 0: executor.ExecuteRequest.fixtures:type_name -> executor.Fixture
+	30, // 1: executor.ExecuteRequest.env:type_name -> executor.ExecuteRequest.EnvEntry
+	2,  // 2: executor.ExecuteRequest.manifest:type_name -> executor.Manifest
+	1,  // 3: executor.ExecuteRequest.determinism:type_name -> executor.Determinism
+	5,  // 4: executor.ExecuteResponse.output:type_name -> executor.OutputChunk
+	7,  // 5: executor.ExecuteResponse.artifacts:type_name -> executor.Artifact
+	4,  // 6: executor.ExecuteResponse.fixtures:type_name -> executor.FixtureDigest
+	1,  // 7: executor.ExecuteResponse.determinism:type_name -> executor.Determinism
+	6,  // 8: executor.ExecuteResponse.usage:type_name -> executor.UsagePoint
+	10, // 9: executor.LanguageInfo.versions:type_name -> executor.VersionInfo
+	11, // 10: executor.ListLanguagesResponse.languages:type_name -> executor.LanguageInfo
+	8,  // 11: executor.HintRequest.last_execution:type_name -> executor.ExecuteResponse
+	18, // 12: executor.ReplayResponse.differences:type_name -> executor.Difference
+	8,  // 13: executor.ReplayResponse.result:type_name -> executor.ExecuteResponse
+	20, // 14: executor.ComplexityRequest.generator:type_name -> executor.Program
+	23, // 15: executor.ComplexityFit.candidates:type_name -> executor.ComplexityCandidate
+	22, // 16: executor.ComplexityResponse.measurements:type_name -> executor.ComplexityMeasurement
+	24, // 17: executor.ComplexityResponse.cpu:type_name -> executor.ComplexityFit
+	24, // 18: executor.ComplexityResponse.memory:type_name -> executor.ComplexityFit
+	27, // 19: executor.BenchmarkMeasurements.cpu_time_us:type_name -> executor.BenchmarkSummary
+	27, // 20: executor.BenchmarkMeasurements.memory_bytes:type_name -> executor.BenchmarkSummary
+	28, // 21: executor.BenchmarkResponse.submission:type_name -> executor.BenchmarkMeasurements
+	28, // 22: executor.BenchmarkResponse.reference:type_name -> executor.BenchmarkMeasurements
+	0,  // 23: executor.CodeExecutor.Execute:input_type -> executor.ExecuteRequest
+	13, // 24: executor.CodeExecutor.Health:input_type -> executor.HealthRequest
+	15, // 25: executor.CodeExecutor.GetHint:input_type -> executor.HintRequest
+	9,  // 26: executor.CodeExecutor.ListLanguages:input_type -> executor.ListLanguagesRequest
+	17, // 27: executor.CodeExecutor.Replay:input_type -> executor.ReplayRequest
+	21, // 28: executor.CodeExecutor.AnalyzeComplexity:input_type -> executor.ComplexityRequest
+	26, // 29: executor.CodeExecutor.Benchmark:input_type -> executor.BenchmarkRequest
+	8,  // 30: executor.CodeExecutor.Execute:output_type -> executor.ExecuteResponse
+	14, // 31: executor.CodeExecutor.Health:output_type -> executor.HealthResponse
+	16, // 32: executor.CodeExecutor.GetHint:output_type -> executor.HintResponse
+	12, // 33: executor.CodeExecutor.ListLanguages:output_type -> executor.ListLanguagesResponse
+	19, // 34: executor.CodeExecutor.Replay:output_type -> executor.ReplayResponse
+	25, // 35: executor.CodeExecutor.AnalyzeComplexity:output_type -> executor.ComplexityResponse
+	29, // 36: executor.CodeExecutor.Benchmark:output_type -> executor.BenchmarkResponse
+	30, // [30:37] is the sub-list for method output_type
+	23, // [23:30] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_executor_proto_init() }
@@ -353,13 +2676,14 @@ func file_executor_proto_init() {
 	if File_executor_proto != nil {
 		return
 	}
+	file_executor_proto_msgTypes[26].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_executor_proto_rawDesc), len(file_executor_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
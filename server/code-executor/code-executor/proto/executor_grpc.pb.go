@@ -19,8 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CodeExecutor_Execute_FullMethodName = "/executor.CodeExecutor/Execute"
-	CodeExecutor_Health_FullMethodName  = "/executor.CodeExecutor/Health"
+	CodeExecutor_Execute_FullMethodName           = "/executor.CodeExecutor/Execute"
+	CodeExecutor_Health_FullMethodName            = "/executor.CodeExecutor/Health"
+	CodeExecutor_GetHint_FullMethodName           = "/executor.CodeExecutor/GetHint"
+	CodeExecutor_ListLanguages_FullMethodName     = "/executor.CodeExecutor/ListLanguages"
+	CodeExecutor_Replay_FullMethodName            = "/executor.CodeExecutor/Replay"
+	CodeExecutor_AnalyzeComplexity_FullMethodName = "/executor.CodeExecutor/AnalyzeComplexity"
+	CodeExecutor_Benchmark_FullMethodName         = "/executor.CodeExecutor/Benchmark"
 )
 
 // CodeExecutorClient is the client API for CodeExecutor service.
@@ -31,6 +36,11 @@ const (
 type CodeExecutorClient interface {
 	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	GetHint(ctx context.Context, in *HintRequest, opts ...grpc.CallOption) (*HintResponse, error)
+	ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error)
+	Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (*ReplayResponse, error)
+	AnalyzeComplexity(ctx context.Context, in *ComplexityRequest, opts ...grpc.CallOption) (*ComplexityResponse, error)
+	Benchmark(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (*BenchmarkResponse, error)
 }
 
 type codeExecutorClient struct {
@@ -61,6 +71,56 @@ func (c *codeExecutorClient) Health(ctx context.Context, in *HealthRequest, opts
 	return out, nil
 }
 
+func (c *codeExecutorClient) GetHint(ctx context.Context, in *HintRequest, opts ...grpc.CallOption) (*HintResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HintResponse)
+	err := c.cc.Invoke(ctx, CodeExecutor_GetHint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeExecutorClient) ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLanguagesResponse)
+	err := c.cc.Invoke(ctx, CodeExecutor_ListLanguages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeExecutorClient) Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (*ReplayResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayResponse)
+	err := c.cc.Invoke(ctx, CodeExecutor_Replay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeExecutorClient) AnalyzeComplexity(ctx context.Context, in *ComplexityRequest, opts ...grpc.CallOption) (*ComplexityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComplexityResponse)
+	err := c.cc.Invoke(ctx, CodeExecutor_AnalyzeComplexity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *codeExecutorClient) Benchmark(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (*BenchmarkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BenchmarkResponse)
+	err := c.cc.Invoke(ctx, CodeExecutor_Benchmark_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CodeExecutorServer is the server API for CodeExecutor service.
 // All implementations must embed UnimplementedCodeExecutorServer
 // for forward compatibility.
@@ -69,6 +129,11 @@ func (c *codeExecutorClient) Health(ctx context.Context, in *HealthRequest, opts
 type CodeExecutorServer interface {
 	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	GetHint(context.Context, *HintRequest) (*HintResponse, error)
+	ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error)
+	Replay(context.Context, *ReplayRequest) (*ReplayResponse, error)
+	AnalyzeComplexity(context.Context, *ComplexityRequest) (*ComplexityResponse, error)
+	Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error)
 	mustEmbedUnimplementedCodeExecutorServer()
 }
 
@@ -85,6 +150,21 @@ func (UnimplementedCodeExecutorServer) Execute(context.Context, *ExecuteRequest)
 func (UnimplementedCodeExecutorServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
 }
+func (UnimplementedCodeExecutorServer) GetHint(context.Context, *HintRequest) (*HintResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHint not implemented")
+}
+func (UnimplementedCodeExecutorServer) ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLanguages not implemented")
+}
+func (UnimplementedCodeExecutorServer) Replay(context.Context, *ReplayRequest) (*ReplayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+func (UnimplementedCodeExecutorServer) AnalyzeComplexity(context.Context, *ComplexityRequest) (*ComplexityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeComplexity not implemented")
+}
+func (UnimplementedCodeExecutorServer) Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Benchmark not implemented")
+}
 func (UnimplementedCodeExecutorServer) mustEmbedUnimplementedCodeExecutorServer() {}
 func (UnimplementedCodeExecutorServer) testEmbeddedByValue()                      {}
 
@@ -142,6 +222,96 @@ func _CodeExecutor_Health_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CodeExecutor_GetHint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeExecutorServer).GetHint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeExecutor_GetHint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeExecutorServer).GetHint(ctx, req.(*HintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeExecutor_ListLanguages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLanguagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeExecutorServer).ListLanguages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeExecutor_ListLanguages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeExecutorServer).ListLanguages(ctx, req.(*ListLanguagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeExecutor_Replay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeExecutorServer).Replay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeExecutor_Replay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeExecutorServer).Replay(ctx, req.(*ReplayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeExecutor_AnalyzeComplexity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComplexityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeExecutorServer).AnalyzeComplexity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeExecutor_AnalyzeComplexity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeExecutorServer).AnalyzeComplexity(ctx, req.(*ComplexityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CodeExecutor_Benchmark_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BenchmarkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CodeExecutorServer).Benchmark(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CodeExecutor_Benchmark_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CodeExecutorServer).Benchmark(ctx, req.(*BenchmarkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CodeExecutor_ServiceDesc is the grpc.ServiceDesc for CodeExecutor service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,6 +327,26 @@ var CodeExecutor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Health",
 			Handler:    _CodeExecutor_Health_Handler,
 		},
+		{
+			MethodName: "GetHint",
+			Handler:    _CodeExecutor_GetHint_Handler,
+		},
+		{
+			MethodName: "ListLanguages",
+			Handler:    _CodeExecutor_ListLanguages_Handler,
+		},
+		{
+			MethodName: "Replay",
+			Handler:    _CodeExecutor_Replay_Handler,
+		},
+		{
+			MethodName: "AnalyzeComplexity",
+			Handler:    _CodeExecutor_AnalyzeComplexity_Handler,
+		},
+		{
+			MethodName: "Benchmark",
+			Handler:    _CodeExecutor_Benchmark_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "executor.proto",
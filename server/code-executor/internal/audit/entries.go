@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"code-executor/internal/docker"
+)
+
+// ExecutionEntry returns the audit entry of an execution and its outcome
+func ExecutionEntry(caller Caller, config docker.ExecutionConfig, result *docker.ExecutionResult, err error) Entry {
+	entry := Entry{
+		Action:   ActionExecute,
+		Caller:   caller,
+		Language: config.Language,
+		CodeHash: HashCode(config.Code),
+		Limits: &Limits{
+			TimeoutMs:   config.Timeout.Milliseconds(),
+			MemoryBytes: config.MemoryLimit,
+			CPU:         config.CPULimit,
+			CPUTimeMs:   config.CPUTimeLimit.Milliseconds(),
+		},
+	}
+
+	switch {
+	case err != nil:
+		entry.Verdict = VerdictError
+		entry.Error = err.Error()
+	case result.Timeout || result.CPUTimeExceeded:
+		entry.Verdict = VerdictTimeout
+	case result.ExitCode != 0:
+		entry.Verdict = VerdictRuntimeError
+	default:
+		entry.Verdict = VerdictCompleted
+	}
+	if result != nil {
+		entry.ExitCode = result.ExitCode
+		entry.ContainerID = result.ContainerID
+		entry.Runtime = result.Runtime
+		entry.ImageDigest = result.ImageDigest
+	}
+	return entry
+}
+
+// ReviewEntry returns the audit entry of a review and its outcome
+func ReviewEntry(caller Caller, language, code, reviewer string, cached bool, err error) Entry {
+	entry := Entry{
+		Action:   ActionReview,
+		Caller:   caller,
+		Language: language,
+		CodeHash: HashCode(code),
+		Reviewer: reviewer,
+		Verdict:  VerdictReviewed,
+	}
+
+	switch {
+	case err != nil:
+		entry.Verdict = VerdictError
+		entry.Error = err.Error()
+	case cached:
+		entry.Verdict = VerdictCached
+	}
+	return entry
+}
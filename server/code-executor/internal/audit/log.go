@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"code-executor/internal/auth"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionExecute = "execute"
+	ActionReview  = "review"
+)
+
+// Verdicts of audited calls
+const (
+	VerdictCompleted    = "completed"     // Execution exited with code 0
+	VerdictRuntimeError = "runtime_error" // Execution exited with a non-zero code
+	VerdictTimeout      = "timeout"       // Execution exceeded its wall or CPU time limit
+	VerdictReviewed     = "reviewed"      // Review was produced by the reviewer
+	VerdictCached       = "cached"        // Review was served from the cache
+	VerdictError        = "error"         // Call failed, see the entry's error
+)
+
+// maxLineSize is the largest audit line read back from a log file
+const maxLineSize = 1024 * 1024
+
+// Caller identifies who made an audited call
+type Caller struct {
+	KeyID    string `json:"key_id,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	Role     string `json:"role,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	ClientIP string `json:"client_ip,omitempty"`
+}
+
+// CallerFromContext returns the caller of a request from its identity and client IP
+func CallerFromContext(ctx context.Context, clientIP string) Caller {
+	caller := Caller{ClientIP: clientIP}
+	if identity, ok := auth.FromContext(ctx); ok {
+		caller.KeyID = identity.KeyID
+		caller.UserID = identity.UserID
+		caller.Role = identity.Role
+		caller.TenantID = identity.TenantID
+	}
+	return caller
+}
+
+// Limits are the resource limits an execution ran with
+type Limits struct {
+	TimeoutMs   int64   `json:"timeout_ms"`
+	MemoryBytes int64   `json:"memory_bytes"`
+	CPU         float64 `json:"cpu"`
+	CPUTimeMs   int64   `json:"cpu_time_ms,omitempty"` // CPU time limit, none if zero
+}
+
+// Entry is one line of the audit log. Every entry carries the hash of its
+// predecessor, so changing, removing or reordering lines breaks the chain.
+// New fields must be omitempty so older entries still hash the same.
+type Entry struct {
+	Seq         uint64    `json:"seq"`
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Caller      Caller    `json:"caller"`
+	Language    string    `json:"language,omitempty"`
+	CodeHash    string    `json:"code_hash"`
+	Limits      *Limits   `json:"limits,omitempty"`
+	Verdict     string    `json:"verdict"`
+	ExitCode    int       `json:"exit_code,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Runtime     string    `json:"runtime,omitempty"`
+	ImageDigest string    `json:"image_digest,omitempty"`
+	Reviewer    string    `json:"reviewer,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash,omitempty"`
+}
+
+// HashCode returns the hash identifying submitted code
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// computeHash returns the hash of an entry without its own hash field
+func computeHash(entry Entry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an append-only, hash-chained JSON lines audit log
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      uint64
+	lastHash string
+	now      func() time.Time
+}
+
+// Open opens the audit log at path, continuing the chain of an existing file
+func Open(path string) (*Log, error) {
+	l := &Log{path: path, now: time.Now}
+
+	// Recover the position in the chain from the last entry
+	if existing, err := os.Open(path); err == nil {
+		defer existing.Close()
+		scanner := bufio.NewScanner(existing)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var entry Entry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				return nil, fmt.Errorf("failed to parse audit log %s: %w", path, err)
+			}
+			l.seq = entry.Seq
+			l.lastHash = entry.Hash
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// Append chains the entry to the log and writes it to disk
+func (l *Log) Append(entry Entry) (*Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = l.seq + 1
+	if entry.Time.IsZero() {
+		entry.Time = l.now().UTC()
+	}
+	entry.PrevHash = l.lastHash
+
+	hash, err := computeHash(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	l.seq = entry.Seq
+	l.lastHash = entry.Hash
+	return &entry, nil
+}
+
+// Export writes the lines of entries recorded in [from, to) to w unchanged,
+// so an export can be verified on its own. Zero times leave the range open.
+func (l *Log) Export(w io.Writer, from, to time.Time) (int, error) {
+	// Only the lines complete when the export starts are read, so appends
+	// can go on while a slow client downloads the export
+	l.mu.Lock()
+	info, err := l.file.Stat()
+	l.mu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	exported := 0
+	scanner := bufio.NewScanner(io.LimitReader(file, info.Size()))
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return exported, fmt.Errorf("failed to parse audit entry: %w", err)
+		}
+		if (!from.IsZero() && entry.Time.Before(from)) || (!to.IsZero() && !entry.Time.Before(to)) {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return exported, fmt.Errorf("failed to write audit export: %w", err)
+		}
+		exported++
+	}
+	if err := scanner.Err(); err != nil {
+		return exported, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return exported, nil
+}
+
+// Close closes the audit log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Scope is a permission granted to a caller
+type Scope string
+
+const (
+	ScopeExecute Scope = "execute" // Run code
+	ScopeReview  Scope = "review"  // Request reviews and hints
+	ScopeAdmin   Scope = "admin"   // Manage the service, implies every other scope
+)
+
+var (
+	// ErrMissingCredentials is returned when a request carries no credentials
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is returned when credentials cannot be verified
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity describes an authenticated caller
+type Identity struct {
+	KeyID    string  // ID of the API key used
+	UserID   string  // Learner or instructor, for platform tokens
+	Role     string  // Platform role, for platform tokens
+	TenantID string  // Tenant the caller belongs to
+	Scopes   []Scope // Permissions of the caller
+	Limits   *Limits // Resource limits of the caller, nil for defaults
+	Trusted  bool    // Code of the caller is trusted, e.g. instructors
+}
+
+// HasScope reports whether the identity was granted a scope
+func (i *Identity) HasScope(scope Scope) bool {
+	for _, s := range i.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTrusted reports whether the caller in the context runs trusted code.
+// Unauthenticated callers are untrusted.
+func IsTrusted(ctx context.Context) bool {
+	identity, ok := FromContext(ctx)
+	return ok && identity.Trusted
+}
+
+// Authenticator verifies a bearer token and returns the caller's identity
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// BearerToken extracts the token from an Authorization header value
+func BearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// Chain tries each authenticator in turn and returns the first identity found
+type Chain []Authenticator
+
+// Authenticate returns the identity from the first authenticator accepting the token
+func (c Chain) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(ctx, token)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored in the context, if any
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minJWKSRefresh limits how often an unknown key ID triggers a refetch
+const minJWKSRefresh = time.Minute
+
+// jsonWebKey is a single key of a JWK set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS provides RSA public keys from a JWK set loaded from a file or URL and cached for a TTL
+type JWKS struct {
+	source string // File path or http(s) URL
+	ttl    time.Duration
+	client *http.Client
+
+	mu         sync.Mutex
+	keys       map[string]*rsa.PublicKey
+	fetchedAt  time.Time
+	err        error         // Error of the last refresh
+	refreshing chan struct{} // Closed when the running refresh ends, nil if none runs
+}
+
+// NewJWKS creates a JWK set reading from a file path or http(s) URL
+func NewJWKS(source string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		source: source,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key with the given ID. An empty ID is accepted if the set holds a single key.
+func (j *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	keys, err := j.current(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// current returns the key set, refreshing it first if it is stale. Only one
+// refresh runs at a time and it runs without the lock held: while it does,
+// other callers get the cached keys, or wait for it if there are none yet.
+func (j *JWKS) current(ctx context.Context, kid string) (map[string]*rsa.PublicKey, error) {
+	j.mu.Lock()
+	keys := j.keys
+	stale := keys == nil || time.Since(j.fetchedAt) > j.ttl
+	if _, known := keys[kid]; !known && kid != "" && time.Since(j.fetchedAt) > minJWKSRefresh {
+		// The signing key may have been rotated since the last fetch
+		stale = true
+	}
+	if !stale {
+		j.mu.Unlock()
+		return keys, nil
+	}
+
+	if done := j.refreshing; done != nil {
+		j.mu.Unlock()
+		if keys != nil {
+			return keys, nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if j.keys == nil {
+			return nil, j.err
+		}
+		return j.keys, nil
+	}
+	done := make(chan struct{})
+	j.refreshing = done
+	j.mu.Unlock()
+
+	fetched, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.fetchedAt = time.Now()
+	j.err = err
+	if err == nil {
+		j.keys = fetched
+	}
+	j.refreshing = nil
+	close(done)
+
+	// Keep serving cached keys if a refresh fails
+	if j.keys == nil {
+		return nil, err
+	}
+	return j.keys, nil
+}
+
+// fetch loads and parses the key set
+func (j *JWKS) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	data, err := j.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// read returns the raw key set from the file or URL
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseRSAKey builds an RSA public key from its JWK modulus and exponent
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
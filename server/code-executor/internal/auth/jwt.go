@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures verification of platform-issued JWTs
+type JWTConfig struct {
+	Issuer        string                `json:"issuer"`
+	Audience      string                `json:"audience"`
+	HMACSecretEnv string                `json:"hmac_secret_env"` // Environment variable holding the HS256 secret
+	JWKSFile      string                `json:"jwks_file"`       // Local JWK set for RS256
+	JWKSURL       string                `json:"jwks_url"`        // Remote JWK set for RS256
+	JWKSCacheTTL  string                `json:"jwks_cache_ttl"`  // How long a fetched JWK set is cached, e.g. "1h"
+	RoleScopes    map[string][]Scope    `json:"role_scopes"`
+	RoleLimits    map[string]RoleLimits `json:"role_limits"`
+	TrustedRoles  []string              `json:"trusted_roles"` // Roles whose code is trusted
+}
+
+// LoadJWTConfig reads a JWT configuration from a JSON file
+func LoadJWTConfig(path string) (*JWTConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT config: %w", err)
+	}
+
+	var config JWTConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT config: %w", err)
+	}
+	return &config, nil
+}
+
+// defaultRoleScopes matches the roles issued by the platform
+var defaultRoleScopes = map[string][]Scope{
+	"STUDENT":    {ScopeExecute, ScopeReview},
+	"INSTRUCTOR": {ScopeExecute, ScopeReview},
+	"ADMIN":      {ScopeAdmin},
+}
+
+// defaultTrustedRoles are the platform roles whose code is trusted
+var defaultTrustedRoles = []string{"INSTRUCTOR", "ADMIN"}
+
+// clockSkew is the tolerance applied to expiry and not-before checks
+const clockSkew = 30 * time.Second
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims are the claims issued by the platform's auth service
+type jwtClaims struct {
+	UserID    string          `json:"userId"`
+	Role      string          `json:"role"`
+	Type      string          `json:"type"`
+	TenantID  string          `json:"tenantId"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"` // String or array of strings
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// JWTVerifier authenticates callers with JWTs signed by the platform
+type JWTVerifier struct {
+	issuer     string
+	audience   string
+	hmacSecret []byte
+	jwks       *JWKS
+	roleScopes map[string][]Scope
+	roleLimits map[string]Limits
+	trusted    map[string]bool
+}
+
+// NewJWTVerifier creates a verifier from the configuration
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		roleScopes: defaultRoleScopes,
+		roleLimits: make(map[string]Limits),
+		trusted:    make(map[string]bool),
+	}
+
+	if config.HMACSecretEnv != "" {
+		secret := os.Getenv(config.HMACSecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", config.HMACSecretEnv)
+		}
+		verifier.hmacSecret = []byte(secret)
+	}
+
+	ttl := time.Hour
+	if config.JWKSCacheTTL != "" {
+		parsed, err := time.ParseDuration(config.JWKSCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks_cache_ttl: %w", err)
+		}
+		ttl = parsed
+	}
+	switch {
+	case config.JWKSFile != "" && config.JWKSURL != "":
+		return nil, fmt.Errorf("jwks_file and jwks_url are mutually exclusive")
+	case config.JWKSFile != "":
+		verifier.jwks = NewJWKS(config.JWKSFile, ttl)
+	case config.JWKSURL != "":
+		verifier.jwks = NewJWKS(config.JWKSURL, ttl)
+	}
+
+	if verifier.hmacSecret == nil && verifier.jwks == nil {
+		return nil, fmt.Errorf("either hmac_secret_env or a JWKS source is required")
+	}
+
+	if config.RoleScopes != nil {
+		verifier.roleScopes = config.RoleScopes
+	}
+	for role, limits := range config.RoleLimits {
+		verifier.roleLimits[role] = limits.Limits()
+	}
+	trustedRoles := defaultTrustedRoles
+	if config.TrustedRoles != nil {
+		trustedRoles = config.TrustedRoles
+	}
+	for _, role := range trustedRoles {
+		verifier.trusted[role] = true
+	}
+
+	return verifier, nil
+}
+
+// Authenticate verifies the token signature and claims
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidCredentials)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidCredentials)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidCredentials)
+	}
+	if err := v.verifySignature(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidCredentials)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		UserID:   claims.UserID,
+		Role:     claims.Role,
+		TenantID: claims.TenantID,
+		Scopes:   v.roleScopes[claims.Role],
+		Trusted:  v.trusted[claims.Role],
+	}
+	if limits, ok := v.roleLimits[claims.Role]; ok {
+		identity.Limits = &limits
+	}
+	return identity, nil
+}
+
+// verifySignature checks the signature with the key matching the algorithm
+func (v *JWTVerifier) verifySignature(ctx context.Context, header jwtHeader, signingInput string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if v.hmacSecret == nil {
+			return fmt.Errorf("%w: HS256 tokens are not accepted", ErrInvalidCredentials)
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidCredentials)
+		}
+		return nil
+	case "RS256":
+		if v.jwks == nil {
+			return fmt.Errorf("%w: RS256 tokens are not accepted", ErrInvalidCredentials)
+		}
+		key, err := v.jwks.Key(ctx, header.Kid)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidCredentials)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidCredentials, header.Alg)
+	}
+}
+
+// validateClaims checks expiry, issuer, audience and token type
+func (v *JWTVerifier) validateClaims(claims jwtClaims) error {
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+	if now.After(unixTime(*claims.ExpiresAt).Add(clockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidCredentials)
+	}
+	if claims.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*claims.NotBefore)) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidCredentials)
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidCredentials)
+	}
+	if v.audience != "" && !hasAudience(claims.Audience, v.audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidCredentials)
+	}
+	// Refresh tokens must not be usable as access tokens
+	if claims.Type != "" && claims.Type != "access" {
+		return fmt.Errorf("%w: not an access token", ErrInvalidCredentials)
+	}
+	if claims.UserID == "" {
+		return fmt.Errorf("%w: token has no user", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+// hasAudience reports whether the aud claim contains the audience
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		for _, aud := range multiple {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// unixTime converts a NumericDate claim to a time
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
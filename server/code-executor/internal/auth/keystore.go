@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// APIKey is an entry of the key file. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID       string  `json:"id"`
+	Hash     string  `json:"hash"` // Hex-encoded SHA-256 of the key
+	Scopes   []Scope `json:"scopes"`
+	TenantID string  `json:"tenant_id"`
+	Trusted  bool    `json:"trusted"` // Code submitted with the key is trusted
+}
+
+// keyFile is the on-disk format of the key store
+type keyFile struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// KeyStore authenticates callers against a set of hashed API keys
+type KeyStore struct {
+	keys []APIKey
+}
+
+// LoadKeyStore reads hashed API keys from a JSON file
+func LoadKeyStore(path string) (*KeyStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	for i, key := range file.Keys {
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("key %q: hash must be a hex-encoded SHA-256 digest", key.ID)
+		}
+		for _, scope := range key.Scopes {
+			switch scope {
+			case ScopeExecute, ScopeReview, ScopeAdmin:
+			default:
+				return nil, fmt.Errorf("key %q: unknown scope %q", key.ID, scope)
+			}
+		}
+		file.Keys[i].Hash = strings.ToLower(key.Hash)
+	}
+
+	return &KeyStore{keys: file.Keys}, nil
+}
+
+// HashKey returns the hex-encoded SHA-256 hash of an API key as stored in the key file
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// Authenticate looks up the API key. Every stored hash is compared in
+// constant time, so the time taken does not reveal which keys exist.
+func (s *KeyStore) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	hash := []byte(HashKey(token))
+	var match *APIKey
+	for i := range s.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(s.keys[i].Hash)) == 1 {
+			match = &s.keys[i]
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{
+		KeyID:    match.ID,
+		TenantID: match.TenantID,
+		Scopes:   match.Scopes,
+		Trusted:  match.Trusted,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Limits are the highest resource limits a caller may request
+type Limits struct {
+	MaxTimeout time.Duration
+	MaxMemory  int64 // in bytes
+	MaxCPU     float64
+}
+
+// DefaultLimits apply to callers without role-specific limits
+var DefaultLimits = Limits{
+	MaxTimeout: 120 * time.Second,
+	MaxMemory:  1024 * 1024 * 1024,
+	MaxCPU:     1.0,
+}
+
+// Limits of executions that do not request them
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultMemory  = 128 * 1024 * 1024
+	DefaultCPU     = 0.5
+)
+
+// Apply returns the requested limits, defaults for unset ones, capped by the caller's limits
+func (l Limits) Apply(timeout time.Duration, memory int64, cpu float64) (time.Duration, int64, float64) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	if memory == 0 {
+		memory = DefaultMemory
+	}
+	if cpu == 0 {
+		cpu = DefaultCPU
+	}
+	return min(timeout, l.MaxTimeout), min(memory, l.MaxMemory), min(cpu, l.MaxCPU)
+}
+
+// RoleLimits is the configuration format of the limits of a role
+type RoleLimits struct {
+	MaxTimeoutSeconds int     `json:"max_timeout_seconds"`
+	MaxMemoryMB       int64   `json:"max_memory_mb"`
+	MaxCPU            float64 `json:"max_cpu"`
+}
+
+// Limits converts the configuration into limits, using defaults for unset values
+func (r RoleLimits) Limits() Limits {
+	limits := DefaultLimits
+	if r.MaxTimeoutSeconds > 0 {
+		limits.MaxTimeout = time.Duration(r.MaxTimeoutSeconds) * time.Second
+	}
+	if r.MaxMemoryMB > 0 {
+		limits.MaxMemory = r.MaxMemoryMB * 1024 * 1024
+	}
+	if r.MaxCPU > 0 {
+		limits.MaxCPU = r.MaxCPU
+	}
+	return limits
+}
+
+// LimitsFor returns the resource limits of the caller in the context
+func LimitsFor(ctx context.Context) Limits {
+	if identity, ok := FromContext(ctx); ok && identity.Limits != nil {
+		return *identity.Limits
+	}
+	return DefaultLimits
+}
@@ -0,0 +1,164 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-executor/internal/docker"
+)
+
+// Limits of benchmarks
+const (
+	DefaultRuns   = 10
+	MinRuns       = 3
+	MaxRuns       = 50
+	DefaultWarmup = 1
+	MaxWarmup     = 10
+)
+
+// maxReferenceError is how much of a failed reference's stderr is reported
+const maxReferenceError = 1024
+
+// Errors of benchmarks
+var (
+	ErrInvalidRequest = errors.New("invalid benchmark")
+	ErrReference      = errors.New("reference solution failed")
+)
+
+// Runner runs the program of an execution repeatedly in one container, e.g.
+// the Benchmark method of a docker.Manager
+type Runner func(ctx context.Context, config docker.ExecutionConfig, runs int) ([]*docker.ExecutionResult, error)
+
+// Request describes a benchmark of a submission
+type Request struct {
+	Config    docker.ExecutionConfig // Submission, input and limits of every run
+	Runs      int                    // Measured runs, DefaultRuns if zero
+	Warmup    *int                   // Runs before the measured ones, DefaultWarmup if nil and none if zero
+	Reference string                 // Code of a reference solution in the same language, none if empty
+}
+
+// Measurements is the usage of a program over the measured runs
+type Measurements struct {
+	Runs   int     // Measured runs completed
+	CPU    Summary // CPU time in microseconds
+	Memory Summary // Peak resident memory in bytes
+	Error  string  // Why the program failed, which ends its benchmark
+}
+
+// Result is the outcome of a benchmark
+type Result struct {
+	Submission Measurements
+	Reference  *Measurements // Nil without a reference solution
+	Score      float64       // Median CPU time of the reference relative to the submission's, above 1 if the submission is faster
+	Noisy      bool          // The CPU time of the submission or the reference is noisy
+}
+
+// Validate checks the runs, warmup and program of a request
+func Validate(req Request) error {
+	if req.Runs != 0 && (req.Runs < MinRuns || req.Runs > MaxRuns) {
+		return fmt.Errorf("%w: runs must be between %d and %d", ErrInvalidRequest, MinRuns, MaxRuns)
+	}
+	if req.Warmup != nil && (*req.Warmup < 0 || *req.Warmup > MaxWarmup) {
+		return fmt.Errorf("%w: warmup must be between 0 and %d runs", ErrInvalidRequest, MaxWarmup)
+	}
+	if req.Config.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidRequest)
+	}
+	if _, err := docker.ImageFor(req.Config.Language); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if err := docker.ValidateArgs(req.Config.Args, req.Config.Env); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// Run benchmarks the submission and, if given, the reference solution on the
+// same input and limits. Each runs its warmup runs and then its measured runs
+// in one container. A failing submission ends its benchmark and is reported
+// with the runs before the failure; a failing reference is an error.
+func Run(ctx context.Context, run Runner, req Request) (*Result, error) {
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	if req.Runs == 0 {
+		req.Runs = DefaultRuns
+	}
+	if req.Warmup == nil {
+		warmup := DefaultWarmup
+		req.Warmup = &warmup
+	}
+
+	submission, _, err := measure(ctx, run, req, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Submission: *submission, Noisy: submission.CPU.Noisy}
+	if req.Reference == "" || submission.Error != "" {
+		return result, nil
+	}
+
+	config := req.Config
+	config.Code = req.Reference
+	reference, failed, err := measure(ctx, run, req, config)
+	if err != nil {
+		return nil, err
+	}
+	if reference.Error != "" {
+		stderr := strings.TrimSpace(failed.Stderr)
+		if len(stderr) > maxReferenceError {
+			stderr = stderr[:maxReferenceError]
+		}
+		return nil, fmt.Errorf("%w: %s: %s", ErrReference, reference.Error, stderr)
+	}
+	result.Reference = reference
+	result.Noisy = result.Noisy || reference.CPU.Noisy
+	if submission.CPU.Median > 0 {
+		result.Score = reference.CPU.Median / submission.CPU.Median
+	}
+	return result, nil
+}
+
+// measure runs a program and summarizes its measured runs. It also returns
+// the failed run, nil if all runs succeeded.
+func measure(ctx context.Context, run Runner, req Request, config docker.ExecutionConfig) (*Measurements, *docker.ExecutionResult, error) {
+	results, err := run(ctx, config, *req.Warmup+req.Runs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	measurements := &Measurements{}
+	var failed *docker.ExecutionResult
+	var cpu, memory []float64
+	for i, result := range results {
+		if failure := failure(result); failure != "" {
+			measurements.Error = failure
+			failed = result
+			break
+		}
+		if i < *req.Warmup {
+			continue
+		}
+		cpu = append(cpu, float64(result.CPUTime().Microseconds()))
+		memory = append(memory, float64(result.MemoryUsed))
+	}
+	measurements.Runs = len(cpu)
+	measurements.CPU = Summarize(cpu)
+	measurements.Memory = Summarize(memory)
+	return measurements, failed, nil
+}
+
+// failure describes why a run failed, empty if it succeeded
+func failure(run *docker.ExecutionResult) string {
+	switch {
+	case run.Timeout:
+		return "timeout"
+	case run.CPUTimeExceeded:
+		return "cpu time limit exceeded"
+	case run.ExitCode != 0:
+		return fmt.Sprintf("exit code %d", run.ExitCode)
+	}
+	return ""
+}
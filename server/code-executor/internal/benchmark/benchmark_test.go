@@ -0,0 +1,166 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"code-executor/internal/docker"
+)
+
+// fakeRunner returns the CPU times of its programs, the first being a slow
+// cold run, and records how many runs were requested
+type fakeRunner struct {
+	cpu       map[string][]time.Duration // CPU time of every run by code, zero fails with exit code 1
+	requested []int
+}
+
+func (r *fakeRunner) run(ctx context.Context, config docker.ExecutionConfig, runs int) ([]*docker.ExecutionResult, error) {
+	r.requested = append(r.requested, runs)
+	var results []*docker.ExecutionResult
+	for _, cpu := range r.cpu[config.Code][:runs] {
+		if cpu == 0 {
+			results = append(results, &docker.ExecutionResult{ExitCode: 1, Stderr: "Traceback: ZeroDivisionError"})
+			break
+		}
+		results = append(results, &docker.ExecutionResult{CPUUserTime: cpu, MemoryUsed: 10 << 20})
+	}
+	return results, nil
+}
+
+// runs returns CPU times in milliseconds of a cold run followed by warm ones
+func runs(cold int, warm ...int) []time.Duration {
+	durations := []time.Duration{time.Duration(cold) * time.Millisecond}
+	for _, ms := range warm {
+		durations = append(durations, time.Duration(ms)*time.Millisecond)
+	}
+	return durations
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestRunWarmup(t *testing.T) {
+	tests := []struct {
+		name          string
+		warmup        *int
+		wantRequested int
+		wantNoisy     bool
+	}{
+		{name: "default drops the cold run", warmup: nil, wantRequested: 4},
+		{name: "no warmup keeps the cold run", warmup: intPtr(0), wantRequested: 3, wantNoisy: true},
+		{name: "two warmup runs", warmup: intPtr(2), wantRequested: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{cpu: map[string][]time.Duration{"submission": runs(50, 10, 10, 11, 10, 10)}}
+			result, err := Run(context.Background(), runner.run, Request{
+				Config: docker.ExecutionConfig{Language: "python", Code: "submission"},
+				Runs:   3,
+				Warmup: tt.warmup,
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(runner.requested) != 1 || runner.requested[0] != tt.wantRequested {
+				t.Errorf("requested %v runs, want %d", runner.requested, tt.wantRequested)
+			}
+			if result.Submission.Runs != 3 {
+				t.Errorf("%d measured runs, want 3", result.Submission.Runs)
+			}
+			if tt.warmup != nil && *tt.warmup == 0 {
+				// The cold run is measured
+				if result.Submission.CPU.P90 < 40_000 {
+					t.Errorf("p90 %v, want the cold run", result.Submission.CPU.P90)
+				}
+			} else if result.Submission.CPU.P90 > 11_000 {
+				t.Errorf("p90 %v, want only warm runs", result.Submission.CPU.P90)
+			}
+			if result.Submission.CPU.Min != 10_000 || result.Noisy != tt.wantNoisy {
+				t.Errorf("cpu %+v, noisy %v", result.Submission.CPU, result.Noisy)
+			}
+		})
+	}
+}
+
+func TestRunReference(t *testing.T) {
+	runner := &fakeRunner{cpu: map[string][]time.Duration{
+		"submission": runs(50, 10, 10, 10),
+		"reference":  runs(90, 20, 20, 20),
+	}}
+	result, err := Run(context.Background(), runner.run, Request{
+		Config:    docker.ExecutionConfig{Language: "python", Code: "submission"},
+		Runs:      3,
+		Reference: "reference",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Reference == nil || result.Reference.CPU.Median != 20_000 {
+		t.Fatalf("reference %+v, want a median of 20ms", result.Reference)
+	}
+	if result.Score != 2 {
+		t.Errorf("score %v, want 2", result.Score)
+	}
+}
+
+func TestRunSubmissionFailsPartway(t *testing.T) {
+	runner := &fakeRunner{cpu: map[string][]time.Duration{
+		"submission": runs(50, 10, 12, 0, 10),
+		"reference":  runs(90, 20, 20, 20, 20),
+	}}
+	result, err := Run(context.Background(), runner.run, Request{
+		Config:    docker.ExecutionConfig{Language: "python", Code: "submission"},
+		Runs:      4,
+		Reference: "reference",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Submission.Error != "exit code 1" || result.Submission.Runs != 2 {
+		t.Errorf("submission %+v, want 2 runs before exit code 1", result.Submission)
+	}
+	if result.Submission.CPU.Median != 11_000 {
+		t.Errorf("median %v, want the runs before the failure", result.Submission.CPU.Median)
+	}
+	if result.Reference != nil || len(runner.requested) != 1 {
+		t.Errorf("reference ran after the submission failed")
+	}
+}
+
+func TestRunReferenceFails(t *testing.T) {
+	runner := &fakeRunner{cpu: map[string][]time.Duration{
+		"submission": runs(50, 10, 10, 10),
+		"reference":  runs(90, 0, 20, 20),
+	}}
+	_, err := Run(context.Background(), runner.run, Request{
+		Config:    docker.ExecutionConfig{Language: "python", Code: "submission"},
+		Runs:      3,
+		Reference: "reference",
+	})
+	if !errors.Is(err, ErrReference) || !strings.Contains(err.Error(), "ZeroDivisionError") {
+		t.Errorf("Run() error = %v, want %v with the reference's stderr", err, ErrReference)
+	}
+}
+
+func TestValidateWarmup(t *testing.T) {
+	tests := []struct {
+		warmup  *int
+		wantErr bool
+	}{
+		{warmup: nil},
+		{warmup: intPtr(0)},
+		{warmup: intPtr(MaxWarmup)},
+		{warmup: intPtr(-1), wantErr: true},
+		{warmup: intPtr(MaxWarmup + 1), wantErr: true},
+	}
+	for _, tt := range tests {
+		err := Validate(Request{Config: docker.ExecutionConfig{Language: "python", Code: "print(1)"}, Warmup: tt.warmup})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(warmup %v) error = %v, wantErr %v", tt.warmup, err, tt.wantErr)
+		}
+	}
+}
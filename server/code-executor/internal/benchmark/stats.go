@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"math"
+	"sort"
+)
+
+// NoisyDeviation is the relative standard deviation above which measurements
+// are flagged as noisy
+const NoisyDeviation = 0.1
+
+// Summary describes the distribution of a measurement over the runs
+type Summary struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	StdDev float64 `json:"stddev"` // Sample standard deviation, zero for a single run
+	Noisy  bool    `json:"noisy"`  // The standard deviation exceeds NoisyDeviation of the mean
+}
+
+// Summarize returns the summary of measurements, the zero summary if there are none
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var mean float64
+	for _, value := range sorted {
+		mean += value / float64(len(sorted))
+	}
+	var deviation float64
+	if len(sorted) > 1 {
+		var squares float64
+		for _, value := range sorted {
+			squares += (value - mean) * (value - mean)
+		}
+		deviation = math.Sqrt(squares / float64(len(sorted)-1))
+	}
+
+	return Summary{
+		Min:    sorted[0],
+		Median: percentile(sorted, 0.5),
+		P90:    percentile(sorted, 0.9),
+		StdDev: deviation,
+		Noisy:  mean > 0 && deviation/mean > NoisyDeviation,
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 == len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,80 @@
+package benchmark
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Summary
+	}{
+		{name: "no runs", values: nil, want: Summary{}},
+		{name: "single run", values: []float64{5}, want: Summary{Min: 5, Median: 5, P90: 5}},
+		{
+			name:   "even count interpolates",
+			values: []float64{7, 2, 10, 4, 1, 9, 3, 6, 8, 5},
+			want:   Summary{Min: 1, Median: 5.5, P90: 9.1, StdDev: math.Sqrt(55.0 / 6), Noisy: true},
+		},
+		{
+			name:   "steady runs",
+			values: []float64{100, 102, 98, 101, 99},
+			want:   Summary{Min: 98, Median: 100, P90: 101.6, StdDev: math.Sqrt(2.5)},
+		},
+		{
+			name:   "just above the noise threshold",
+			values: []float64{88, 100, 112},
+			want:   Summary{Min: 88, Median: 100, P90: 109.6, StdDev: 12, Noisy: true},
+		},
+		{name: "all zero", values: []float64{0, 0, 0}, want: Summary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.values)
+			for _, field := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"min", got.Min, tt.want.Min},
+				{"median", got.Median, tt.want.Median},
+				{"p90", got.P90, tt.want.P90},
+				{"stddev", got.StdDev, tt.want.StdDev},
+			} {
+				if math.Abs(field.got-field.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+				}
+			}
+			if got.Noisy != tt.want.Noisy {
+				t.Errorf("noisy = %v, want %v", got.Noisy, tt.want.Noisy)
+			}
+		})
+	}
+}
+
+func TestSummarizeKeepsValues(t *testing.T) {
+	values := []float64{3, 1, 2}
+	Summarize(values)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("Summarize() reordered its input to %v", values)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{p: 0, want: 10},
+		{p: 0.5, want: 25},
+		{p: 0.9, want: 37},
+		{p: 1, want: 40},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package blob
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ErrNotFound is returned for blobs that do not exist or have expired
+var ErrNotFound = errors.New("blob not found")
+
+// idPattern matches the IDs handed out by the store
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Store keeps blobs as files in a local directory for a limited time
+type Store struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewStore creates a store in dir keeping blobs for ttl
+func NewStore(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &Store{dir: dir, ttl: ttl}, nil
+}
+
+// Put stores data and returns its ID. IDs are random, so they can be used in
+// download URLs without revealing other blobs.
+func (s *Store) Put(data []byte) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate blob ID: %w", err)
+	}
+	id := hex.EncodeToString(raw)
+
+	// Write to a temporary file first, so readers never see a partial blob
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, id)); err != nil {
+		return "", fmt.Errorf("failed to store blob: %w", err)
+	}
+	return id, nil
+}
+
+// Open returns the blob with the ID
+func (s *Store) Open(id string) (*os.File, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	if s.expired(info, time.Now()) {
+		file.Close()
+		return nil, ErrNotFound
+	}
+	return file, nil
+}
+
+// Get returns the content of the blob with the ID
+func (s *Store) Get(id string) ([]byte, error) {
+	file, err := s.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	return data, nil
+}
+
+// Sweep removes expired blobs and returns how many were removed
+func (s *Store) Sweep(now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !s.expired(info, now) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// expired reports whether a blob is older than the TTL
+func (s *Store) expired(info os.FileInfo, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(info.ModTime()) > s.ttl
+}
@@ -0,0 +1,219 @@
+package buildcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// keyPattern matches the keys returned by Key, which are also the file names
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Stats contains build cache metrics
+type Stats struct {
+	Entries   int     `json:"entries"`
+	Bytes     int64   `json:"bytes"`
+	MaxBytes  int64   `json:"max_bytes"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Stores    uint64  `json:"stores"`
+	Evictions uint64  `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// cacheEntry is a build output stored on disk
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// Cache stores build outputs as files in a local directory, named by a hash
+// of everything the build depends on. The total size is bounded, the least
+// recently used outputs are evicted first.
+type Cache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	size     int64
+	order    *list.List // Front is most recently used
+	entries  map[string]*list.Element
+	stats    Stats
+}
+
+// Key returns the cache key of a build from its inputs, such as the language,
+// the toolchain digest, the source and the compile flags
+func Key(inputs ...string) string {
+	hash := sha256.New()
+	for _, input := range inputs {
+		hash.Write([]byte(input))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Open opens the cache in dir holding at most maxBytes of outputs. Outputs
+// stored by earlier processes are kept, ordered by when they were last used.
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create build cache directory: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list build cache: %w", err)
+	}
+
+	type stored struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var existing []stored
+	for _, file := range files {
+		if !keyPattern.MatchString(file.Name()) {
+			continue // Leftover temporary files are overwritten or ignored
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		existing = append(existing, stored{key: file.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(existing, func(i, j int) bool { return existing[i].modTime.After(existing[j].modTime) })
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	for _, entry := range existing {
+		c.entries[entry.key] = c.order.PushBack(&cacheEntry{key: entry.key, size: entry.size})
+		c.size += entry.size
+	}
+	c.evict()
+	return c, nil
+}
+
+// Get returns the build output stored under the key
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	path := filepath.Join(c.dir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.remove(element)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	// The modification time records the use, so the order survives restarts
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	c.order.MoveToFront(element)
+	c.stats.Hits++
+	return data, true
+}
+
+// Put stores a build output under the key, evicting the least recently used
+// outputs until the cache fits its size. Outputs larger than the cache are not stored.
+func (c *Cache) Put(key string, data []byte) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid build cache key %q", key)
+	}
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	// Write to a temporary file first, so readers never see a partial output
+	tmp, err := os.CreateTemp(c.dir, ".store-*")
+	if err != nil {
+		return fmt.Errorf("failed to create build cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write build cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write build cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, key)); err != nil {
+		return fmt.Errorf("failed to store build cache entry: %w", err)
+	}
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		c.size += size - entry.size
+		entry.size = size
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, size: size})
+		c.size += size
+	}
+	c.stats.Stores++
+	c.evict()
+	return nil
+}
+
+// Clear removes all outputs and returns how many were removed
+func (c *Cache) Clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := c.order.Len()
+	for c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+	return removed
+}
+
+// Stats returns a snapshot of the cache metrics
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.size
+	stats.MaxBytes = c.maxBytes
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// evict removes the least recently used outputs until the cache fits its
+// size. The caller must hold the lock.
+func (c *Cache) evict() {
+	for c.size > c.maxBytes && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove deletes an output from the cache and disk. The caller must hold the lock.
+func (c *Cache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	os.Remove(filepath.Join(c.dir, entry.key))
+}
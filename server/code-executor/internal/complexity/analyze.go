@@ -0,0 +1,188 @@
+package complexity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code-executor/internal/docker"
+)
+
+// Limits of complexity analyses
+const (
+	MinSizes      = 3
+	MaxSizes      = 20
+	MaxRepeats    = 5
+	MaxInputBytes = 16 * 1024 * 1024 // Largest input a generator may print
+)
+
+// maxGeneratorError is how much of a failed generator's stderr is reported
+const maxGeneratorError = 1024
+
+// Errors of complexity analyses
+var (
+	ErrInvalidRequest = errors.New("invalid complexity analysis")
+	ErrGenerator      = errors.New("input generator failed")
+)
+
+// Executor runs an execution, e.g. the Execute method of a docker.Manager
+type Executor func(ctx context.Context, config docker.ExecutionConfig) (*docker.ExecutionResult, error)
+
+// Program is code in a language, optionally with a version like python@3.12
+type Program struct {
+	Language string
+	Code     string
+}
+
+// Request describes a complexity analysis of a submission
+type Request struct {
+	Submission Program
+	Generator  Program                // Prints the input of the size given as its first argument
+	Sizes      []int                  // Input sizes in increasing order
+	Repeats    int                    // Runs per size, the fastest counts; 1 if zero
+	Limits     docker.ExecutionConfig // Limits and trust of every run
+}
+
+// Measurement is the usage of the submission at an input size
+type Measurement struct {
+	Size       int
+	InputBytes int64
+	CPUTime    time.Duration // CPU time of the fastest run
+	Memory     int64         // Peak memory of the fastest run in bytes
+	Error      string        // Why the submission failed at this size, which ends the analysis
+}
+
+// Result is the outcome of a complexity analysis
+type Result struct {
+	Measurements []Measurement
+	CPU          *Fit // Nil if the submission failed before MinSizes sizes
+	Memory       *Fit
+}
+
+// Validate checks the sizes, repeats and programs of a request
+func Validate(req Request) error {
+	if len(req.Sizes) < MinSizes || len(req.Sizes) > MaxSizes {
+		return fmt.Errorf("%w: between %d and %d sizes are required", ErrInvalidRequest, MinSizes, MaxSizes)
+	}
+	for i, size := range req.Sizes {
+		if size <= 0 {
+			return fmt.Errorf("%w: sizes must be positive", ErrInvalidRequest)
+		}
+		if i > 0 && size <= req.Sizes[i-1] {
+			return fmt.Errorf("%w: sizes must be increasing", ErrInvalidRequest)
+		}
+	}
+	if req.Repeats < 0 || req.Repeats > MaxRepeats {
+		return fmt.Errorf("%w: repeats must be between 1 and %d", ErrInvalidRequest, MaxRepeats)
+	}
+	for _, program := range []struct {
+		name string
+		Program
+	}{{"submission", req.Submission}, {"generator", req.Generator}} {
+		if program.Code == "" {
+			return fmt.Errorf("%w: %s code is required", ErrInvalidRequest, program.name)
+		}
+		if _, err := docker.ImageFor(program.Language); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidRequest, program.name, err)
+		}
+	}
+	return nil
+}
+
+// Analyze runs the submission on generated inputs of increasing size and fits
+// its CPU time and peak memory to complexity classes. A size the submission
+// fails at, e.g. by timing out, ends the analysis; the sizes before it are fitted.
+func Analyze(ctx context.Context, execute Executor, req Request) (*Result, error) {
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	repeats := max(req.Repeats, 1)
+
+	result := &Result{}
+	for _, size := range req.Sizes {
+		input, err := generate(ctx, execute, req, size)
+		if err != nil {
+			return nil, err
+		}
+
+		measurement := Measurement{Size: size, InputBytes: int64(len(input))}
+		for i := 0; i < repeats; i++ {
+			config := req.Limits
+			config.Language = req.Submission.Language
+			config.Code = req.Submission.Code
+			config.Input = input
+			run, err := execute(ctx, config)
+			if err != nil {
+				return nil, err
+			}
+			if failure := failure(run); failure != "" {
+				measurement.Error = failure
+				break
+			}
+			if i == 0 || run.CPUTime() < measurement.CPUTime {
+				measurement.CPUTime = run.CPUTime()
+				measurement.Memory = run.MemoryUsed
+			}
+		}
+		result.Measurements = append(result.Measurements, measurement)
+		if measurement.Error != "" {
+			break
+		}
+	}
+
+	var sizes []int
+	var cpu, memory []float64
+	for _, measurement := range result.Measurements {
+		if measurement.Error != "" {
+			continue
+		}
+		sizes = append(sizes, measurement.Size)
+		cpu = append(cpu, measurement.CPUTime.Seconds())
+		memory = append(memory, float64(measurement.Memory))
+	}
+	result.CPU = FitClasses(sizes, cpu)
+	result.Memory = FitClasses(sizes, memory)
+	return result, nil
+}
+
+// generate runs the generator for a size and returns the input it printed
+func generate(ctx context.Context, execute Executor, req Request, size int) (string, error) {
+	config := req.Limits
+	config.Language = req.Generator.Language
+	config.Code = req.Generator.Code
+	config.Args = []string{strconv.Itoa(size)}
+	config.StdoutLimit = MaxInputBytes
+	config.CPUTimeLimit = 0 // Only the submission is judged
+
+	run, err := execute(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	if run.StdoutTruncated {
+		return "", fmt.Errorf("%w: input of size %d exceeds %d bytes", ErrGenerator, size, MaxInputBytes)
+	}
+	if failure := failure(run); failure != "" {
+		stderr := strings.TrimSpace(run.Stderr)
+		if len(stderr) > maxGeneratorError {
+			stderr = stderr[:maxGeneratorError]
+		}
+		return "", fmt.Errorf("%w at size %d: %s: %s", ErrGenerator, size, failure, stderr)
+	}
+	return run.Stdout, nil
+}
+
+// failure describes why a run failed, empty if it succeeded
+func failure(run *docker.ExecutionResult) string {
+	switch {
+	case run.Timeout:
+		return "timeout"
+	case run.CPUTimeExceeded:
+		return "cpu time limit exceeded"
+	case run.ExitCode != 0:
+		return fmt.Sprintf("exit code %d", run.ExitCode)
+	}
+	return ""
+}
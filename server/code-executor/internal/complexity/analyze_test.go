@@ -0,0 +1,135 @@
+package complexity
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"code-executor/internal/docker"
+)
+
+// fakeExecutor generates inputs of n bytes and runs the submission in linear
+// CPU time, failing as configured
+type fakeExecutor struct {
+	failAt    int  // Size the submission times out at, none if zero
+	failGen   bool // The generator exits with an error
+	submitted []int
+}
+
+func (e *fakeExecutor) execute(ctx context.Context, config docker.ExecutionConfig) (*docker.ExecutionResult, error) {
+	if config.Code == "generator" {
+		if e.failGen {
+			return &docker.ExecutionResult{ExitCode: 1, Stderr: "IndexError: list index out of range"}, nil
+		}
+		size, err := strconv.Atoi(config.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &docker.ExecutionResult{Stdout: strings.Repeat("x", size)}, nil
+	}
+
+	size := len(config.Input)
+	e.submitted = append(e.submitted, size)
+	if size == e.failAt {
+		return &docker.ExecutionResult{Timeout: true, ExitCode: 137}, nil
+	}
+	return &docker.ExecutionResult{
+		CPUUserTime: 50*time.Millisecond + time.Duration(size)*time.Microsecond,
+		MemoryUsed:  20<<20 + int64(size)*64,
+	}, nil
+}
+
+func analyzeRequest() Request {
+	return Request{
+		Submission: Program{Language: "python", Code: "submission"},
+		Generator:  Program{Language: "python", Code: "generator"},
+		Sizes:      []int{1000, 2000, 4000, 8000, 16000, 32000},
+		Repeats:    2,
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	executor := &fakeExecutor{}
+	result, err := Analyze(context.Background(), executor.execute, analyzeRequest())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if len(result.Measurements) != 6 || len(executor.submitted) != 12 {
+		t.Fatalf("%d measurements from %d runs, want 6 from 12", len(result.Measurements), len(executor.submitted))
+	}
+	if m := result.Measurements[2]; m.Size != 4000 || m.InputBytes != 4000 || m.CPUTime != 54*time.Millisecond {
+		t.Errorf("measurement %+v", m)
+	}
+	if result.CPU == nil || result.CPU.Class != Linear {
+		t.Errorf("CPU fit %+v, want %s", result.CPU, Linear)
+	}
+	if result.Memory == nil || result.Memory.Class != Linear {
+		t.Errorf("memory fit %+v, want %s", result.Memory, Linear)
+	}
+}
+
+func TestAnalyzeSubmissionFailsPartway(t *testing.T) {
+	executor := &fakeExecutor{failAt: 16000}
+	result, err := Analyze(context.Background(), executor.execute, analyzeRequest())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	// The failing size ends the analysis without repeating it
+	if len(result.Measurements) != 5 {
+		t.Fatalf("%d measurements, want 5", len(result.Measurements))
+	}
+	if n := len(executor.submitted); n != 9 || executor.submitted[n-1] != 16000 {
+		t.Errorf("submission ran at %v, want twice per size up to one run at 16000", executor.submitted)
+	}
+	last := result.Measurements[4]
+	if last.Size != 16000 || last.Error != "timeout" {
+		t.Errorf("last measurement %+v, want a timeout at 16000", last)
+	}
+	for _, m := range result.Measurements[:4] {
+		if m.Error != "" {
+			t.Errorf("measurement at %d failed: %s", m.Size, m.Error)
+		}
+	}
+	// The four sizes before the failure are fitted
+	if result.CPU == nil || result.CPU.Class != Linear {
+		t.Errorf("CPU fit %+v, want %s", result.CPU, Linear)
+	}
+}
+
+func TestAnalyzeFailsBeforeMinSizes(t *testing.T) {
+	executor := &fakeExecutor{failAt: 4000}
+	result, err := Analyze(context.Background(), executor.execute, analyzeRequest())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(result.Measurements) != 3 || result.CPU != nil || result.Memory != nil {
+		t.Errorf("result %+v, want 3 measurements without fits", result)
+	}
+}
+
+func TestAnalyzeGeneratorFails(t *testing.T) {
+	executor := &fakeExecutor{failGen: true}
+	_, err := Analyze(context.Background(), executor.execute, analyzeRequest())
+	if !errors.Is(err, ErrGenerator) {
+		t.Fatalf("Analyze() error = %v, want %v", err, ErrGenerator)
+	}
+	if !strings.Contains(err.Error(), "IndexError") {
+		t.Errorf("error %q does not contain the generator's stderr", err)
+	}
+	if len(executor.submitted) != 0 {
+		t.Errorf("submission ran %d times after the generator failed", len(executor.submitted))
+	}
+}
+
+func TestAnalyzeInvalidRequest(t *testing.T) {
+	req := analyzeRequest()
+	req.Sizes = []int{1000, 500, 2000}
+	if _, err := Analyze(context.Background(), (&fakeExecutor{}).execute, req); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Analyze() error = %v, want %v", err, ErrInvalidRequest)
+	}
+}
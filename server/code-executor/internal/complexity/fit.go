@@ -0,0 +1,128 @@
+package complexity
+
+import (
+	"math"
+	"sort"
+)
+
+// Complexity classes measurements are fitted against
+const (
+	Constant     = "O(1)"
+	Logarithmic  = "O(log n)"
+	Linear       = "O(n)"
+	Linearithmic = "O(n log n)"
+	Quadratic    = "O(n^2)"
+	Exponential  = "O(2^n)"
+)
+
+// class is a complexity class with its growth at n, scaled so the growth at
+// the largest size is 1 and exponential growth cannot overflow
+type class struct {
+	name   string
+	growth func(n, largest float64) float64 // Nil for constant
+}
+
+// classes are the classes fitted, simplest first
+var classes = []class{
+	{name: Constant},
+	{name: Logarithmic, growth: func(n, largest float64) float64 { return math.Log(n) / math.Log(largest) }},
+	{name: Linear, growth: func(n, largest float64) float64 { return n / largest }},
+	{name: Linearithmic, growth: func(n, largest float64) float64 { return n * math.Log(n) / (largest * math.Log(largest)) }},
+	{name: Quadratic, growth: func(n, largest float64) float64 { return (n / largest) * (n / largest) }},
+	{name: Exponential, growth: func(n, largest float64) float64 { return math.Exp2(n - largest) }},
+}
+
+// Fit is the complexity class that best explains a series of measurements
+type Fit struct {
+	Class      string
+	Confidence float64     // Probability of the class among the candidates
+	Candidates []Candidate // Classes that explain the measurements, most likely first
+}
+
+// Candidate is a class that explains the measurements with its probability
+type Candidate struct {
+	Class       string  `json:"class"`
+	Probability float64 `json:"probability"`
+}
+
+// FitClasses fits measurements at increasing sizes to every class as
+// a*growth(n)+b, so fixed costs like interpreter startup do not count.
+// Classes are compared by their Bayesian information criterion, which
+// penalizes the slope of the growing classes, and weighted into
+// probabilities. Classes whose best slope is not positive are no more than
+// constant and left out. It returns nil for fewer than MinSizes measurements.
+func FitClasses(sizes []int, values []float64) *Fit {
+	m := len(sizes)
+	if m < MinSizes || m != len(values) {
+		return nil
+	}
+	largest := float64(sizes[m-1])
+
+	var mean, scale float64
+	for _, value := range values {
+		mean += value / float64(m)
+		scale = max(scale, math.Abs(value))
+	}
+	// Exact fits would have an infinite criterion, residuals below the
+	// resolution of the measurements count as that resolution
+	floor := math.Pow(1e-6*scale, 2) + math.SmallestNonzeroFloat64
+
+	criteria := make(map[string]float64, len(classes))
+	for _, c := range classes {
+		var residuals float64
+		parameters := 1.0
+		if c.growth == nil {
+			for _, value := range values {
+				residuals += (value - mean) * (value - mean)
+			}
+		} else {
+			x := make([]float64, m)
+			var xMean float64
+			for i, size := range sizes {
+				x[i] = c.growth(float64(size), largest)
+				xMean += x[i] / float64(m)
+			}
+			var covariance, variance float64
+			for i := range x {
+				covariance += (x[i] - xMean) * (values[i] - mean)
+				variance += (x[i] - xMean) * (x[i] - xMean)
+			}
+			if variance == 0 || covariance <= 0 {
+				continue
+			}
+			slope := covariance / variance
+			intercept := mean - slope*xMean
+			for i := range x {
+				residual := values[i] - (slope*x[i] + intercept)
+				residuals += residual * residual
+			}
+			parameters = 2
+		}
+		criteria[c.name] = float64(m)*math.Log(max(residuals/float64(m), floor)) + parameters*math.Log(float64(m))
+	}
+
+	best := math.Inf(1)
+	for _, criterion := range criteria {
+		best = min(best, criterion)
+	}
+	var total float64
+	weights := make(map[string]float64, len(criteria))
+	for name, criterion := range criteria {
+		weights[name] = math.Exp(-(criterion - best) / 2)
+		total += weights[name]
+	}
+
+	fit := &Fit{}
+	for _, c := range classes {
+		if weight, ok := weights[c.name]; ok {
+			fit.Candidates = append(fit.Candidates, Candidate{Class: c.name, Probability: weight / total})
+		}
+	}
+	// Ties go to the simpler class
+	sort.SliceStable(fit.Candidates, func(i, j int) bool {
+		return fit.Candidates[i].Probability > fit.Candidates[j].Probability
+	})
+	fit.Class = fit.Candidates[0].Class
+	fit.Confidence = fit.Candidates[0].Probability
+	return fit
+}
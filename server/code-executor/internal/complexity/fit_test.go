@@ -0,0 +1,53 @@
+package complexity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitClasses(t *testing.T) {
+	sizes := []int{1000, 2000, 4000, 8000, 16000, 32000, 64000}
+
+	tests := []struct {
+		name           string
+		growth         func(n float64) float64
+		want           string
+		wantConfidence float64
+	}{
+		// Noise alone fits growing classes about as well, which splits the confidence
+		{name: "constant", growth: func(n float64) float64 { return 0 }, want: Constant},
+		{name: "linear", growth: func(n float64) float64 { return 1e-6 * n }, want: Linear, wantConfidence: 0.5},
+		{name: "linearithmic", growth: func(n float64) float64 { return 1e-6 * n * math.Log(n) }, want: Linearithmic, wantConfidence: 0.5},
+		{name: "quadratic", growth: func(n float64) float64 { return 1e-9 * n * n }, want: Quadratic, wantConfidence: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A fixed startup cost plus 1% noise, alternating in sign
+			values := make([]float64, len(sizes))
+			for i, size := range sizes {
+				noise := 1 + 0.01*float64(1-2*(i%2))
+				values[i] = (0.05 + tt.growth(float64(size))) * noise
+			}
+
+			fit := FitClasses(sizes, values)
+			if fit == nil {
+				t.Fatal("FitClasses() = nil")
+			}
+			if fit.Class != tt.want {
+				t.Errorf("class %s, want %s (candidates %+v)", fit.Class, tt.want, fit.Candidates)
+			}
+			if fit.Confidence <= tt.wantConfidence {
+				t.Errorf("confidence %.2f, want above %.2f", fit.Confidence, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestFitClassesTooFewSizes(t *testing.T) {
+	if fit := FitClasses([]int{1, 2}, []float64{1, 2}); fit != nil {
+		t.Errorf("FitClasses() = %+v for 2 sizes, want nil", fit)
+	}
+	if fit := FitClasses([]int{1, 2, 3}, []float64{1, 2}); fit != nil {
+		t.Errorf("FitClasses() = %+v for mismatched lengths, want nil", fit)
+	}
+}
@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits of the program arguments and environment of a run
+const (
+	MaxArgs     = 64
+	MaxArgBytes = 16 * 1024 // Total size of the arguments
+	MaxEnv      = 32
+	MaxEnvBytes = 16 * 1024 // Total size of names and values
+)
+
+// ErrInvalidArguments is returned for arguments or environment variables that are not allowed
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+// envNamePattern matches portable environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// deniedEnv are variables callers may not set, because they change how the
+// sandbox, loader or interpreter behave rather than what the program sees
+var deniedEnv = map[string]bool{
+	"PATH":              true,
+	"HOME":              true,
+	"SHELL":             true,
+	"IFS":               true,
+	"ENV":               true,
+	"BASH_ENV":          true,
+	"TMPDIR":            true,
+	"NODE_OPTIONS":      true,
+	"NODE_PATH":         true,
+	"PYTHONPATH":        true,
+	"PYTHONHOME":        true,
+	"PYTHONSTARTUP":     true,
+	"PYTHONINSPECT":     true,
+	"RUBYOPT":           true,
+	"RUBYLIB":           true,
+	"PHPRC":             true,
+	"PHP_INI_SCAN_DIR":  true,
+	"JAVA_TOOL_OPTIONS": true,
+	"_JAVA_OPTIONS":     true,
+	"JDK_JAVA_OPTIONS":  true,
+	"CLASSPATH":         true,
+	"GOFLAGS":           true,
+	"GOTOOLCHAIN":       true,
+	"RUSTFLAGS":         true,
+}
+
+// deniedEnvPrefixes are prefixes of variables callers may not set
+var deniedEnvPrefixes = []string{"LD_", "DYLD_", "GCONV_", "MALLOC_"}
+
+// ValidateArgs checks the program arguments and environment of a run against
+// the denylist and size limits
+func ValidateArgs(args []string, env map[string]string) error {
+	if len(args) > MaxArgs {
+		return fmt.Errorf("%w: at most %d arguments are allowed", ErrInvalidArguments, MaxArgs)
+	}
+	size := 0
+	for _, arg := range args {
+		if strings.ContainsRune(arg, 0) {
+			return fmt.Errorf("%w: arguments must not contain NUL bytes", ErrInvalidArguments)
+		}
+		size += len(arg)
+	}
+	if size > MaxArgBytes {
+		return fmt.Errorf("%w: arguments exceed %d bytes", ErrInvalidArguments, MaxArgBytes)
+	}
+
+	if len(env) > MaxEnv {
+		return fmt.Errorf("%w: at most %d environment variables are allowed", ErrInvalidArguments, MaxEnv)
+	}
+	size = 0
+	for name, value := range env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: %q is not a valid environment variable name", ErrInvalidArguments, name)
+		}
+		if envDenied(name) {
+			return fmt.Errorf("%w: environment variable %s may not be set", ErrInvalidArguments, name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%w: environment variable %s contains a NUL byte", ErrInvalidArguments, name)
+		}
+		size += len(name) + len(value)
+	}
+	if size > MaxEnvBytes {
+		return fmt.Errorf("%w: environment exceeds %d bytes", ErrInvalidArguments, MaxEnvBytes)
+	}
+	return nil
+}
+
+// envDenied reports whether callers may not set the variable
+func envDenied(name string) bool {
+	upper := strings.ToUpper(name)
+	if deniedEnv[upper] {
+		return true
+	}
+	for _, prefix := range deniedEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+
+	// Variables the sandbox sets for a language or its dependencies, e.g.
+	// GOCACHE, keep their values
+	for _, language := range languages {
+		if envListed(language.Env, name) {
+			return true
+		}
+	}
+	for _, installer := range manifestInstallers {
+		if envListed(installer.env, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// envListed reports whether the variable is set in a list of NAME=value pairs
+func envListed(variables []string, name string) bool {
+	for _, variable := range variables {
+		if strings.EqualFold(strings.SplitN(variable, "=", 2)[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// environment returns the container environment of a run. Caller variables
+// come last in a stable order, after those the sandbox and dependencies need.
+func environment(language *Language, dependencies []string, env map[string]string) []string {
+	variables := append([]string{"HOME=/tmp"}, language.Env...) // The user has no home directory
+	variables = append(variables, dependencies...)
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variables = append(variables, name+"="+env[name])
+	}
+	return variables
+}
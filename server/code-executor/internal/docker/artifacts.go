@@ -0,0 +1,129 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Defaults for the files returned from a run
+const (
+	DefaultArtifactMaxFiles = 16
+	DefaultArtifactMaxBytes = 8 * 1024 * 1024
+)
+
+// artifactDir is the directory artifact patterns are matched in
+const artifactDir = "/tmp"
+
+// ErrInvalidArtifactPattern is returned for artifact patterns that are not valid globs below /tmp
+var ErrInvalidArtifactPattern = errors.New("invalid artifact pattern")
+
+// Artifact is a file the program left in /tmp
+type Artifact struct {
+	Path string // Relative to /tmp
+	Size int64
+	Data []byte
+}
+
+// ValidateArtifactPatterns checks that the patterns are globs matching files below /tmp
+func ValidateArtifactPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		relative, ok := artifactPattern(pattern)
+		if !ok {
+			return fmt.Errorf("%w: %q is not below %s", ErrInvalidArtifactPattern, pattern, artifactDir)
+		}
+		if _, err := path.Match(relative, ""); err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrInvalidArtifactPattern, pattern, err)
+		}
+	}
+	return nil
+}
+
+// artifactPattern returns the pattern relative to /tmp, false if it points elsewhere
+func artifactPattern(pattern string) (string, bool) {
+	relative := path.Clean(pattern)
+	if path.IsAbs(relative) {
+		var ok bool
+		if relative, ok = strings.CutPrefix(relative, artifactDir+"/"); !ok {
+			return "", false
+		}
+	}
+	if relative == "." || relative == ".." || strings.HasPrefix(relative, "../") {
+		return "", false
+	}
+	return relative, true
+}
+
+// matchArtifact reports whether a path relative to /tmp matches one of the patterns
+func matchArtifact(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		relative, ok := artifactPattern(pattern)
+		if !ok {
+			continue
+		}
+		if match, _ := path.Match(relative, name); match {
+			return true
+		}
+	}
+	return false
+}
+
+// collectArtifacts copies the files matching the patterns out of the stopped
+// container. Files beyond the count or size limit are skipped and reported as
+// truncated.
+func (m *Manager) collectArtifacts(ctx context.Context, containerID string, config ExecutionConfig) ([]Artifact, bool, error) {
+	maxFiles := config.ArtifactMaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultArtifactMaxFiles
+	}
+	maxBytes := config.ArtifactMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultArtifactMaxBytes
+	}
+
+	reader, _, err := m.client.CopyFromContainer(ctx, containerID, artifactDir)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to copy artifacts: %w", err)
+	}
+	defer reader.Close()
+
+	var artifacts []Artifact
+	var size int64
+	truncated := false
+
+	// Entries are named relative to the parent of /tmp, e.g. tmp/plot.png
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read artifacts: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(header.Name, path.Base(artifactDir)+"/")
+		if !matchArtifact(config.Artifacts, name) {
+			continue
+		}
+		if len(artifacts) >= maxFiles || size+header.Size > maxBytes {
+			truncated = true
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(archive, header.Size))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read artifact %s: %w", name, err)
+		}
+		size += header.Size
+		artifacts = append(artifacts, Artifact{Path: name, Size: header.Size, Data: data})
+	}
+
+	return artifacts, truncated, nil
+}
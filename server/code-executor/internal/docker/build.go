@@ -0,0 +1,385 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ErrInvalidImageDefinition is returned for image definitions that cannot be built or registered
+var ErrInvalidImageDefinition = errors.New("invalid image definition")
+
+// definitionNamePattern matches names of image definitions, used as version
+// names and in image tags
+var definitionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// ImageDefinition declares an image built on a language version with extra
+// packages, e.g. numpy for a data science course. Registered definitions are
+// versions of their language, selected with language@name.
+type ImageDefinition struct {
+	Name     string              `json:"name"`               // Version name, e.g. "data" in python@data
+	Language string              `json:"language"`           // Language the image runs
+	Base     string              `json:"base,omitempty"`     // Version built on, the language's default if empty
+	Packages map[string][]string `json:"packages,omitempty"` // Packages per package manager, installed from the mirror
+	Env      map[string]string   `json:"env,omitempty"`      // Image environment, e.g. CLASSPATH for jars
+	Setup    []string            `json:"setup,omitempty"`    // Shell commands run last while building
+
+	base Version // Resolved base version
+	tag  string  // Image tag, changes with the definition
+}
+
+// packageManager installs packages from its subdirectory of the package mirror
+type packageManager struct {
+	install func(packages []string) []string // Command installing from /tmp/mirror/<manager>
+	target  string                           // Directory plain files are copied to instead of installing
+	env     []string                         // Environment the installed packages need
+}
+
+// packageManagers are the package managers definitions can install with.
+// Builds run without network, so every manager reads only its mirror directory.
+var packageManagers = map[string]packageManager{
+	// A repository indexed with apk index
+	"apk": {install: func(packages []string) []string {
+		return append([]string{"apk", "add", "--no-network", "--allow-untrusted", "--repositories-file", "/dev/null", "--repository", "/tmp/mirror/apk"}, packages...)
+	}},
+	// Wheels and source archives fetched with pip download
+	"pip": {install: func(packages []string) []string {
+		return append([]string{"pip", "install", "--no-index", "--no-cache-dir", "--find-links", "/tmp/mirror/pip"}, packages...)
+	}},
+	// An npm cache filled with npm cache add
+	"npm": {
+		install: func(packages []string) []string {
+			return append([]string{"npm", "install", "--global", "--offline", "--cache", "/tmp/mirror/npm"}, packages...)
+		},
+		env: []string{"NODE_PATH=/usr/local/lib/node_modules"},
+	},
+	// Gem files fetched with gem fetch
+	"gem": {install: func(packages []string) []string {
+		return shell("cd /tmp/mirror/gem && gem install --local --no-document \"$@\"", packages)
+	}},
+	// Jars and other libraries, e.g. JUnit with CLASSPATH=/opt/lib/*:.
+	"lib": {target: "/opt/lib/"},
+	// Headers, e.g. single-header Catch2
+	"include": {target: "/usr/local/include/"},
+}
+
+// LoadImageDefinitions reads the image definitions of a directory, one JSON file each
+func LoadImageDefinitions(dir string) ([]*ImageDefinition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image definitions: %w", err)
+	}
+	sort.Strings(paths)
+
+	definitions := make([]*ImageDefinition, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image definition: %w", err)
+		}
+		var definition ImageDefinition
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return nil, fmt.Errorf("failed to parse image definition %s: %w", filepath.Base(path), err)
+		}
+		definitions = append(definitions, &definition)
+	}
+	return definitions, nil
+}
+
+// RegisterImageDefinitions adds the definitions to the language registry as
+// versions of their language. It must be called before the servers start.
+func RegisterImageDefinitions(definitions []*ImageDefinition) error {
+	for _, definition := range definitions {
+		language, err := definition.resolve()
+		if err != nil {
+			return err
+		}
+		language.Versions = append(language.Versions, Version{
+			Name:       definition.Name,
+			Image:      definition.tag,
+			Definition: definition,
+		})
+	}
+	return nil
+}
+
+// resolve validates the definition against the registry and derives its tag
+func (d *ImageDefinition) resolve() (*Language, error) {
+	if !definitionNamePattern.MatchString(d.Name) {
+		return nil, fmt.Errorf("%w: %q is not a valid name", ErrInvalidImageDefinition, d.Name)
+	}
+	language, ok := LookupLanguage(d.Language)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: unknown language %q", ErrInvalidImageDefinition, d.Name, d.Language)
+	}
+	d.Language = language.Name
+
+	for _, version := range language.Versions {
+		if version.Name == d.Name {
+			return nil, fmt.Errorf("%w: %s already has a version %q", ErrInvalidImageDefinition, language.Name, d.Name)
+		}
+	}
+	base := language.Versions[0]
+	if d.Base != "" {
+		_, version, err := ResolveLanguage(language.Name + "@" + d.Base)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImageDefinition, d.Name, err)
+		}
+		base = version
+	}
+	if base.Definition != nil {
+		return nil, fmt.Errorf("%w: %s: cannot build on the defined image %s", ErrInvalidImageDefinition, d.Name, base.Name)
+	}
+	d.base = base
+
+	for manager, packages := range d.Packages {
+		installer, ok := packageManagers[manager]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: unknown package manager %q", ErrInvalidImageDefinition, d.Name, manager)
+		}
+		for _, name := range packages {
+			if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, "\x00\r\n") {
+				return nil, fmt.Errorf("%w: %s: invalid %s package %q", ErrInvalidImageDefinition, d.Name, manager, name)
+			}
+			if installer.target != "" && (name == "." || name == ".." || strings.ContainsAny(name, "/\\")) {
+				return nil, fmt.Errorf("%w: %s: %s package %q is not a plain file name", ErrInvalidImageDefinition, d.Name, manager, name)
+			}
+		}
+	}
+	for name, value := range d.Env {
+		if !envNamePattern.MatchString(name) || strings.ContainsAny(value, "\x00\r\n") {
+			return nil, fmt.Errorf("%w: %s: invalid environment variable %q", ErrInvalidImageDefinition, d.Name, name)
+		}
+	}
+	for _, step := range d.Setup {
+		if strings.ContainsAny(step, "\x00\r\n") {
+			return nil, fmt.Errorf("%w: %s: setup steps must be single lines", ErrInvalidImageDefinition, d.Name)
+		}
+	}
+
+	// The tag is derived from the content, so a changed definition gets a new image
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image definition: %w", err)
+	}
+	sum := sha256.Sum256(append(encoded, d.base.Image...))
+	d.tag = fmt.Sprintf("code-executor/%s-%s:%s", language.Name, d.Name, hex.EncodeToString(sum[:6]))
+	return language, nil
+}
+
+// managers returns the package managers the definition uses in a stable order
+func (d *ImageDefinition) managers() []string {
+	managers := make([]string, 0, len(d.Packages))
+	for manager, packages := range d.Packages {
+		if len(packages) > 0 {
+			managers = append(managers, manager)
+		}
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+// dockerfile returns the Dockerfile building the definition on the base image.
+// Installers run in exec form, so package names never reach a shell.
+func (d *ImageDefinition) dockerfile(base string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\nUSER root\n", base)
+
+	for _, name := range d.managers() {
+		manager := packageManagers[name]
+		packages := d.Packages[name]
+		if manager.target != "" {
+			sources := make([]string, 0, len(packages)+1)
+			for _, file := range packages {
+				sources = append(sources, "mirror/"+name+"/"+file)
+			}
+			instruction, err := json.Marshal(append(sources, manager.target))
+			if err != nil {
+				return "", fmt.Errorf("failed to encode COPY instruction: %w", err)
+			}
+			fmt.Fprintf(&b, "COPY %s\n", instruction)
+			continue
+		}
+
+		instruction, err := json.Marshal(manager.install(packages))
+		if err != nil {
+			return "", fmt.Errorf("failed to encode RUN instruction: %w", err)
+		}
+		fmt.Fprintf(&b, "COPY mirror/%s /tmp/mirror/%s\nRUN %s\n", name, name, instruction)
+		for _, variable := range manager.env {
+			fmt.Fprintf(&b, "ENV %s\n", variable)
+		}
+	}
+	// The mirror stays in the COPY layers, but not in the file system code sees
+	b.WriteString("RUN rm -rf /tmp/mirror\n")
+
+	names := make([]string, 0, len(d.Env))
+	for name := range d.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	for _, name := range names {
+		fmt.Fprintf(&b, "ENV %s=\"%s\"\n", name, quote.Replace(d.Env[name]))
+	}
+
+	for _, step := range d.Setup {
+		fmt.Fprintf(&b, "RUN %s\n", step)
+	}
+	return b.String(), nil
+}
+
+// definitionFor returns the definition a registry image is built from, nil for pulled images
+func definitionFor(tag string) *ImageDefinition {
+	for _, language := range languages {
+		for _, version := range language.Versions {
+			if version.Image == tag && version.Definition != nil {
+				return version.Definition
+			}
+		}
+	}
+	return nil
+}
+
+// UsePackageMirror sets the directory image builds install packages from. It
+// has a subdirectory per package manager, e.g. pip/ with downloaded wheels.
+func (m *Manager) UsePackageMirror(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open package mirror: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("package mirror %s is not a directory", dir)
+	}
+	m.packageMirror = dir
+	return nil
+}
+
+// buildImage builds the image of a definition through the Docker build API.
+// The build has no network, packages come from the local mirror.
+func (m *Manager) buildImage(ctx context.Context, definition *ImageDefinition) error {
+	if err := m.EnsureImage(ctx, definition.base.Image); err != nil {
+		return err
+	}
+	if len(definition.managers()) > 0 && m.packageMirror == "" {
+		return fmt.Errorf("failed to build image %s: package mirror is not configured", definition.tag)
+	}
+
+	dockerfile, err := definition.dockerfile(m.reference(definition.base.Image))
+	if err != nil {
+		return err
+	}
+
+	// Stream the context, the mirror directories can be large
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(m.writeBuildContext(writer, definition, dockerfile))
+	}()
+	defer reader.Close()
+
+	response, err := m.client.ImageBuild(ctx, reader, types.ImageBuildOptions{
+		Tags:        []string{definition.tag},
+		NetworkMode: "none",
+		Remove:      true,
+		ForceRemove: true,
+		Labels:      map[string]string{"code-executor.definition": definition.Language + "@" + definition.Name},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build image %s: %w", definition.tag, err)
+	}
+	defer response.Body.Close()
+
+	if err := readProgress(response.Body); err != nil {
+		return fmt.Errorf("failed to build image %s: %w", definition.tag, err)
+	}
+	return nil
+}
+
+// writeBuildContext writes the build context of a definition as a tar archive:
+// the Dockerfile and the mirror directories of the package managers it uses
+func (m *Manager) writeBuildContext(w io.Writer, definition *ImageDefinition, dockerfile string) error {
+	archive := tar.NewWriter(w)
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: "Dockerfile", Mode: 0o644, Size: int64(len(dockerfile))}
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to archive Dockerfile: %w", err)
+	}
+	if _, err := io.WriteString(archive, dockerfile); err != nil {
+		return fmt.Errorf("failed to archive Dockerfile: %w", err)
+	}
+
+	for _, manager := range definition.managers() {
+		root := filepath.Join(m.packageMirror, manager)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return fmt.Errorf("package mirror has no %s directory", manager)
+		}
+
+		// Plain files are copied one by one, only those need to be in the context
+		include := func(string) bool { return true }
+		if packageManagers[manager].target != "" {
+			files := make(map[string]bool)
+			for _, file := range definition.Packages[manager] {
+				files[file] = true
+			}
+			include = func(path string) bool { return files[path] }
+		}
+
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			relative, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(filepath.Join("mirror", manager, relative))
+			if entry.IsDir() {
+				return archive.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0o755})
+			}
+			if !entry.Type().IsRegular() || !include(filepath.ToSlash(relative)) {
+				return nil // Symlinks and devices are never part of the context
+			}
+			return addFile(archive, path, name)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to archive %s mirror: %w", manager, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to archive build context: %w", err)
+	}
+	return nil
+}
+
+// addFile adds a regular file to the archive under the name
+func addFile(archive *tar.Writer, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, file)
+	return err
+}
@@ -0,0 +1,243 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"code-executor/internal/buildcache"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Locations and limits of compile outputs
+const (
+	buildDir            = ".build" // Directory in /tmp the compile step collects outputs in
+	maxBuildOutputBytes = 256 * 1024 * 1024
+)
+
+// compileResult is the outcome of the compile step of a run
+type compileResult struct {
+	archive     []byte           // Tar of the files the program needs in /tmp
+	cached      bool             // The archive came from the build cache
+	compileTime time.Duration    // Zero for cached builds
+	failed      *ExecutionResult // Compiler output and exit code if compiling failed
+}
+
+// UseBuildCache caches the outputs of the compile step of compiled languages,
+// so unchanged code is not compiled again
+func (m *Manager) UseBuildCache(cache *buildcache.Cache) {
+	m.buildCache = cache
+}
+
+// compileCached returns the compiled program from the build cache, compiling
+// it on a miss or without a cache. The key covers the language, the image with
+// its toolchain and dependencies, and the compile script with the source and flags.
+func (m *Manager) compileCached(ctx context.Context, language *Language, imageID, runtime string, config ExecutionConfig) (*compileResult, error) {
+	script := language.Build.Compile(config.Code)
+	if m.buildCache == nil {
+		return m.compile(ctx, language, imageID, runtime, config, script)
+	}
+	key := buildcache.Key(language.Name, imageID, script)
+	if archive, ok := m.buildCache.Get(key); ok {
+		return &compileResult{archive: archive, cached: true}, nil
+	}
+
+	result, err := m.compile(ctx, language, imageID, runtime, config, script)
+	if err != nil || result.failed != nil {
+		return result, err
+	}
+	if err := m.buildCache.Put(key, result.archive); err != nil {
+		log.Printf("Failed to cache build output: %v", err)
+	}
+	return result, nil
+}
+
+// compile runs the compile script in its own container with the limits of the
+// run and copies out the files the program needs. The container idles while
+// the script runs as an exec, as its /tmp only lives while the container runs.
+func (m *Manager) compile(ctx context.Context, language *Language, imageID, runtime string, config ExecutionConfig, script string) (*compileResult, error) {
+	compileCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	// The caller's environment is left out, as it is not part of the cache key
+	collect := fmt.Sprintf("mkdir %s && cp %s %s/", buildDir, language.Build.Outputs, buildDir)
+	containerConfig := &container.Config{
+		Image:           imageID,
+		Cmd:             []string{"sleep", strconv.Itoa(int((config.Timeout + idleMargin) / time.Second))},
+		Env:             environment(language, dependencyEnv(config.Manifest), nil),
+		WorkingDir:      "/tmp",
+		NetworkDisabled: true,
+	}
+	hostConfig := &container.HostConfig{
+		Resources: container.Resources{
+			Memory:    config.MemoryLimit,
+			CPUQuota:  int64(config.CPULimit * 100000),
+			CPUPeriod: 100000,
+		},
+		NetworkMode:    "none",
+		ReadonlyRootfs: true,
+		Runtime:        runtime,
+	}
+	m.applySecurityProfile(language, containerConfig, hostConfig)
+	// The outputs are copied out through the archive API
+	delete(hostConfig.Tmpfs, "/tmp")
+	hostConfig.Mounts = append(hostConfig.Mounts, m.profile.tmpVolume(language))
+
+	resp, err := m.client.ContainerCreate(compileCtx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compile container: %w", err)
+	}
+	defer m.removeContainer(resp.ID)
+
+	if err := m.client.ContainerStart(compileCtx, resp.ID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to start compile container: %w", err)
+	}
+
+	// go.mod marks the module root the dependencies are resolved from
+	if config.Manifest != nil {
+		manifest := []Fixture{{Name: config.Manifest.Name, Data: config.Manifest.Data}}
+		if err := m.copyFixtures(compileCtx, resp.ID, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	exec, err := m.client.ContainerExecCreate(compileCtx, resp.ID, container.ExecOptions{
+		User:         containerConfig.User,
+		AttachStdout: true,
+		AttachStderr: true,
+		WorkingDir:   containerConfig.WorkingDir,
+		Cmd:          shell(script+" && "+collect, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compile exec: %w", err)
+	}
+
+	start := time.Now()
+	hijackedResp, err := m.client.ContainerExecAttach(compileCtx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start compiler: %w", err)
+	}
+	defer hijackedResp.Close()
+
+	// Compiler output is only kept for failed compiles, cut off at the output limits of the run
+	stdout := newLimitedBuffer(outputLimit(config.StdoutLimit), func() {})
+	stderr := newLimitedBuffer(outputLimit(config.StderrLimit), func() {})
+	outputDone := make(chan error, 1)
+	go func() {
+		outputDone <- demultiplex(hijackedResp.Reader, stdout, stderr)
+	}()
+
+	var exitCode int
+	var timeout bool
+	select {
+	case err := <-outputDone:
+		if err != nil && compileCtx.Err() == nil {
+			return nil, fmt.Errorf("failed to read compiler output: %w", err)
+		}
+		exitCode, err = m.execExitCode(compileCtx, exec.ID)
+		if err != nil && compileCtx.Err() == nil {
+			return nil, err
+		}
+		timeout = err != nil
+	case <-compileCtx.Done():
+		timeout = true
+	}
+	compileTime := time.Since(start)
+
+	if timeout || exitCode != 0 {
+		m.client.ContainerKill(context.Background(), resp.ID, "SIGKILL")
+		return &compileResult{compileTime: compileTime, failed: &ExecutionResult{
+			Stdout:          stdout.String(),
+			Stderr:          stderr.String(),
+			StdoutTruncated: stdout.Truncated(),
+			StderrTruncated: stderr.Truncated(),
+			StdoutBytes:     stdout.total,
+			StderrBytes:     stderr.total,
+			ExitCode:        exitCode,
+			Timeout:         timeout,
+			ExecutionTime:   compileTime,
+			CompileTime:     compileTime,
+			ContainerID:     resp.ID,
+			Runtime:         runtime,
+		}}, nil
+	}
+
+	// The container still runs, so its /tmp is still mounted
+	reader, _, err := m.client.CopyFromContainer(ctx, resp.ID, "/tmp/"+buildDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy compile outputs: %w", err)
+	}
+	defer reader.Close()
+	archive, err := buildArchive(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &compileResult{archive: archive, compileTime: compileTime}, nil
+}
+
+// buildArchive turns the copy of the build directory into the archive placed
+// in /tmp of runs. Files are owned by root and read-only, so the program cannot
+// change what later runs of the same code get from the cache.
+func buildArchive(reader io.Reader) ([]byte, error) {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	source := tar.NewReader(reader)
+	var size int64
+	for {
+		header, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compile outputs: %w", err)
+		}
+		name := strings.TrimPrefix(header.Name, buildDir+"/")
+		if header.Typeflag != tar.TypeReg || name == header.Name || strings.Contains(name, "/") {
+			continue
+		}
+		size += header.Size
+		if size > maxBuildOutputBytes {
+			return nil, fmt.Errorf("compile outputs exceed %d bytes", maxBuildOutputBytes)
+		}
+
+		if err := writer.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0o555,
+			Size:     header.Size,
+			ModTime:  header.ModTime,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to archive compile output %s: %w", name, err)
+		}
+		if _, err := io.Copy(writer, source); err != nil {
+			return nil, fmt.Errorf("failed to archive compile output %s: %w", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to archive compile outputs: %w", err)
+	}
+	return archive.Bytes(), nil
+}
+
+// compileTime returns the time spent compiling in a separate step
+func compileTime(build *compileResult) time.Duration {
+	if build == nil {
+		return 0
+	}
+	return build.compileTime
+}
+
+// copyBuild places the compiled program in /tmp of the run container
+func (m *Manager) copyBuild(ctx context.Context, containerID string, archive []byte) error {
+	if err := m.client.CopyToContainer(ctx, containerID, "/tmp", bytes.NewReader(archive), container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy compiled program: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Stream types of Docker's multiplexed attach and log format. Every frame is
+// an 8-byte header [STREAM_TYPE, 0, 0, 0, SIZE1, SIZE2, SIZE3, SIZE4]
+// followed by SIZE bytes of payload.
+const (
+	streamStdin    = 0
+	streamStdout   = 1
+	streamStderr   = 2
+	streamSystem   = 3 // Error reported by the daemon
+	frameHeaderLen = 8
+)
+
+// maxSystemErrLen is the longest daemon error message read from a stream
+const maxSystemErrLen = 64 * 1024
+
+// demultiplex copies the frames of a multiplexed stream to stdout and
+// stderr until the stream ends. Headers and payloads are read in full, so
+// short reads from the connection cannot split or drop output.
+func demultiplex(src io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, frameHeaderLen)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("stream ended inside a frame header")
+			}
+			return err
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+
+		var dst io.Writer
+		switch header[0] {
+		case streamStdin, streamStdout:
+			dst = stdout
+		case streamStderr:
+			dst = stderr
+		case streamSystem:
+			message, err := io.ReadAll(io.LimitReader(src, min(size, maxSystemErrLen)))
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("daemon error: %s", message)
+		default:
+			return fmt.Errorf("unknown stream type %d", header[0])
+		}
+
+		// Copy the payload in pieces instead of allocating a frame of any size
+		if _, err := io.CopyN(dst, src, size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("stream ended inside a frame")
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Limits of the dependency install phase
+const (
+	MaxManifestBytes = 64 * 1024
+	installTimeout   = 5 * time.Minute
+	installMemory    = 1024 * 1024 * 1024
+	installTmpfs     = "rw,nosuid,nodev,mode=1777,size=1024m"
+	installLogTail   = "20" // Lines of installer output reported when an install fails
+)
+
+// dependencyImagePrefix is the repository of images with installed dependencies
+const dependencyImagePrefix = "code-executor/deps:"
+
+// Errors of dependency manifests and their installation
+var (
+	ErrInvalidManifest   = errors.New("invalid dependency manifest")
+	ErrDependencyInstall = errors.New("failed to install dependencies")
+)
+
+// Manifest is a dependency manifest submitted with the code, e.g. requirements.txt
+type Manifest struct {
+	Name string // File name, selects the package manager
+	Data []byte
+}
+
+// manifestInstaller installs the dependencies of a manifest into /opt/deps.
+// The install script runs in /opt/deps next to the manifest, with the package
+// cache mounted read-only at /cache and no network.
+type manifestInstaller struct {
+	language string
+	script   string
+	env      []string // Environment the program needs to find the dependencies
+}
+
+// manifestInstallers are the supported manifests by file name
+var manifestInstallers = map[string]manifestInstaller{
+	// Wheels only, so no package build code runs; the cache holds pip download output
+	"requirements.txt": {
+		language: "python",
+		script:   "pip install --no-index --only-binary=:all: --no-cache-dir --find-links /cache/pip --target /opt/deps/python -r requirements.txt",
+		env:      []string{"PYTHONPATH=/opt/deps/python"},
+	},
+	// npm writes to its cache while reading, so it works on a copy
+	"package.json": {
+		language: "javascript",
+		script:   "cp -R /cache/npm /tmp/npm-cache && npm install --offline --ignore-scripts --no-audit --no-fund --cache /tmp/npm-cache",
+		env:      []string{"NODE_PATH=/opt/deps/node_modules"},
+	},
+	// The cache is a module proxy directory, e.g. a copy of $GOMODCACHE/cache/download
+	"go.mod": {
+		language: "go",
+		script:   "GOMODCACHE=/opt/deps/go GOPROXY=file:///cache/go GOSUMDB=off GOFLAGS=-mod=mod go mod download all",
+		env:      []string{"GOMODCACHE=/opt/deps/go", "GOPROXY=off", "GOSUMDB=off", "GOFLAGS=-mod=mod"},
+	},
+	// The cache is a directory made with cargo vendor. Dependencies are built
+	// once and passed to rustc through /opt/deps/rustc-flags.
+	"Cargo.toml": {
+		language: "rust",
+		script: `set -e
+mkdir -p src .cargo
+echo 'fn main() {}' > src/main.rs
+printf '[source.crates-io]\nreplace-with = "cache"\n[source.cache]\ndirectory = "/cache/cargo"\n' > .cargo/config.toml
+CARGO_HOME=/tmp/cargo CARGO_TARGET_DIR=/opt/deps/target cargo build --release --offline
+deps=/opt/deps/target/release/deps
+echo "--edition 2021 -L dependency=$deps" > rustc-flags
+for lib in $deps/lib*.rlib $deps/lib*.so; do
+  [ -e "$lib" ] || continue
+  name=${lib##*/lib}
+  echo "--extern ${name%%-*}=$lib"
+done | sort -u -t= -k1,1 >> rustc-flags`,
+	},
+}
+
+// ValidateManifest checks a manifest is supported for the language and does
+// not collide with a fixture, as it is also placed in /tmp next to the code
+func ValidateManifest(manifest *Manifest, languageSpec string, fixtures []Fixture) error {
+	if manifest == nil {
+		return nil
+	}
+	installer, ok := manifestInstallers[manifest.Name]
+	if !ok {
+		return fmt.Errorf("%w: unsupported manifest %q", ErrInvalidManifest, manifest.Name)
+	}
+	language, _, err := ResolveLanguage(languageSpec)
+	if err != nil {
+		return err
+	}
+	if language.Name != installer.language {
+		return fmt.Errorf("%w: %s is not a %s manifest", ErrInvalidManifest, manifest.Name, language.Name)
+	}
+	if len(manifest.Data) > MaxManifestBytes {
+		return fmt.Errorf("%w: manifest exceeds %d bytes", ErrInvalidManifest, MaxManifestBytes)
+	}
+	for _, fixture := range fixtures {
+		if fixture.Name == manifest.Name {
+			return fmt.Errorf("%w: a fixture is named %s", ErrInvalidManifest, manifest.Name)
+		}
+	}
+	return nil
+}
+
+// UsePackageCache sets the package cache dependency installs read from: a
+// Docker volume name, or an absolute host directory
+func (m *Manager) UsePackageCache(source string) error {
+	if filepath.IsAbs(source) {
+		info, err := os.Stat(source)
+		if err != nil {
+			return fmt.Errorf("failed to open package cache: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("package cache %s is not a directory", source)
+		}
+	}
+	m.packageCache = source
+	return nil
+}
+
+// cacheMount returns the read-only mount of the package cache
+func (m *Manager) cacheMount() mount.Mount {
+	cache := mount.Mount{Type: mount.TypeVolume, Source: m.packageCache, Target: "/cache", ReadOnly: true}
+	if filepath.IsAbs(m.packageCache) {
+		cache.Type = mount.TypeBind
+	}
+	return cache
+}
+
+// installDependencies returns the ID of an image with the dependencies of
+// the manifest installed on the base image. Images are tagged by a hash of
+// the base image and the manifest, so repeated runs reuse the installed layer.
+func (m *Manager) installDependencies(ctx context.Context, language *Language, imageID string, manifest *Manifest) (string, bool, error) {
+	if m.packageCache == "" {
+		return "", false, fmt.Errorf("%w: dependency installs are not enabled", ErrInvalidManifest)
+	}
+
+	sum := sha256.Sum256([]byte(imageID + "\x00" + manifest.Name + "\x00" + string(manifest.Data)))
+	tag := dependencyImagePrefix + hex.EncodeToString(sum[:8])
+	cached, installed, err := m.inspectImage(ctx, tag)
+	if err != nil {
+		return "", false, err
+	}
+	if installed {
+		return cached.ID, true, nil
+	}
+
+	installCtx, cancel := context.WithTimeout(ctx, installTimeout)
+	defer cancel()
+
+	containerConfig := &container.Config{
+		Image:           imageID,
+		Cmd:             []string{"sh", "-c", manifestInstallers[manifest.Name].script},
+		Env:             environment(language, nil, nil),
+		WorkingDir:      "/opt/deps",
+		NetworkDisabled: true,
+	}
+	hostConfig := &container.HostConfig{
+		Resources: container.Resources{
+			Memory:    installMemory,
+			CPUQuota:  100000,
+			CPUPeriod: 100000,
+		},
+		NetworkMode: "none", // Packages come only from the cache
+	}
+	m.applySecurityProfile(language, containerConfig, hostConfig)
+	// Installers write to /opt/deps in the root file system, which is committed
+	// as the layer. Root without capabilities cannot do more than that.
+	containerConfig.User = "0:0"
+	hostConfig.Tmpfs = map[string]string{"/tmp": installTmpfs}
+	hostConfig.Mounts = append(hostConfig.Mounts, m.cacheMount())
+
+	resp, err := m.client.ContainerCreate(installCtx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create install container: %w", err)
+	}
+	defer m.client.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true})
+
+	if err := m.copyManifest(installCtx, resp.ID, manifest); err != nil {
+		return "", false, err
+	}
+	if err := m.client.ContainerStart(installCtx, resp.ID, container.StartOptions{}); err != nil {
+		return "", false, fmt.Errorf("failed to start install container: %w", err)
+	}
+
+	statusCh, errCh := m.client.ContainerWait(installCtx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if installCtx.Err() != nil {
+			return "", false, fmt.Errorf("%w: timed out after %s", ErrDependencyInstall, installTimeout)
+		}
+		return "", false, fmt.Errorf("install container wait error: %w", err)
+	case result := <-statusCh:
+		if result.StatusCode != 0 {
+			return "", false, fmt.Errorf("%w: installer exited with code %d: %s", ErrDependencyInstall, result.StatusCode, m.installLog(ctx, resp.ID))
+		}
+	}
+
+	if _, err := m.client.ContainerCommit(ctx, resp.ID, container.CommitOptions{
+		Reference: tag,
+		Comment:   "Dependencies of " + manifest.Name,
+	}); err != nil {
+		return "", false, fmt.Errorf("failed to commit dependencies: %w", err)
+	}
+	committed, _, err := m.inspectImage(ctx, tag)
+	if err != nil {
+		return "", false, err
+	}
+	return committed.ID, false, nil
+}
+
+// copyManifest places the manifest in /opt/deps of the install container
+func (m *Manager) copyManifest(ctx context.Context, containerID string, manifest *Manifest) error {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	now := time.Now()
+	if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "opt/deps/", Mode: 0o755, ModTime: now}); err != nil {
+		return fmt.Errorf("failed to archive manifest: %w", err)
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "opt/deps/" + manifest.Name,
+		Mode:     0o644,
+		Size:     int64(len(manifest.Data)),
+		ModTime:  now,
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to archive manifest: %w", err)
+	}
+	if _, err := writer.Write(manifest.Data); err != nil {
+		return fmt.Errorf("failed to archive manifest: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to archive manifest: %w", err)
+	}
+
+	if err := m.client.CopyToContainer(ctx, containerID, "/", &archive, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy manifest: %w", err)
+	}
+	return nil
+}
+
+// installLog returns the last lines the installer wrote, to explain a failed install
+func (m *Manager) installLog(ctx context.Context, containerID string) string {
+	logs, err := m.client.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       installLogTail,
+	})
+	if err != nil {
+		return "no installer output"
+	}
+	defer logs.Close()
+
+	var output bytes.Buffer
+	if err := demultiplex(logs, &output, &output); err != nil {
+		return "no installer output"
+	}
+	return strings.TrimSpace(output.String())
+}
+
+// dependencyEnv returns the environment a program needs for the dependencies of the manifest
+func dependencyEnv(manifest *Manifest) []string {
+	if manifest == nil {
+		return nil
+	}
+	return manifestInstallers[manifest.Name].env
+}
@@ -0,0 +1,193 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Settings deterministic runs share, so output does not depend on the host
+const (
+	DefaultSeed         = 1
+	deterministicTZ     = "UTC"
+	deterministicLocale = "C.UTF-8"
+	fakeTimeLibrary     = "/opt/faketime/libfaketime.so.1"
+	fakeTimeFormat      = "2006-01-02 15:04:05"
+	minFakeTimeYear     = 1970
+	maxFakeTimeYear     = 2100
+)
+
+// Determinism selects a deterministic run. The run records the settings it
+// applied in the same form, so a run can be repeated with the recorded settings.
+type Determinism struct {
+	Seed     uint32    // Seed of the language runtime, DefaultSeed if zero
+	FakeTime time.Time // Start of a faked wall clock, the real clock if zero
+	PinCPU   bool      // Run on a single CPU
+
+	// Set by the run
+	Timezone string
+	Locale   string
+	CPUSet   string // CPU the run was pinned to
+}
+
+// Seeding fixes the randomness a language runtime draws at startup
+type Seeding struct {
+	Env  func(seed uint32) []string // Variables set for the program
+	Flag string                     // Interpreter flag taking the seed, e.g. --random-seed
+}
+
+// deterministicEnvPrefixes are variables deterministic runs set, which callers
+// may not override in them
+var deterministicEnvPrefixes = []string{"TZ", "LANG", "LANGUAGE", "LC_", "FAKETIME", "DONT_FAKE_"}
+
+// ValidateDeterminism checks the caller environment does not override what a
+// deterministic run fixes and the faked clock is in range
+func ValidateDeterminism(determinism *Determinism, languageSpec string, env map[string]string) error {
+	if determinism == nil {
+		return nil
+	}
+	if !determinism.FakeTime.IsZero() {
+		year := determinism.FakeTime.UTC().Year()
+		if year < minFakeTimeYear || year >= maxFakeTimeYear {
+			return fmt.Errorf("%w: fake time must be between %d and %d", ErrInvalidArguments, minFakeTimeYear, maxFakeTimeYear)
+		}
+	}
+
+	language, _, err := ResolveLanguage(languageSpec)
+	if err != nil {
+		return err
+	}
+	var seedEnv []string
+	if language.Seeding != nil && language.Seeding.Env != nil {
+		seedEnv = language.Seeding.Env(DefaultSeed)
+	}
+	for name := range env {
+		upper := strings.ToUpper(name)
+		for _, prefix := range deterministicEnvPrefixes {
+			if upper == prefix || (strings.HasSuffix(prefix, "_") && strings.HasPrefix(upper, prefix)) {
+				return fmt.Errorf("%w: environment variable %s is fixed in deterministic runs", ErrInvalidArguments, name)
+			}
+		}
+		if envListed(seedEnv, name) {
+			return fmt.Errorf("%w: environment variable %s is fixed in deterministic runs", ErrInvalidArguments, name)
+		}
+	}
+	return nil
+}
+
+// UseFakeTime enables faked wall clocks in deterministic runs, preloading the
+// libfaketime library at the host path into programs. The library must match
+// the C library of the images, e.g. be built against musl for Alpine images.
+func (m *Manager) UseFakeTime(library string) error {
+	info, err := os.Stat(library)
+	if err != nil {
+		return fmt.Errorf("failed to open fake time library: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("fake time library %s is a directory", library)
+	}
+	m.fakeTime = library
+	return nil
+}
+
+// UseDeterministicCPUs sets the CPUs deterministic runs are pinned to, as a
+// comma separated list. Runs take turns between them.
+func (m *Manager) UseDeterministicCPUs(list string) error {
+	var cpus []string
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if cpu, err := strconv.Atoi(field); err != nil || cpu < 0 {
+			return fmt.Errorf("invalid CPU %q", field)
+		}
+		cpus = append(cpus, field)
+	}
+	m.pinnedCPUs = cpus
+	return nil
+}
+
+// deterministic returns the settings a deterministic run applies. A recorded
+// CPU is kept when it is still one deterministic runs are pinned to.
+func (m *Manager) deterministic(requested *Determinism) (*Determinism, error) {
+	if requested == nil {
+		return nil, nil
+	}
+	if !requested.FakeTime.IsZero() && m.fakeTime == "" {
+		return nil, fmt.Errorf("%w: fake time is not enabled", ErrInvalidArguments)
+	}
+
+	applied := &Determinism{
+		Seed:     requested.Seed,
+		FakeTime: requested.FakeTime.UTC(),
+		PinCPU:   requested.PinCPU,
+		Timezone: deterministicTZ,
+		Locale:   deterministicLocale,
+	}
+	if applied.Seed == 0 {
+		applied.Seed = DefaultSeed
+	}
+	if applied.PinCPU {
+		for _, cpu := range m.pinnedCPUs {
+			if cpu == requested.CPUSet {
+				applied.CPUSet = cpu
+			}
+		}
+		if applied.CPUSet == "" {
+			turn := atomic.AddUint64(&m.pinnedTurn, 1)
+			applied.CPUSet = m.pinnedCPUs[int(turn%uint64(len(m.pinnedCPUs)))]
+		}
+	}
+	return applied, nil
+}
+
+// env returns the variables the deterministic run sets for the language
+func (d *Determinism) env(language *Language) []string {
+	if d == nil {
+		return nil
+	}
+	variables := []string{"TZ=" + d.Timezone, "LANG=" + d.Locale, "LC_ALL=" + d.Locale}
+	if language.Seeding != nil && language.Seeding.Env != nil {
+		variables = append(variables, language.Seeding.Env(d.Seed)...)
+	}
+	if !d.FakeTime.IsZero() {
+		// The clock starts at the fake time and advances from there
+		variables = append(variables,
+			"LD_PRELOAD="+fakeTimeLibrary,
+			"FAKETIME=@"+d.FakeTime.Format(fakeTimeFormat),
+			"FAKETIME_DONT_RESET=1",
+		)
+	}
+	return variables
+}
+
+// command adds the seed flag of the interpreter to the command
+func (d *Determinism) command(language *Language, command []string) []string {
+	if d == nil || language.Seeding == nil || language.Seeding.Flag == "" || language.Build != nil {
+		return command
+	}
+	seeded := []string{command[0], fmt.Sprintf("%s=%d", language.Seeding.Flag, d.Seed)}
+	return append(seeded, command[1:]...)
+}
+
+// applyDeterminism pins the run to its CPU and mounts the fake time library
+func (m *Manager) applyDeterminism(d *Determinism, hostConfig *container.HostConfig) {
+	if d == nil {
+		return
+	}
+	if d.CPUSet != "" {
+		hostConfig.Resources.CpusetCpus = d.CPUSet
+	}
+	if !d.FakeTime.IsZero() {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   m.fakeTime,
+			Target:   fakeTimeLibrary,
+			ReadOnly: true,
+		})
+	}
+}
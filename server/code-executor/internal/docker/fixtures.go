@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Limits of the fixture files attached to a run
+const (
+	MaxFixtures     = 16
+	MaxFixtureBytes = 16 * 1024 * 1024
+)
+
+// ErrInvalidFixture is returned for fixtures with unusable names or sizes
+var ErrInvalidFixture = errors.New("invalid fixture")
+
+// Fixture is a data file placed read-only in the working directory before the run
+type Fixture struct {
+	Name string // File name in /tmp
+	Data []byte
+}
+
+// FixtureDigest records the integrity of a fixture the program ran with
+type FixtureDigest struct {
+	Name   string
+	Size   int64
+	SHA256 string // Hex-encoded
+}
+
+// ValidateFixtures checks fixture names and the size limits
+func ValidateFixtures(fixtures []Fixture) error {
+	if len(fixtures) > MaxFixtures {
+		return fmt.Errorf("%w: at most %d fixtures are allowed", ErrInvalidFixture, MaxFixtures)
+	}
+
+	seen := make(map[string]bool, len(fixtures))
+	var size int
+	for _, fixture := range fixtures {
+		name := fixture.Name
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+			return fmt.Errorf("%w: %q is not a plain file name", ErrInvalidFixture, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: %q is attached twice", ErrInvalidFixture, name)
+		}
+		seen[name] = true
+		size += len(fixture.Data)
+	}
+	if size > MaxFixtureBytes {
+		return fmt.Errorf("%w: fixtures exceed %d bytes", ErrInvalidFixture, MaxFixtureBytes)
+	}
+	return nil
+}
+
+// fixtureDigests returns the integrity hashes of the fixtures
+func fixtureDigests(fixtures []Fixture) []FixtureDigest {
+	digests := make([]FixtureDigest, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		sum := sha256.Sum256(fixture.Data)
+		digests = append(digests, FixtureDigest{
+			Name:   fixture.Name,
+			Size:   int64(len(fixture.Data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	return digests
+}
+
+// copyFixtures places the fixtures in /tmp of the created container. They are
+// owned by root and not writable, and the sticky bit of /tmp keeps the program
+// from replacing them.
+func (m *Manager) copyFixtures(ctx context.Context, containerID string, fixtures []Fixture) error {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	now := time.Now()
+	for _, fixture := range fixtures {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     fixture.Name,
+			Mode:     0o444,
+			Size:     int64(len(fixture.Data)),
+			ModTime:  now,
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to archive fixture %s: %w", fixture.Name, err)
+		}
+		if _, err := writer.Write(fixture.Data); err != nil {
+			return fmt.Errorf("failed to archive fixture %s: %w", fixture.Name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to archive fixtures: %w", err)
+	}
+
+	if err := m.client.CopyToContainer(ctx, containerID, "/tmp", &archive, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy fixtures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,450 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+)
+
+// ErrUnknownImage is returned for images that are not in the language registry
+var ErrUnknownImage = errors.New("image is not in the language registry")
+
+// ErrImageUnavailable is returned for image digests that can no longer be installed
+var ErrImageUnavailable = errors.New("image is no longer available")
+
+// digestPattern matches a content digest images are pinned to
+var digestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// VersionInfo describes a language version and whether its image is installed
+type VersionInfo struct {
+	Version   string
+	Image     string
+	Digest    string // Content digest of the installed image
+	Pinned    string // Digest the image is pinned to, empty if it follows the tag
+	Installed bool
+	Default   bool
+}
+
+// LanguageInfo describes a supported language and its versions
+type LanguageInfo struct {
+	Name     string
+	Aliases  []string
+	Versions []VersionInfo
+}
+
+// ImageStatus describes an image of the language registry
+type ImageStatus struct {
+	Image      string
+	Languages  []string // language@version specs running in the image
+	Required   bool     // The default version of a language, needed before the service is ready
+	Built      bool     // Built from an image definition instead of pulled
+	Pinned     string   // Digest the image is pinned to, empty if it follows the tag
+	Installed  bool
+	ID         string
+	Digest     string
+	Size       int64
+	LastPulled time.Time // Zero if not pulled or built by this process
+	LastError  string    // Error of the last pull or build, empty if it succeeded
+}
+
+// progressMessage is a message of the progress stream of an image pull or build
+type progressMessage struct {
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// pullStatus is the outcome of the last pull or build of an image
+type pullStatus struct {
+	pulled time.Time
+	err    error
+}
+
+// LoadImagePins reads a JSON file mapping image tags to the digests they are pinned to
+func LoadImagePins(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image pins: %w", err)
+	}
+
+	var pins map[string]string
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("failed to parse image pins: %w", err)
+	}
+
+	registry := registryImages()
+	for tag, digest := range pins {
+		if _, ok := registry[tag]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownImage, tag)
+		}
+		if definitionFor(tag) != nil {
+			return nil, fmt.Errorf("%s is built from a definition and cannot be pinned", tag)
+		}
+		if !digestPattern.MatchString(digest) {
+			return nil, fmt.Errorf("invalid digest %q for %s", digest, tag)
+		}
+	}
+	return pins, nil
+}
+
+// PinImages makes executions run pinned images by digest instead of by tag
+func (m *Manager) PinImages(pins map[string]string) {
+	m.imagesMu.Lock()
+	defer m.imagesMu.Unlock()
+	m.pins = pins
+}
+
+// registryImages returns every image of the language registry with the specs running in it
+func registryImages() map[string][]string {
+	images := make(map[string][]string)
+	for _, language := range languages {
+		for _, version := range language.Versions {
+			images[version.Image] = append(images[version.Image], language.Name+"@"+version.Name)
+		}
+	}
+	return images
+}
+
+// requiredImages returns the images of the default language versions
+func requiredImages() map[string]bool {
+	required := make(map[string]bool)
+	for _, language := range languages {
+		required[language.Versions[0].Image] = true
+	}
+	return required
+}
+
+// sortedImages returns the registry images in a stable order
+func sortedImages() []string {
+	registry := registryImages()
+	tags := make([]string, 0, len(registry))
+	for tag := range registry {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// pinned returns the digest an image is pinned to, empty if it follows the tag
+func (m *Manager) pinned(tag string) string {
+	m.imagesMu.Lock()
+	defer m.imagesMu.Unlock()
+	return m.pins[tag]
+}
+
+// reference returns the reference an image is pulled and run by
+func (m *Manager) reference(tag string) string {
+	if digest := m.pinned(tag); digest != "" {
+		return tag + "@" + digest
+	}
+	return tag
+}
+
+// resolveImage returns the ID of the local image of a version and its content
+// digest. Containers are created from the ID, so a tag moving during the run
+// cannot change the image the code runs in.
+func (m *Manager) resolveImage(ctx context.Context, version Version) (string, string, error) {
+	reference := m.reference(version.Image)
+	inspect, _, err := m.client.ImageInspectWithRaw(ctx, reference)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to inspect image %s: %w", reference, err)
+	}
+	return inspect.ID, imageDigest(inspect), nil
+}
+
+// resolveDigest returns the ID of the image with the content digest an
+// earlier run reported, pulling it by digest if it is not installed. Images
+// built locally are only available while they are installed.
+func (m *Manager) resolveDigest(ctx context.Context, digest string) (string, error) {
+	inspect, installed, err := m.inspectImage(ctx, digest)
+	if err != nil {
+		return "", err
+	}
+	if installed {
+		return inspect.ID, nil
+	}
+	if !strings.Contains(digest, "@") {
+		return "", fmt.Errorf("%w: %s", ErrImageUnavailable, digest)
+	}
+
+	reader, err := m.client.ImagePull(ctx, digest, image.PullOptions{})
+	if err == nil {
+		err = readProgress(reader)
+		reader.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to pull %s: %v", ErrImageUnavailable, digest, err)
+	}
+	inspect, _, err = m.client.ImageInspectWithRaw(ctx, digest)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", digest, err)
+	}
+	return inspect.ID, nil
+}
+
+// imageDigest returns the content digest of an image. Images built locally
+// have no registry digest, their ID is the content digest.
+func imageDigest(inspect types.ImageInspect) string {
+	if len(inspect.RepoDigests) > 0 {
+		return inspect.RepoDigests[0]
+	}
+	return inspect.ID
+}
+
+// inspectImage returns the local image, false if it is not installed
+func (m *Manager) inspectImage(ctx context.Context, reference string) (types.ImageInspect, bool, error) {
+	inspect, _, err := m.client.ImageInspectWithRaw(ctx, reference)
+	if errdefs.IsNotFound(err) {
+		return types.ImageInspect{}, false, nil
+	}
+	if err != nil {
+		return types.ImageInspect{}, false, fmt.Errorf("failed to inspect image %s: %w", reference, err)
+	}
+	return inspect, true, nil
+}
+
+// readProgress consumes the progress stream of an image pull or build. The
+// daemon reports failures such as missing tags, authentication errors or
+// failed build steps in the stream after accepting the request.
+func readProgress(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		var message progressMessage
+		if err := decoder.Decode(&message); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read progress: %w", err)
+		}
+		if message.ErrorDetail != nil && message.ErrorDetail.Message != "" {
+			return errors.New(message.ErrorDetail.Message)
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+	}
+}
+
+// pullImage pulls an image and waits for the pull to finish. Images built
+// from a definition are built instead.
+func (m *Manager) pullImage(ctx context.Context, tag string) error {
+	var err error
+	if definition := definitionFor(tag); definition != nil {
+		err = m.buildImage(ctx, definition)
+	} else {
+		reference := m.reference(tag)
+		err = func() error {
+			reader, err := m.client.ImagePull(ctx, reference, image.PullOptions{})
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+			return readProgress(reader)
+		}()
+		if err != nil {
+			err = fmt.Errorf("failed to pull image %s: %w", reference, err)
+		}
+	}
+
+	m.imagesMu.Lock()
+	m.pulls[tag] = pullStatus{pulled: time.Now(), err: err}
+	m.imagesMu.Unlock()
+	return err
+}
+
+// EnsureImage pulls or builds the image of a language registry tag unless it is installed
+func (m *Manager) EnsureImage(ctx context.Context, tag string) error {
+	_, installed, err := m.inspectImage(ctx, m.reference(tag))
+	if err != nil || installed {
+		return err
+	}
+	return m.pullImage(ctx, tag)
+}
+
+// PrewarmImages pulls every missing image of the language registry, so the
+// first execution of a language does not wait for a pull
+func (m *Manager) PrewarmImages(ctx context.Context) error {
+	var errs []error
+	for _, tag := range sortedImages() {
+		if err := m.EnsureImage(ctx, tag); err != nil {
+			log.Printf("Failed to pre-pull %s: %v", tag, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RefreshImages pulls the registry images again, so tags pick up updates, and
+// removes the images a tag pointed to before. Pinned and built images are only
+// pulled or built if missing, the tag of a built image changes with its definition.
+func (m *Manager) RefreshImages(ctx context.Context) error {
+	var errs []error
+	for _, tag := range sortedImages() {
+		if m.pinned(tag) != "" || definitionFor(tag) != nil {
+			if err := m.EnsureImage(ctx, tag); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		before, installed, err := m.inspectImage(ctx, tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.pullImage(ctx, tag); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		after, _, err := m.inspectImage(ctx, tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Removal fails while running containers or other tags use the old
+		// image, which is then left for the daemon's own pruning
+		if installed && before.ID != after.ID {
+			if _, err := m.client.ImageRemove(ctx, before.ID, image.RemoveOptions{PruneChildren: true}); err != nil {
+				log.Printf("Failed to remove outdated image %s of %s: %v", before.ID, tag, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunImageRefresh refreshes the registry images every interval until the context ends
+func (m *Manager) RunImageRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.RefreshImages(ctx); err != nil {
+				log.Printf("Image refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// MissingImages returns the required images that are not installed. The
+// service is not ready to execute code until none are missing.
+func (m *Manager) MissingImages(ctx context.Context) ([]string, error) {
+	required := requiredImages()
+
+	var missing []string
+	for _, tag := range sortedImages() {
+		if !required[tag] {
+			continue
+		}
+		_, installed, err := m.inspectImage(ctx, m.reference(tag))
+		if err != nil {
+			return nil, err
+		}
+		if !installed {
+			missing = append(missing, tag)
+		}
+	}
+	return missing, nil
+}
+
+// Images returns the status of every image of the language registry
+func (m *Manager) Images(ctx context.Context) ([]ImageStatus, error) {
+	registry := registryImages()
+	required := requiredImages()
+
+	statuses := make([]ImageStatus, 0, len(registry))
+	for _, tag := range sortedImages() {
+		status := ImageStatus{
+			Image:     tag,
+			Languages: registry[tag],
+			Required:  required[tag],
+			Built:     definitionFor(tag) != nil,
+			Pinned:    m.pinned(tag),
+		}
+
+		inspect, installed, err := m.inspectImage(ctx, m.reference(tag))
+		if err != nil {
+			return nil, err
+		}
+		if installed {
+			status.Installed = true
+			status.ID = inspect.ID
+			status.Digest = imageDigest(inspect)
+			status.Size = inspect.Size
+		}
+
+		m.imagesMu.Lock()
+		if pull, ok := m.pulls[tag]; ok {
+			status.LastPulled = pull.pulled
+			if pull.err != nil {
+				status.LastError = pull.err.Error()
+			}
+		}
+		m.imagesMu.Unlock()
+
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// PullImage pulls a registry image, updating its tag. Images built from a definition are rebuilt.
+func (m *Manager) PullImage(ctx context.Context, tag string) error {
+	if _, ok := registryImages()[tag]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownImage, tag)
+	}
+	return m.pullImage(ctx, tag)
+}
+
+// RemoveImage removes a registry image. Images used by containers are not removed.
+func (m *Manager) RemoveImage(ctx context.Context, tag string) error {
+	if _, ok := registryImages()[tag]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownImage, tag)
+	}
+	if _, err := m.client.ImageRemove(ctx, m.reference(tag), image.RemoveOptions{PruneChildren: true}); err != nil {
+		return fmt.Errorf("failed to remove image %s: %w", tag, err)
+	}
+	return nil
+}
+
+// Languages returns the supported languages with their versions and which images are installed
+func (m *Manager) Languages(ctx context.Context) ([]LanguageInfo, error) {
+	infos := make([]LanguageInfo, 0, len(languages))
+	for _, language := range languages {
+		info := LanguageInfo{Name: language.Name, Aliases: language.Aliases}
+		for i, version := range language.Versions {
+			versionInfo := VersionInfo{
+				Version: version.Name,
+				Image:   version.Image,
+				Pinned:  m.pinned(version.Image),
+				Default: i == 0,
+			}
+
+			inspect, installed, err := m.inspectImage(ctx, m.reference(version.Image))
+			if err != nil {
+				return nil, err
+			}
+			if installed {
+				versionInfo.Installed = true
+				versionInfo.Digest = imageDigest(inspect)
+			}
+			info.Versions = append(info.Versions, versionInfo)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,229 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Language describes how code of a language is run
+type Language struct {
+	Name     string
+	Aliases  []string
+	Versions []Version                                 // Supported toolchain versions, the first is the default
+	Env      []string                                  // Extra environment, e.g. caches moved to the writable /tmp
+	Compiled bool                                      // Builds a binary in /tmp, so /tmp must allow exec
+	Command  func(code string, args []string) []string // Command running the code with the program arguments
+	Build    *Build                                    // Compile step of compiled languages, replacing Command
+	Seeding  *Seeding                                  // Fixes the runtime's randomness in deterministic runs
+}
+
+// Build describes how code of a compiled language is compiled and run, so the
+// compile step can run separately and its outputs be cached
+type Build struct {
+	Compile func(code string) string // Script compiling the code in /tmp
+	Outputs string                   // Files in /tmp the program needs, as a shell glob
+	Run     string                   // Script running the compiled program
+}
+
+// Version is a toolchain version of a language and the image providing it
+type Version struct {
+	Name       string           // Version requests select, e.g. "3.12" in python@3.12
+	Image      string           // Image tag
+	Definition *ImageDefinition // Set for images built from a definition instead of pulled
+}
+
+// ErrUnsupportedVersion is returned for language versions the registry does not have
+var ErrUnsupportedVersion = errors.New("unsupported language version")
+
+// languages is the registry of supported languages
+var languages = []*Language{
+	{
+		Name:    "python",
+		Aliases: []string{"python3"},
+		Versions: []Version{
+			{Name: "3.11", Image: "python:3.11-alpine"},
+			{Name: "3.12", Image: "python:3.12-alpine"},
+			{Name: "3.13", Image: "python:3.13-alpine"},
+		},
+		Env:     []string{"PYTHONDONTWRITEBYTECODE=1"},
+		Command: func(code string, args []string) []string { return append([]string{"python3", "-c", code}, args...) },
+		Seeding: &Seeding{Env: func(seed uint32) []string { return []string{fmt.Sprintf("PYTHONHASHSEED=%d", seed)} }},
+	},
+	{
+		Name:    "javascript",
+		Aliases: []string{"js", "node"},
+		Versions: []Version{
+			{Name: "18", Image: "node:18-alpine"},
+			{Name: "20", Image: "node:20-alpine"},
+			{Name: "22", Image: "node:22-alpine"},
+		},
+		Command: func(code string, args []string) []string { return append([]string{"node", "-e", code, "--"}, args...) },
+		Seeding: &Seeding{Flag: "--random-seed"}, // Seeds Math.random
+	},
+	{
+		Name:    "go",
+		Aliases: []string{"golang"},
+		Versions: []Version{
+			{Name: "1.21", Image: "golang:1.21-alpine"},
+			{Name: "1.22", Image: "golang:1.22-alpine"},
+			{Name: "1.23", Image: "golang:1.23-alpine"},
+		},
+		Env: []string{"GOCACHE=/tmp/.cache/go-build", "GOPATH=/tmp/go"},
+		// math/rand without an explicit seed starts from 1, as before Go 1.20
+		Seeding:  &Seeding{Env: func(uint32) []string { return []string{"GODEBUG=randautoseed=0"} }},
+		Compiled: true,
+		Build: &Build{
+			Compile: func(code string) string { return fmt.Sprintf("echo '%s' > main.go && go build -o main main.go", code) },
+			Outputs: "main",
+			Run:     "./main",
+		},
+	},
+	{
+		Name: "java",
+		Versions: []Version{
+			{Name: "11", Image: "openjdk:11-alpine"},
+			{Name: "17", Image: "eclipse-temurin:17-jdk-alpine"},
+			{Name: "21", Image: "eclipse-temurin:21-jdk-alpine"},
+		},
+		Build: &Build{
+			Compile: func(code string) string { return fmt.Sprintf("echo '%s' > Main.java && javac Main.java", code) },
+			Outputs: "*.class",
+			Run:     "java Main",
+		},
+	},
+	{
+		Name: "c",
+		Versions: []Version{
+			{Name: "latest", Image: "gcc:alpine"},
+			{Name: "13", Image: "gcc:13"},
+			{Name: "14", Image: "gcc:14"},
+		},
+		Compiled: true,
+		Build: &Build{
+			Compile: func(code string) string { return fmt.Sprintf("echo '%s' > main.c && gcc main.c -o main", code) },
+			Outputs: "main",
+			Run:     "./main",
+		},
+	},
+	{
+		Name:    "cpp",
+		Aliases: []string{"c++"},
+		Versions: []Version{
+			{Name: "latest", Image: "gcc:alpine"},
+			{Name: "13", Image: "gcc:13"},
+			{Name: "14", Image: "gcc:14"},
+		},
+		Compiled: true,
+		Build: &Build{
+			Compile: func(code string) string { return fmt.Sprintf("echo '%s' > main.cpp && g++ main.cpp -o main", code) },
+			Outputs: "main",
+			Run:     "./main",
+		},
+	},
+	{
+		Name: "rust",
+		Versions: []Version{
+			{Name: "latest", Image: "rust:alpine"},
+			{Name: "1.80", Image: "rust:1.80-alpine"},
+			{Name: "1.82", Image: "rust:1.82-alpine"},
+		},
+		Compiled: true,
+		Build: &Build{
+			// Dependencies of a Cargo.toml manifest are passed as rustc flags
+			Compile: func(code string) string {
+				return fmt.Sprintf("echo '%s' > main.rs && rustc $(cat /opt/deps/rustc-flags 2>/dev/null) main.rs", code)
+			},
+			Outputs: "main",
+			Run:     "./main",
+		},
+	},
+	{
+		Name: "ruby",
+		Versions: []Version{
+			{Name: "3.2", Image: "ruby:3.2-alpine"},
+			{Name: "3.3", Image: "ruby:3.3-alpine"},
+		},
+		Command: func(code string, args []string) []string { return append([]string{"ruby", "-e", code, "--"}, args...) },
+	},
+	{
+		Name: "php",
+		Versions: []Version{
+			{Name: "8.2", Image: "php:8.2-alpine"},
+			{Name: "8.3", Image: "php:8.3-alpine"},
+		},
+		Command: func(code string, args []string) []string { return append([]string{"php", "-r", code, "--"}, args...) },
+	},
+}
+
+// command returns the command running the code with the program arguments.
+// Compiled languages compile and run in one step.
+func (l *Language) command(code string, args []string) []string {
+	if l.Build != nil {
+		return shell(l.Build.Compile(code)+" && "+l.Build.Run+` "$@"`, args)
+	}
+	return l.Command(code, args)
+}
+
+// shell returns a command running the script with the program arguments as
+// "$@", so the arguments are never interpreted by the shell
+func shell(script string, args []string) []string {
+	return append([]string{"sh", "-c", script, "sh"}, args...)
+}
+
+// LookupLanguage returns the registered language with the name or alias
+func LookupLanguage(name string) (*Language, bool) {
+	name = strings.ToLower(name)
+	for _, language := range languages {
+		if language.Name == name {
+			return language, true
+		}
+		for _, alias := range language.Aliases {
+			if alias == name {
+				return language, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// languageFor returns the language to run code with, falling back to Python
+func languageFor(name string) *Language {
+	if language, ok := LookupLanguage(name); ok {
+		return language
+	}
+	return languages[0]
+}
+
+// ResolveLanguage returns the language and version of a spec such as "python"
+// or "python@3.12". Without a version the language's default is used. Unknown
+// languages without a version fall back to Python.
+func ResolveLanguage(spec string) (*Language, Version, error) {
+	name, versionName, versioned := strings.Cut(spec, "@")
+	if !versioned {
+		language := languageFor(name)
+		return language, language.Versions[0], nil
+	}
+
+	language, ok := LookupLanguage(name)
+	if !ok {
+		return nil, Version{}, fmt.Errorf("%w: unknown language %q", ErrUnsupportedVersion, name)
+	}
+	available := make([]string, 0, len(language.Versions))
+	for _, version := range language.Versions {
+		if version.Name == versionName {
+			return language, version, nil
+		}
+		available = append(available, version.Name)
+	}
+	return nil, Version{}, fmt.Errorf("%w: %s has no version %q (available: %s)", ErrUnsupportedVersion, language.Name, versionName, strings.Join(available, ", "))
+}
+
+// ImageFor returns the Docker image code of the language spec runs in
+func ImageFor(spec string) (string, error) {
+	_, version, err := ResolveLanguage(spec)
+	if err != nil {
+		return "", err
+	}
+	return version.Image, nil
+}
@@ -4,298 +4,524 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"strings"
+	"log"
+	"strconv"
+	"sync"
 	"time"
 
+	"code-executor/internal/buildcache"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
 )
 
 // Manager handles Docker container operations
 type Manager struct {
-	client *client.Client
+	client  *client.Client
+	profile SecurityProfile
+	seccomp string // Seccomp profile JSON
+
+	runtimes           *RuntimeConfig  // Nil until runtimes are configured
+	registeredRuntimes map[string]bool // Runtimes registered with the daemon
+
+	imagesMu      sync.Mutex
+	pins          map[string]string     // Image tags pinned to digests
+	pulls         map[string]pullStatus // Last pull or build of each image tag
+	packageMirror string                // Directory image builds install packages from
+	packageCache  string                // Volume or directory dependency installs read from
+	buildCache    *buildcache.Cache     // Nil unless compile outputs are cached
+
+	fakeTime   string   // Host path of libfaketime, empty unless clocks can be faked
+	pinnedCPUs []string // CPUs deterministic runs are pinned to
+	pinnedTurn uint64   // Runs pinned so far, selects the next CPU
 }
 
-// NewManager creates a new Docker manager
-func NewManager() (*Manager, error) {
+// NewManager creates a new Docker manager applying the security profile to every container
+func NewManager(profile SecurityProfile) (*Manager, error) {
+	if err := profile.validate(); err != nil {
+		return nil, fmt.Errorf("invalid security profile: %w", err)
+	}
+	seccomp, err := profile.loadSeccomp()
+	if err != nil {
+		return nil, err
+	}
+
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
 
-	return &Manager{client: cli}, nil
+	return &Manager{
+		client:     cli,
+		profile:    profile,
+		seccomp:    seccomp,
+		pulls:      make(map[string]pullStatus),
+		pinnedCPUs: []string{"0"},
+	}, nil
 }
 
 // ExecutionResult contains the results of code execution
 type ExecutionResult struct {
-	Stdout        string
-	Stderr        string
-	ExitCode      int
-	Timeout       bool
-	MemoryUsed    int64
-	ExecutionTime time.Duration
+	Stdout             string
+	Stderr             string
+	StdoutTruncated    bool            // Stdout exceeded its limit and was cut off
+	StderrTruncated    bool            // Stderr exceeded its limit and was cut off
+	StdoutBytes        int64           // Bytes written to stdout, including those cut off
+	StderrBytes        int64           // Bytes written to stderr, including those cut off
+	Output             []OutputChunk   // Both streams in arrival order, if combined output was requested
+	Artifacts          []Artifact      // Files in /tmp matching the requested patterns
+	ArtifactsTruncated bool            // Matching files were skipped because of the artifact limits
+	Fixtures           []FixtureDigest // Integrity of the fixtures the program ran with
+	ExitCode           int
+	Timeout            bool
+	MemoryUsed         int64
+	ExecutionTime      time.Duration // Wall time of the run phase
+	CPUUserTime        time.Duration // CPU time of the run phase in user mode
+	CPUSystemTime      time.Duration // CPU time of the run phase in kernel mode
+	CPUTimeExceeded    bool          // The run used more CPU time than its limit
+	StartupTime        time.Duration // Time from creating the container, or the exec in a reused one, to starting the program
+	PullTime           time.Duration // Time spent pulling a missing image
+	Usage              []UsagePoint  // Resource usage over the run, if a usage series was requested
+	ContainerID        string
+	Runtime            string        // OCI runtime used, empty for the daemon's default
+	Version            string        // Language version the code ran with
+	ImageDigest        string        // Content digest of the image the code ran in
+	DependenciesCached bool          // The dependencies of the manifest were installed by an earlier run
+	InstallTime        time.Duration // Time spent installing dependencies, not part of the timeout
+	BuildCached        bool          // The compiled program came from the build cache
+	CompileTime        time.Duration // Time spent in a separate compile step, zero when cached
+	Determinism        *Determinism  // Settings of a deterministic run, nil otherwise
+}
+
+// CPUTime returns the CPU time of the run phase
+func (r *ExecutionResult) CPUTime() time.Duration {
+	return r.CPUUserTime + r.CPUSystemTime
 }
 
 // ExecutionConfig contains configuration for code execution
 type ExecutionConfig struct {
-	Language      string
-	Code          string
-	Input         string
-	Timeout       time.Duration
-	MemoryLimit   int64 // in bytes
-	CPULimit      float64
+	Language         string
+	Code             string
+	Input            string
+	Timeout          time.Duration // Wall time limit of the run phase
+	CPUTimeLimit     time.Duration // CPU time limit of the run phase, none if zero
+	UsageInterval    time.Duration // Interval of the usage series, none if zero, at least MinUsageInterval
+	MemoryLimit      int64         // in bytes
+	CPULimit         float64
+	StdoutLimit      int64             // Bytes of stdout kept, DefaultOutputLimit if zero
+	StderrLimit      int64             // Bytes of stderr kept, DefaultOutputLimit if zero
+	CombinedOutput   bool              // Also return both streams interleaved in arrival order
+	Trusted          bool              // Code comes from a trusted caller, selects the trusted runtime
+	Runtime          string            // OCI runtime overriding the configured selection
+	Artifacts        []string          // Glob patterns of files in /tmp returned after the run
+	ArtifactMaxFiles int               // Files returned at most, DefaultArtifactMaxFiles if zero
+	ArtifactMaxBytes int64             // Total size of returned files, DefaultArtifactMaxBytes if zero
+	Fixtures         []Fixture         // Data files placed read-only in /tmp before the run
+	Args             []string          // Program arguments, e.g. sys.argv[1:]
+	Env              map[string]string // Environment variables of the program, checked by ValidateArgs
+	Manifest         *Manifest         // Dependency manifest installed before the run, checked by ValidateManifest
+	Determinism      *Determinism      // Fixes seeds, time zone and locale, checked by ValidateDeterminism
+	ImageDigest      string            // Image of an earlier run to use instead of the version's current one
 }
 
 // Execute runs code in a secure Docker container
 func (m *Manager) Execute(ctx context.Context, config ExecutionConfig) (*ExecutionResult, error) {
-	imageName := m.getImageName(config.Language)
-	
-	// Create execution context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, config.Timeout)
-	defer cancel()
+	prepared, failed, err := m.prepare(ctx, config, 1)
+	if err != nil {
+		return nil, err
+	}
+	if failed != nil {
+		return failed, nil
+	}
+	defer m.removeContainer(prepared.id)
+
+	result, _, err := m.runProgram(ctx, prepared, config)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Benchmark runs the program of an execution up to runs times in one
+// container, so every run after the first finds it warm. The runs end at the
+// first one that fails; a failed compile step is returned as the only run.
+// MemoryUsed of each run is the peak resident memory sampled during it, as the
+// peak memory of the container carries over from earlier runs. Artifacts and
+// usage series are not collected.
+func (m *Manager) Benchmark(ctx context.Context, config ExecutionConfig, runs int) ([]*ExecutionResult, error) {
+	config.Artifacts = nil
+	config.UsageInterval = 0
+	prepared, failed, err := m.prepare(ctx, config, runs)
+	if err != nil {
+		return nil, err
+	}
+	if failed != nil {
+		return []*ExecutionResult{failed}, nil
+	}
+	defer m.removeContainer(prepared.id)
+
+	var results []*ExecutionResult
+	for i := 0; i < runs; i++ {
+		result, used, err := m.runProgram(ctx, prepared, config)
+		if err != nil {
+			return nil, err
+		}
+		result.MemoryUsed = used.rss
+		results = append(results, result)
+		// A stopped container cannot run the program again
+		if result.Timeout || result.CPUTimeExceeded || result.ExitCode != 0 {
+			break
+		}
+	}
+	return results, nil
+}
+
+// runContainer is a started container the program of an execution runs in
+type runContainer struct {
+	id         string
+	user       string
+	workingDir string
+	command    []string
+	created    time.Time       // When the container was created, startup of the first run is measured from it
+	ran        bool            // A run already started the program in the container
+	setup      ExecutionResult // Image, dependencies and build every run of the program shares
+}
+
+// prepare pulls the image, installs dependencies and compiles the code of an
+// execution, then starts an idle container the program can run in up to runs
+// times. A failed compile step is returned as the result instead.
+func (m *Manager) prepare(ctx context.Context, config ExecutionConfig, runs int) (*runContainer, *ExecutionResult, error) {
+	language, version, err := ResolveLanguage(config.Language)
+	if err != nil {
+		return nil, nil, err
+	}
+	runtime, err := m.runtimeFor(language, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Missing images are pulled first, the pull is reported apart from the run
+	var imageID, imageDigest string
+	pullStart := time.Now()
+	if config.ImageDigest != "" {
+		imageDigest = config.ImageDigest
+		imageID, err = m.resolveDigest(ctx, config.ImageDigest)
+	} else if err = m.EnsureImage(ctx, version.Image); err == nil {
+		imageID, imageDigest, err = m.resolveImage(ctx, version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pullTime := time.Since(pullStart)
+	determinism, err := m.deterministic(config.Determinism)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Install dependencies in a separate phase, the run uses the resulting image
+	var dependenciesCached bool
+	var installTime time.Duration
+	fixtures := config.Fixtures
+	if config.Manifest != nil {
+		installStart := time.Now()
+		imageID, dependenciesCached, err = m.installDependencies(ctx, language, imageID, config.Manifest)
+		if err != nil {
+			return nil, nil, err
+		}
+		installTime = time.Since(installStart)
+		// The manifest sits next to the code, go.mod marks the module root
+		fixtures = append(append([]Fixture(nil), fixtures...), Fixture{Name: config.Manifest.Name, Data: config.Manifest.Data})
+	}
+
+	// Compiled languages compile in a separate step, so the run phase only runs
+	// the compiled program
+	command := language.command(config.Code, config.Args)
+	var build *compileResult
+	if language.Build != nil {
+		build, err = m.compileCached(ctx, language, imageID, runtime, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if build.failed != nil {
+			result := build.failed
+			result.Fixtures = fixtureDigests(config.Fixtures)
+			result.Version = version.Name
+			result.ImageDigest = imageDigest
+			result.DependenciesCached = dependenciesCached
+			result.InstallTime = installTime
+			result.PullTime = pullTime
+			result.Determinism = determinism
+			return nil, result, nil
+		}
+		command = shell(language.Build.Run+` "$@"`, config.Args)
+	}
+	command = determinism.command(language, command)
 
-	// Create container configuration
+	// The container idles while files are copied in and the program runs as an
+	// exec, so the cgroup statistics of the run phase can be read after it exits
 	containerConfig := &container.Config{
-		Image:        imageName,
-		AttachStdout: true,
-		AttachStderr: true,
-		AttachStdin:  true,
-		OpenStdin:    true,
-		StdinOnce:    true,
-		Tty:          false,
+		Image:           imageID,
+		Tty:             false,
 		NetworkDisabled: true, // Disable network access
-		Cmd:          m.getCommand(config.Language, config.Code),
-		WorkingDir:   "/tmp",
+		Cmd:             []string{"sleep", strconv.Itoa(int((time.Duration(runs)*config.Timeout + idleMargin) / time.Second))},
+		Env:             append(environment(language, dependencyEnv(config.Manifest), config.Env), determinism.env(language)...),
+		WorkingDir:      "/tmp",
 	}
 
 	// Host configuration with resource limits
 	hostConfig := &container.HostConfig{
-		Memory:     config.MemoryLimit,
-		CPUQuota:   int64(config.CPULimit * 100000), // CPUQuota is in microseconds
-		CPUPeriod:  100000,
-		NetworkMode: "none", // No network access
-		ReadonlyRootfs: true, // Read-only filesystem
-		TmpfsOptions: map[string]string{
-			"/tmp": "rw,noexec,nosuid,size=100m", // Writable /tmp with limits
-		},
-		SecurityOpt: []string{
-			"no-new-privileges:true", // Prevent privilege escalation
+		Resources: container.Resources{
+			Memory:    config.MemoryLimit,
+			CPUQuota:  int64(config.CPULimit * 100000), // CPUQuota is in microseconds
+			CPUPeriod: 100000,
 		},
-		CapDrop: []string{"ALL"}, // Drop all capabilities
-		AutoRemove: true, // Auto-remove container when done
+		NetworkMode:    "none",                     // No network access
+		ReadonlyRootfs: true,                       // Read-only filesystem, only /tmp is writable
+		AutoRemove:     len(config.Artifacts) == 0, // Auto-remove container when done, unless artifacts are copied out
+		Runtime:        runtime,
+	}
+	m.applySecurityProfile(language, containerConfig, hostConfig)
+	m.applyDeterminism(determinism, hostConfig)
+	// Files are copied in and out of /tmp through the archive API
+	if len(config.Artifacts) > 0 || len(fixtures) > 0 || build != nil {
+		delete(hostConfig.Tmpfs, "/tmp")
+		hostConfig.Mounts = append(hostConfig.Mounts, m.profile.tmpVolume(language))
 	}
 
 	// Create container
-	resp, err := m.client.ContainerCreate(execCtx, containerConfig, hostConfig, nil, nil, "")
+	created := time.Now()
+	resp, err := m.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create container: %w", err)
+		return nil, nil, fmt.Errorf("failed to create container: %w", err)
 	}
-
-	// Ensure container is cleaned up
+	ready := false
 	defer func() {
-		if err := m.client.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{
-			Force: true,
-		}); err != nil {
-			// Log error but don't fail the execution
-			fmt.Printf("Warning: failed to remove container %s: %v\n", resp.ID, err)
+		if !ready {
+			m.removeContainer(resp.ID)
 		}
 	}()
 
-	// Start container
-	if err := m.client.ContainerStart(execCtx, resp.ID, container.StartOptions{}); err != nil {
-		return nil, fmt.Errorf("failed to start container: %w", err)
+	// Start the idle container first, a /tmp volume only holds files while
+	// the container runs
+	if err := m.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to start container: %w", err)
 	}
 
-	// Send input to container if provided
-	if config.Input != "" {
-		hijackedResp, err := m.client.ContainerAttach(execCtx, resp.ID, container.AttachOptions{
-			Stream: true,
-			Stdin:  true,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to attach to container: %w", err)
+	// Place fixtures before the program starts
+	if len(fixtures) > 0 {
+		if err := m.copyFixtures(ctx, resp.ID, fixtures); err != nil {
+			return nil, nil, err
 		}
-		
-		go func() {
-			defer hijackedResp.Close()
-			hijackedResp.Conn.Write([]byte(config.Input))
-			hijackedResp.CloseWrite()
-		}()
 	}
 
-	// Wait for container to finish
-	start := time.Now()
-	statusCh, errCh := m.client.ContainerWait(execCtx, resp.ID, container.WaitConditionNotRunning)
-	
-	var exitCode int64
-	var timeout bool
-	
-	select {
-	case err := <-errCh:
-		if err != nil {
-			return nil, fmt.Errorf("container wait error: %w", err)
+	if build != nil {
+		if err := m.copyBuild(ctx, resp.ID, build.archive); err != nil {
+			return nil, nil, err
 		}
-	case result := <-statusCh:
-		exitCode = result.StatusCode
-	case <-execCtx.Done():
-		timeout = true
-		// Force kill the container
-		m.client.ContainerKill(context.Background(), resp.ID, "SIGKILL")
 	}
+	ready = true
 
-	executionTime := time.Since(start)
+	return &runContainer{
+		id:         resp.ID,
+		user:       containerConfig.User,
+		workingDir: containerConfig.WorkingDir,
+		command:    command,
+		created:    created,
+		setup: ExecutionResult{
+			Fixtures:           fixtureDigests(config.Fixtures),
+			PullTime:           pullTime,
+			ContainerID:        resp.ID,
+			Runtime:            runtime,
+			Version:            version.Name,
+			ImageDigest:        imageDigest,
+			DependenciesCached: dependenciesCached,
+			InstallTime:        installTime,
+			BuildCached:        build != nil && build.cached,
+			CompileTime:        compileTime(build),
+			Determinism:        determinism,
+		},
+	}, nil, nil
+}
 
-	// Get container logs
-	logs, err := m.client.ContainerLogs(context.Background(), resp.ID, container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
+// removeContainer removes a container with its /tmp volume. Containers
+// removed by AutoRemove already are gone.
+func (m *Manager) removeContainer(containerID string) {
+	err := m.client.ContainerRemove(context.Background(), containerID, container.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: true, // Including the /tmp volume of runs copying files in or out
 	})
+	if err != nil && !errdefs.IsNotFound(err) {
+		// Log error but don't fail the execution
+		log.Printf("Failed to remove container %s: %v", containerID, err)
+	}
+}
+
+// runProgram runs the program once in a prepared container and returns its
+// result with the usage of the run and the requested artifacts. A run that
+// does not exit on its own stops the container.
+func (m *Manager) runProgram(ctx context.Context, prepared *runContainer, config ExecutionConfig) (*ExecutionResult, usage, error) {
+	var err error
+	run := &runUsage{}
+	run.baseline, err = m.containerUsage(ctx, prepared.id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get container logs: %w", err)
+		return nil, usage{}, err
+	}
+	if config.UsageInterval > 0 {
+		run.series = &usageSeries{interval: usageInterval(config.UsageInterval)}
 	}
-	defer logs.Close()
 
-	// Read stdout and stderr
-	stdout, stderr, err := m.parseLogs(logs)
+	// Later runs in a reused container only wait for their exec to start
+	ready := prepared.created
+	if prepared.ran {
+		ready = time.Now()
+	}
+	prepared.ran = true
+
+	// Create execution context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	exec, err := m.client.ContainerExecCreate(execCtx, prepared.id, container.ExecOptions{
+		User:         prepared.user,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		WorkingDir:   prepared.workingDir,
+		Cmd:          prepared.command,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse logs: %w", err)
-	}
-
-	// Get memory usage statistics
-	stats, err := m.client.ContainerStats(context.Background(), resp.ID, false)
-	var memoryUsed int64
-	if err == nil {
-		defer stats.Body.Close()
-		// Note: In a real implementation, you'd parse the JSON stats
-		// For now, we'll set a placeholder value
-		memoryUsed = 0
-	}
-
-	return &ExecutionResult{
-		Stdout:        stdout,
-		Stderr:        stderr,
-		ExitCode:      int(exitCode),
-		Timeout:       timeout,
-		MemoryUsed:    memoryUsed,
-		ExecutionTime: executionTime,
-	}, nil
-}
+		return nil, usage{}, fmt.Errorf("failed to create exec: %w", err)
+	}
 
-// getImageName returns the appropriate Docker image for the language
-func (m *Manager) getImageName(language string) string {
-	switch strings.ToLower(language) {
-	case "python", "python3":
-		return "python:3.11-alpine"
-	case "javascript", "js", "node":
-		return "node:18-alpine"
-	case "go", "golang":
-		return "golang:1.21-alpine"
-	case "java":
-		return "openjdk:11-alpine"
-	case "c":
-		return "gcc:alpine"
-	case "cpp", "c++":
-		return "gcc:alpine"
-	case "rust":
-		return "rust:alpine"
-	case "ruby":
-		return "ruby:3.2-alpine"
-	case "php":
-		return "php:8.2-alpine"
-	default:
-		return "python:3.11-alpine" // Default fallback
+	// Attaching starts the program, stdin reaches it through the connection
+	startupTime := time.Since(ready)
+	start := time.Now()
+	run.start = start
+	hijackedResp, err := m.client.ContainerExecAttach(execCtx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, usage{}, fmt.Errorf("failed to start program: %w", err)
 	}
-}
+	defer hijackedResp.Close()
+
+	// Send input, closing stdin so programs reading it see EOF
+	go func() {
+		hijackedResp.Conn.Write([]byte(config.Input))
+		hijackedResp.CloseWrite()
+	}()
 
-// getCommand returns the appropriate command to execute code for the language
-func (m *Manager) getCommand(language, code string) []string {
-	switch strings.ToLower(language) {
-	case "python", "python3":
-		return []string{"python3", "-c", code}
-	case "javascript", "js", "node":
-		return []string{"node", "-e", code}
-	case "go", "golang":
-		return []string{"sh", "-c", fmt.Sprintf("echo '%s' > main.go && go run main.go", code)}
-	case "java":
-		return []string{"sh", "-c", fmt.Sprintf("echo '%s' > Main.java && javac Main.java && java Main", code)}
-	case "c":
-		return []string{"sh", "-c", fmt.Sprintf("echo '%s' > main.c && gcc main.c -o main && ./main", code)}
-	case "cpp", "c++":
-		return []string{"sh", "-c", fmt.Sprintf("echo '%s' > main.cpp && g++ main.cpp -o main && ./main", code)}
-	case "rust":
-		return []string{"sh", "-c", fmt.Sprintf("echo '%s' > main.rs && rustc main.rs && ./main", code)}
-	case "ruby":
-		return []string{"ruby", "-e", code}
-	case "php":
-		return []string{"php", "-r", code}
-	default:
-		return []string{"python3", "-c", code}
+	// Read output while the program runs, flagging when a stream exceeds its limit
+	overflow := make(chan struct{})
+	var overflowOnce sync.Once
+	onOverflow := func() { overflowOnce.Do(func() { close(overflow) }) }
+	stdout := newLimitedBuffer(outputLimit(config.StdoutLimit), onOverflow)
+	stderr := newLimitedBuffer(outputLimit(config.StderrLimit), onOverflow)
+	var stdoutWriter, stderrWriter io.Writer = stdout, stderr
+	var combined *combinedOutput
+	if config.CombinedOutput {
+		combined = newCombinedOutput(stdout.limit + stderr.limit)
+		stdoutWriter = combined.tee(StreamStdout, stdout)
+		stderrWriter = combined.tee(StreamStderr, stderr)
 	}
-}
+	outputDone := make(chan error, 1)
+	go func() {
+		outputDone <- demultiplex(hijackedResp.Reader, stdoutWriter, stderrWriter)
+	}()
+
+	// Sample usage while the program runs, stopping it once it exceeds its CPU time limit
+	cpuExceeded := make(chan struct{})
+	watchCtx, stopWatch := context.WithCancel(execCtx)
+	defer stopWatch()
+	go m.watchUsage(watchCtx, prepared.id, run, config.CPUTimeLimit, func() { close(cpuExceeded) })
+
+	// Wait for the program to finish
+	var exitCode int
+	var timeout, cpuTimeExceeded, exited bool
 
-// parseLogs separates stdout and stderr from Docker logs
-func (m *Manager) parseLogs(logs io.Reader) (string, string, error) {
-	var stdout, stderr strings.Builder
-	
-	// Docker logs format: 8-byte header + payload
-	// Header: [STREAM_TYPE, 0, 0, 0, SIZE1, SIZE2, SIZE3, SIZE4]
-	// STREAM_TYPE: 0=stdin, 1=stdout, 2=stderr
-	
-	buffer := make([]byte, 8)
-	for {
-		n, err := logs.Read(buffer)
+	select {
+	case err := <-outputDone:
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", "", err
+			return nil, usage{}, fmt.Errorf("failed to read output: %w", err)
 		}
-		
-		if n < 8 {
-			break
+		exited = true
+	case <-overflow:
+		// Stop programs flooding their output instead of buffering all of it
+		exitCode = 137
+	case <-cpuExceeded:
+		cpuTimeExceeded = true
+		exitCode = 137
+	case <-execCtx.Done():
+		timeout = true
+	}
+
+	executionTime := time.Since(start)
+
+	// The output ends when the program exits, or when it closes its output and
+	// runs on until the timeout
+	if exited {
+		exitCode, err = m.execExitCode(execCtx, exec.ID)
+		if err != nil && execCtx.Err() == nil {
+			return nil, usage{}, err
 		}
-		
-		streamType := buffer[0]
-		size := uint32(buffer[4])<<24 | uint32(buffer[5])<<16 | uint32(buffer[6])<<8 | uint32(buffer[7])
-		
-		payload := make([]byte, size)
-		n, err = logs.Read(payload)
-		if err != nil && err != io.EOF {
-			return "", "", err
+		if err != nil {
+			timeout = true
+			executionTime = time.Since(start)
 		}
-		
-		switch streamType {
-		case 1: // stdout
-			stdout.Write(payload[:n])
-		case 2: // stderr
-			stderr.Write(payload[:n])
+	}
+
+	// Read the final usage while the container still runs, a stopped
+	// container has no statistics
+	stopWatch()
+	if sample, err := m.containerUsage(ctx, prepared.id); err == nil {
+		run.record(sample, time.Now(), true)
+	}
+	used := run.result()
+	if config.CPUTimeLimit > 0 && used.cpu() > config.CPUTimeLimit {
+		cpuTimeExceeded = true
+	}
+
+	// Copy out the files the program left behind while the container runs,
+	// a stopped container's /tmp volume is no longer mounted
+	var artifacts []Artifact
+	var artifactsTruncated bool
+	if len(config.Artifacts) > 0 {
+		artifacts, artifactsTruncated, err = m.collectArtifacts(ctx, prepared.id, config)
+		if err != nil {
+			return nil, usage{}, err
+		}
+	}
+
+	if !exited || timeout {
+		// Force kill the container, the output stream ends once it has stopped
+		m.client.ContainerKill(context.Background(), prepared.id, "SIGKILL")
+		if !exited {
+			<-outputDone
 		}
 	}
-	
-	return stdout.String(), stderr.String(), nil
+
+	result := prepared.setup
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.StdoutTruncated = stdout.Truncated()
+	result.StderrTruncated = stderr.Truncated()
+	result.StdoutBytes = stdout.total
+	result.StderrBytes = stderr.total
+	result.Output = combinedChunks(combined)
+	result.ExitCode = exitCode
+	result.Timeout = timeout
+	result.MemoryUsed = used.memory
+	result.ExecutionTime = executionTime
+	result.CPUUserTime = used.user
+	result.CPUSystemTime = used.system
+	result.CPUTimeExceeded = cpuTimeExceeded
+	result.Usage = run.points()
+	result.StartupTime = startupTime
+	result.Artifacts = artifacts
+	result.ArtifactsTruncated = artifactsTruncated
+	return &result, used, nil
 }
 
 // Close closes the Docker client
 func (m *Manager) Close() error {
 	return m.client.Close()
 }
-
-// EnsureImage ensures the Docker image is available
-func (m *Manager) EnsureImage(ctx context.Context, imageName string) error {
-	_, _, err := m.client.ImageInspectWithRaw(ctx, imageName)
-	if err != nil {
-		// Image doesn't exist, pull it
-		reader, err := m.client.ImagePull(ctx, imageName, image.PullOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to pull image %s: %w", imageName, err)
-		}
-		defer reader.Close()
-		
-		// Read the response to complete the pull
-		io.Copy(io.Discard, reader)
-	}
-	
-	return nil
-}
@@ -0,0 +1,166 @@
+package docker
+
+import (
+	"bytes"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultOutputLimit is the number of bytes kept per output stream unless configured
+const DefaultOutputLimit = 1024 * 1024
+
+// outputLimit returns the configured limit of a stream or the default
+func outputLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultOutputLimit
+	}
+	return limit
+}
+
+// limitedBuffer keeps the first limit bytes written to it and counts the rest
+type limitedBuffer struct {
+	buf        bytes.Buffer
+	limit      int64
+	total      int64  // Bytes written, including those dropped
+	onOverflow func() // Called once when the limit is exceeded
+}
+
+// newLimitedBuffer creates a buffer keeping up to limit bytes
+func newLimitedBuffer(limit int64, onOverflow func()) *limitedBuffer {
+	return &limitedBuffer{limit: limit, onOverflow: onOverflow}
+}
+
+// Write keeps what fits below the limit and never fails, so the stream is drained
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+
+	if room := b.limit - int64(b.buf.Len()); room > 0 {
+		if int64(len(p)) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+
+	if b.Truncated() && b.onOverflow != nil {
+		b.onOverflow()
+		b.onOverflow = nil
+	}
+	return len(p), nil
+}
+
+// Truncated reports whether output was dropped
+func (b *limitedBuffer) Truncated() bool {
+	return b.total > b.limit
+}
+
+// String returns the kept output. Truncated output is cut back to the last
+// complete UTF-8 character, so it does not end in a broken one.
+func (b *limitedBuffer) String() string {
+	data := b.buf.Bytes()
+	if b.Truncated() {
+		for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+			if utf8.RuneStart(data[len(data)-i]) {
+				if !utf8.FullRune(data[len(data)-i:]) {
+					data = data[:len(data)-i]
+				}
+				break
+			}
+		}
+	}
+	return string(data)
+}
+
+// Output stream names
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputChunk is a piece of output of one stream. Chunks are kept in the
+// order they arrived, which interleaves stdout and stderr like a terminal.
+type OutputChunk struct {
+	Stream string
+	Data   string
+	Offset time.Duration // Time since the container was started
+}
+
+// combinedOutput records the output of both streams in arrival order
+type combinedOutput struct {
+	start   time.Time
+	limit   int64 // Bytes kept across all chunks
+	size    int64
+	chunks  []OutputChunk
+	pending map[string][]byte // Start of a UTF-8 character split between writes
+}
+
+// newCombinedOutput creates a combined output keeping up to limit bytes
+func newCombinedOutput(limit int64) *combinedOutput {
+	return &combinedOutput{
+		start:   time.Now(),
+		limit:   limit,
+		pending: make(map[string][]byte),
+	}
+}
+
+// tee returns a writer recording its writes as chunks of the stream before passing them on
+func (c *combinedOutput) tee(stream string, next io.Writer) io.Writer {
+	return &chunkWriter{stream: stream, output: c, next: next}
+}
+
+// add records a write of the stream as a chunk
+func (c *combinedOutput) add(stream string, p []byte) {
+	if c.size >= c.limit {
+		return
+	}
+
+	data := append(c.pending[stream], p...)
+	c.pending[stream] = nil
+
+	// Hold back a character cut off at the end until the next write completes it
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				c.pending[stream] = append([]byte(nil), data[len(data)-i:]...)
+				data = data[:len(data)-i]
+			}
+			break
+		}
+	}
+
+	if room := c.limit - c.size; int64(len(data)) > room {
+		data = data[:room]
+	}
+	if len(data) == 0 {
+		return
+	}
+
+	c.size += int64(len(data))
+	c.chunks = append(c.chunks, OutputChunk{
+		Stream: stream,
+		Data:   string(data),
+		Offset: time.Since(c.start),
+	})
+}
+
+// chunkWriter records writes of one stream in the combined output
+type chunkWriter struct {
+	stream string
+	output *combinedOutput
+	next   io.Writer
+}
+
+// Write records the chunk and passes it on
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.output.add(w.stream, p)
+	return w.next.Write(p)
+}
+
+// combinedChunks returns the recorded chunks, nil without combined output
+func combinedChunks(c *combinedOutput) []OutputChunk {
+	if c == nil {
+		return nil
+	}
+	return c.chunks
+}
@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Fallback policies for runtimes that are not registered with the Docker daemon
+const (
+	FallbackFail    = "fail"    // Refuse to start
+	FallbackDefault = "default" // Run with the daemon's default runtime instead
+	FallbackReject  = "reject"  // Start, but reject executions needing the runtime
+)
+
+// ErrRuntimeUnavailable is returned when an execution needs a runtime the daemon does not have
+var ErrRuntimeUnavailable = errors.New("runtime unavailable")
+
+// RuntimeSelection names the OCI runtime per trust level, empty for the default
+type RuntimeSelection struct {
+	Trusted   string `json:"trusted"`   // Code of trusted callers such as instructors
+	Untrusted string `json:"untrusted"` // Everything else, e.g. public course submissions
+}
+
+// RuntimeConfig selects the OCI runtime (runc, runsc, kata-runtime, ...) executions use
+type RuntimeConfig struct {
+	Default   string                      `json:"default"`   // Runtime when nothing else applies, empty for the daemon's default
+	Trust     RuntimeSelection            `json:"trust"`     // Runtime per trust level
+	Languages map[string]RuntimeSelection `json:"languages"` // Runtime per language and trust level, overriding trust
+	Fallback  string                      `json:"fallback"`  // Policy for unregistered runtimes, "fail" by default
+}
+
+// LoadRuntimeConfig reads a runtime configuration from a JSON file
+func LoadRuntimeConfig(path string) (*RuntimeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runtime config: %w", err)
+	}
+
+	var config RuntimeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse runtime config: %w", err)
+	}
+
+	switch config.Fallback {
+	case "":
+		config.Fallback = FallbackFail
+	case FallbackFail, FallbackDefault, FallbackReject:
+	default:
+		return nil, fmt.Errorf("unknown fallback policy %q", config.Fallback)
+	}
+
+	// Key languages by their registry name, so aliases work in the file
+	languages := make(map[string]RuntimeSelection, len(config.Languages))
+	for name, selection := range config.Languages {
+		language, ok := LookupLanguage(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown language %q", name)
+		}
+		languages[language.Name] = selection
+	}
+	config.Languages = languages
+
+	return &config, nil
+}
+
+// runtimes returns every runtime named in the configuration
+func (c RuntimeConfig) runtimes() []string {
+	seen := make(map[string]bool)
+	add := func(selection RuntimeSelection) {
+		seen[selection.Trusted] = true
+		seen[selection.Untrusted] = true
+	}
+	seen[c.Default] = true
+	add(c.Trust)
+	for _, selection := range c.Languages {
+		add(selection)
+	}
+	delete(seen, "")
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pick returns the runtime of the selection for a trust level
+func (s RuntimeSelection) pick(trusted bool) string {
+	if trusted {
+		return s.Trusted
+	}
+	return s.Untrusted
+}
+
+// ConfigureRuntimes checks the configured runtimes against those registered
+// with the daemon and applies the fallback policy to missing ones
+func (m *Manager) ConfigureRuntimes(ctx context.Context, config RuntimeConfig) error {
+	if config.Fallback == "" {
+		config.Fallback = FallbackFail
+	}
+
+	info, err := m.client.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get Docker daemon info: %w", err)
+	}
+	registered := make(map[string]bool, len(info.Runtimes))
+	available := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		registered[name] = true
+		available = append(available, name)
+	}
+	sort.Strings(available)
+
+	for _, name := range config.runtimes() {
+		if registered[name] {
+			continue
+		}
+		switch config.Fallback {
+		case FallbackFail:
+			return fmt.Errorf("runtime %q is not registered with the Docker daemon (registered: %s)", name, strings.Join(available, ", "))
+		case FallbackDefault:
+			log.Printf("WARNING: runtime %q is not registered with the Docker daemon, using the default runtime %q instead", name, info.DefaultRuntime)
+		case FallbackReject:
+			log.Printf("WARNING: runtime %q is not registered with the Docker daemon, executions needing it are rejected", name)
+		}
+	}
+
+	m.runtimes = &config
+	m.registeredRuntimes = registered
+	return nil
+}
+
+// runtimeFor returns the runtime an execution runs with, empty for the daemon's default
+func (m *Manager) runtimeFor(language *Language, config ExecutionConfig) (string, error) {
+	name := config.Runtime
+	if name == "" && m.runtimes != nil {
+		name = m.runtimes.Languages[language.Name].pick(config.Trusted)
+		if name == "" {
+			name = m.runtimes.Trust.pick(config.Trusted)
+		}
+		if name == "" {
+			name = m.runtimes.Default
+		}
+	}
+
+	if name == "" || m.registeredRuntimes == nil || m.registeredRuntimes[name] {
+		return name, nil
+	}
+	if m.runtimes.Fallback == FallbackDefault {
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: %s is not registered with the Docker daemon", ErrRuntimeUnavailable, name)
+}
@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-units"
+)
+
+// bundledSeccomp is the default seccomp profile. It only allows the system
+// calls interpreters and compilers need, and clone without new namespaces.
+//
+//go:embed seccomp.json
+var bundledSeccomp []byte
+
+// userPattern matches a numeric uid:gid pair
+var userPattern = regexp.MustCompile(`^([0-9]+):([0-9]+)$`)
+
+// SecurityProfile contains the hardening applied to every execution container
+type SecurityProfile struct {
+	User        string         `json:"user"`          // Numeric uid:gid code runs as
+	PidsLimit   int64          `json:"pids_limit"`    // Processes and threads per container
+	NoFile      int64          `json:"nofile"`        // Open files per process
+	FileSizeMB  int64          `json:"file_size_mb"`  // Largest file a process may write
+	SeccompFile string         `json:"seccomp_file"`  // Seccomp profile replacing the bundled one
+	MaskedPaths []string       `json:"masked_paths"`  // Paths hidden from the container
+	TmpfsSizeMB map[string]int `json:"tmpfs_size_mb"` // Size of /tmp per language, "default" for the rest
+}
+
+// DefaultSecurityProfile returns the profile used without configuration
+func DefaultSecurityProfile() SecurityProfile {
+	return SecurityProfile{
+		User:       "65534:65534",
+		PidsLimit:  64,
+		NoFile:     256,
+		FileSizeMB: 64,
+		MaskedPaths: []string{
+			// Docker's defaults, replaced when masked paths are set
+			"/proc/acpi",
+			"/proc/asound",
+			"/proc/kcore",
+			"/proc/keys",
+			"/proc/latency_stats",
+			"/proc/sched_debug",
+			"/proc/scsi",
+			"/proc/timer_list",
+			"/proc/timer_stats",
+			"/sys/firmware",
+			"/sys/devices/virtual/powercap",
+			// Kernel layout and host details
+			"/proc/kallsyms",
+			"/proc/modules",
+			"/proc/interrupts",
+			"/proc/diskstats",
+		},
+		TmpfsSizeMB: map[string]int{
+			"default": 64,
+			"go":      256,
+			"rust":    256,
+			"c":       128,
+			"cpp":     128,
+			"java":    128,
+		},
+	}
+}
+
+// LoadSecurityProfile reads a profile from a JSON file. Fields missing from
+// the file keep their defaults.
+func LoadSecurityProfile(path string) (*SecurityProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read security profile: %w", err)
+	}
+
+	profile := DefaultSecurityProfile()
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse security profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// validate checks that the profile does not weaken isolation by accident
+func (p SecurityProfile) validate() error {
+	match := userPattern.FindStringSubmatch(p.User)
+	if match == nil {
+		return fmt.Errorf("user must be a numeric uid:gid, got %q", p.User)
+	}
+	if uid, _ := strconv.Atoi(match[1]); uid == 0 {
+		return fmt.Errorf("code must not run as root")
+	}
+	if gid, _ := strconv.Atoi(match[2]); gid == 0 {
+		return fmt.Errorf("code must not run in the root group")
+	}
+	if p.PidsLimit <= 0 {
+		return fmt.Errorf("pids_limit must be positive")
+	}
+	if p.NoFile <= 0 || p.FileSizeMB <= 0 {
+		return fmt.Errorf("nofile and file_size_mb must be positive")
+	}
+	if p.TmpfsSizeMB["default"] <= 0 {
+		return fmt.Errorf("tmpfs_size_mb must contain a positive default")
+	}
+	return nil
+}
+
+// loadSeccomp returns the seccomp profile as compact JSON for the security options
+func (p SecurityProfile) loadSeccomp() (string, error) {
+	data := bundledSeccomp
+	if p.SeccompFile != "" {
+		var err error
+		data, err = os.ReadFile(p.SeccompFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read seccomp profile: %w", err)
+		}
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return "", fmt.Errorf("failed to parse seccomp profile: %w", err)
+	}
+	return compact.String(), nil
+}
+
+// tmpfsFor returns the /tmp mount options for a language
+func (p SecurityProfile) tmpfsFor(language *Language) string {
+	size, ok := p.TmpfsSizeMB[language.Name]
+	if !ok {
+		size = p.TmpfsSizeMB["default"]
+	}
+
+	options := "rw,nosuid,nodev,mode=1777"
+	if !language.Compiled {
+		options += ",noexec"
+	}
+	return fmt.Sprintf("%s,size=%dm", options, size)
+}
+
+// tmpVolume returns the /tmp mount of runs copying files in or out. The archive
+// API cannot reach tmpfs mounts, so /tmp is an anonymous volume removed with the
+// container instead. The local driver mounts it as a tmpfs on the host with the
+// same options, keeping its size limit and noexec.
+func (p SecurityProfile) tmpVolume(language *Language) mount.Mount {
+	return mount.Mount{
+		Type:   mount.TypeVolume,
+		Target: "/tmp",
+		VolumeOptions: &mount.VolumeOptions{
+			DriverConfig: &mount.Driver{
+				Name: "local",
+				Options: map[string]string{
+					"type":   "tmpfs",
+					"device": "tmpfs",
+					"o":      p.tmpfsFor(language),
+				},
+			},
+		},
+	}
+}
+
+// ulimits returns the resource limits of processes in the container
+func (p SecurityProfile) ulimits() []*units.Ulimit {
+	fileSize := p.FileSizeMB * 1024 * 1024
+	return []*units.Ulimit{
+		{Name: "nofile", Soft: p.NoFile, Hard: p.NoFile},
+		{Name: "fsize", Soft: fileSize, Hard: fileSize},
+		{Name: "core", Soft: 0, Hard: 0},
+	}
+}
+
+// applySecurityProfile hardens the container and host configuration for a language
+func (m *Manager) applySecurityProfile(language *Language, config *container.Config, hostConfig *container.HostConfig) {
+	p := m.profile
+
+	config.User = p.User
+
+	pidsLimit := p.PidsLimit
+	hostConfig.PidsLimit = &pidsLimit
+	hostConfig.Ulimits = p.ulimits()
+	hostConfig.MaskedPaths = p.MaskedPaths
+	hostConfig.Tmpfs = map[string]string{"/tmp": p.tmpfsFor(language)}
+	hostConfig.SecurityOpt = []string{
+		"no-new-privileges:true", // Prevent privilege escalation
+		"seccomp=" + m.seccomp,
+	}
+	hostConfig.CapDrop = []string{"ALL"}
+}
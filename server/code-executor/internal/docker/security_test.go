@@ -0,0 +1,451 @@
+package docker
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestApplySecurityProfile(t *testing.T) {
+	profile := DefaultSecurityProfile()
+	m := &Manager{profile: profile, seccomp: `{"defaultAction":"SCMP_ACT_ERRNO"}`}
+
+	tests := []struct {
+		language   string
+		tmpfsSize  string
+		executable bool
+	}{
+		{language: "python", tmpfsSize: "size=64m"},
+		{language: "javascript", tmpfsSize: "size=64m"},
+		{language: "go", tmpfsSize: "size=256m", executable: true},
+		{language: "rust", tmpfsSize: "size=256m", executable: true},
+		{language: "java", tmpfsSize: "size=128m"}, // Runs class files, not a binary
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			language, ok := LookupLanguage(tt.language)
+			if !ok {
+				t.Fatalf("unknown language %q", tt.language)
+			}
+			config := &container.Config{}
+			hostConfig := &container.HostConfig{}
+			m.applySecurityProfile(language, config, hostConfig)
+
+			if config.User != "65534:65534" {
+				t.Errorf("User = %q, want 65534:65534", config.User)
+			}
+			if hostConfig.PidsLimit == nil || *hostConfig.PidsLimit != profile.PidsLimit {
+				t.Errorf("PidsLimit = %v, want %d", hostConfig.PidsLimit, profile.PidsLimit)
+			}
+			if !slices.Equal(hostConfig.CapDrop, []string{"ALL"}) {
+				t.Errorf("CapDrop = %v, want [ALL]", hostConfig.CapDrop)
+			}
+			if !slices.Contains(hostConfig.SecurityOpt, "no-new-privileges:true") {
+				t.Errorf("SecurityOpt = %v, want no-new-privileges", hostConfig.SecurityOpt)
+			}
+			if !slices.Contains(hostConfig.SecurityOpt, "seccomp="+m.seccomp) {
+				t.Errorf("SecurityOpt = %v, want the seccomp profile", hostConfig.SecurityOpt)
+			}
+			for _, path := range []string{"/proc/kcore", "/proc/kallsyms", "/proc/keys"} {
+				if !slices.Contains(hostConfig.MaskedPaths, path) {
+					t.Errorf("MaskedPaths does not contain %s", path)
+				}
+			}
+
+			options := strings.Split(hostConfig.Tmpfs["/tmp"], ",")
+			if !slices.Contains(options, tt.tmpfsSize) {
+				t.Errorf("/tmp options %v, want %s", options, tt.tmpfsSize)
+			}
+			if noexec := slices.Contains(options, "noexec"); noexec == tt.executable {
+				t.Errorf("/tmp options %v, noexec = %v", options, noexec)
+			}
+			for _, option := range []string{"nosuid", "nodev"} {
+				if !slices.Contains(options, option) {
+					t.Errorf("/tmp options %v, want %s", options, option)
+				}
+			}
+		})
+	}
+}
+
+func TestTmpVolume(t *testing.T) {
+	profile := DefaultSecurityProfile()
+
+	tests := []struct {
+		language   string
+		tmpfsSize  string
+		executable bool
+	}{
+		{language: "python", tmpfsSize: "size=64m"},
+		{language: "go", tmpfsSize: "size=256m", executable: true},
+		{language: "rust", tmpfsSize: "size=256m", executable: true},
+		{language: "c", tmpfsSize: "size=128m", executable: true},
+		{language: "java", tmpfsSize: "size=128m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			language, ok := LookupLanguage(tt.language)
+			if !ok {
+				t.Fatalf("unknown language %q", tt.language)
+			}
+			volume := profile.tmpVolume(language)
+
+			if volume.Target != "/tmp" || volume.Source != "" {
+				t.Fatalf("mount %s -> %s, want an anonymous volume at /tmp", volume.Source, volume.Target)
+			}
+			if volume.VolumeOptions == nil || volume.VolumeOptions.DriverConfig == nil {
+				t.Fatal("volume has no driver options, /tmp would be unbounded")
+			}
+			driver := volume.VolumeOptions.DriverConfig
+			if driver.Name != "local" || driver.Options["type"] != "tmpfs" {
+				t.Errorf("driver %s with type %q, want a local tmpfs", driver.Name, driver.Options["type"])
+			}
+			options := strings.Split(driver.Options["o"], ",")
+			if !slices.Contains(options, tt.tmpfsSize) {
+				t.Errorf("/tmp options %v, want %s", options, tt.tmpfsSize)
+			}
+			if noexec := slices.Contains(options, "noexec"); noexec == tt.executable {
+				t.Errorf("/tmp options %v, noexec = %v", options, noexec)
+			}
+		})
+	}
+}
+
+func TestSecurityProfileUlimits(t *testing.T) {
+	profile := DefaultSecurityProfile()
+	want := map[string]int64{
+		"nofile": profile.NoFile,
+		"fsize":  profile.FileSizeMB * 1024 * 1024,
+		"core":   0,
+	}
+	ulimits := profile.ulimits()
+	if len(ulimits) != len(want) {
+		t.Fatalf("got %d ulimits, want %d", len(ulimits), len(want))
+	}
+	for _, ulimit := range ulimits {
+		limit, ok := want[ulimit.Name]
+		if !ok {
+			t.Errorf("unexpected ulimit %s", ulimit.Name)
+			continue
+		}
+		if ulimit.Soft != limit || ulimit.Hard != limit {
+			t.Errorf("ulimit %s = %d/%d, want %d", ulimit.Name, ulimit.Soft, ulimit.Hard, limit)
+		}
+	}
+}
+
+func TestSecurityProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*SecurityProfile)
+		wantErr bool
+	}{
+		{name: "default", modify: func(*SecurityProfile) {}},
+		{name: "root user", modify: func(p *SecurityProfile) { p.User = "0:65534" }, wantErr: true},
+		{name: "root group", modify: func(p *SecurityProfile) { p.User = "65534:0" }, wantErr: true},
+		{name: "user name", modify: func(p *SecurityProfile) { p.User = "nobody" }, wantErr: true},
+		{name: "no pids limit", modify: func(p *SecurityProfile) { p.PidsLimit = 0 }, wantErr: true},
+		{name: "no open file limit", modify: func(p *SecurityProfile) { p.NoFile = 0 }, wantErr: true},
+		{name: "no file size limit", modify: func(p *SecurityProfile) { p.FileSizeMB = 0 }, wantErr: true},
+		{name: "no default tmpfs size", modify: func(p *SecurityProfile) { delete(p.TmpfsSizeMB, "default") }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := DefaultSecurityProfile()
+			tt.modify(&profile)
+			if err := profile.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// abuseCase is a snippet trying to escape or exhaust the sandbox. It prints
+// OK when the sandbox held and FAIL when the abuse worked.
+type abuseCase struct {
+	name   string
+	code   string
+	killed bool // The sandbox is expected to kill the snippet instead
+}
+
+// abuseCases are the escape and abuse snippets every security profile must withstand
+var abuseCases = []abuseCase{
+	{
+		name: "runs as non-root",
+		code: `import os
+print("OK" if os.getuid() != 0 and os.getgid() != 0 else "FAIL", os.getuid(), os.getgid())`,
+	},
+	{
+		name: "fork bomb hits the pids limit",
+		code: `import os, time
+forked = 0
+try:
+    for _ in range(500):
+        if os.fork() == 0:
+            time.sleep(10)
+            os._exit(0)
+        forked += 1
+except OSError:
+    pass
+print("OK" if forked < 500 else "FAIL", forked)`,
+	},
+	{
+		name: "cannot regain root",
+		code: `import os
+try:
+    os.setuid(0)
+    print("FAIL")
+except PermissionError:
+    print("OK")`,
+	},
+	{
+		name: "cannot create namespaces",
+		code: `import ctypes
+libc = ctypes.CDLL(None, use_errno=True)
+CLONE_NEWUSER, CLONE_NEWNS = 0x10000000, 0x00020000
+print("OK" if libc.unshare(CLONE_NEWUSER) != 0 and libc.unshare(CLONE_NEWNS) != 0 else "FAIL")`,
+	},
+	{
+		name: "cannot mount",
+		code: `import ctypes
+libc = ctypes.CDLL(None, use_errno=True)
+print("OK" if libc.mount(b"none", b"/tmp", b"proc", 0, None) != 0 else "FAIL")`,
+	},
+	{
+		name: "cannot ptrace",
+		code: `import ctypes
+libc = ctypes.CDLL(None, use_errno=True)
+PTRACE_TRACEME = 0
+print("OK" if libc.ptrace(PTRACE_TRACEME, 0, None, None) != 0 else "FAIL")`,
+	},
+	{
+		name: "has no network",
+		code: `import socket
+try:
+    socket.create_connection(("1.1.1.1", 53), timeout=2)
+    print("FAIL")
+except OSError:
+    print("OK")`,
+	},
+	{
+		name: "root filesystem is read-only",
+		code: `try:
+    open("/etc/escape", "w").write("x")
+    print("FAIL")
+except OSError:
+    print("OK")`,
+	},
+	{
+		name: "kernel details are masked",
+		code: `leaked = []
+for path in ["/proc/kcore", "/proc/kallsyms", "/proc/keys", "/proc/timer_list"]:
+    try:
+        if open(path, "rb").read(64):
+            leaked.append(path)
+    except OSError:
+        pass
+print("FAIL" if leaked else "OK", leaked)`,
+	},
+	{
+		name: "tmp cannot be filled",
+		code: `chunk = b"x" * (1024 * 1024)
+written = 0
+try:
+    with open("/tmp/fill", "wb") as f:
+        for _ in range(1024):
+            f.write(chunk)
+            f.flush()
+            written += 1
+except OSError:
+    pass
+print("OK" if written < 1024 else "FAIL", written)`,
+	},
+	{
+		name: "tmp is not executable for interpreted languages",
+		code: `import os, shutil
+shutil.copy("/bin/busybox", "/tmp/sh")
+os.chmod("/tmp/sh", 0o755)
+try:
+    os.execv("/tmp/sh", ["sh", "-c", "echo FAIL"])
+except PermissionError:
+    print("OK")`,
+	},
+	{
+		name: "open files are limited",
+		code: `files = []
+try:
+    for i in range(4096):
+        files.append(open("/dev/null"))
+except OSError:
+    pass
+print("OK" if len(files) < 4096 else "FAIL", len(files))`,
+	},
+	{
+		name:   "memory limit kills the process",
+		code:   `data = bytearray(1024 * 1024 * 1024); print("FAIL")`,
+		killed: true,
+	},
+}
+
+// newTestManager returns a manager with the default security profile,
+// skipping the test in short mode or without a reachable Docker daemon
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping Docker integration test in short mode")
+	}
+	m, err := NewManager(DefaultSecurityProfile())
+	if err != nil {
+		t.Skipf("Docker is not available: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.client.Ping(ctx); err != nil {
+		t.Skipf("Docker is not available: %v", err)
+	}
+	return m
+}
+
+func TestSandboxWithstandsAbuse(t *testing.T) {
+	m := newTestManager(t)
+
+	for _, tt := range abuseCases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.Execute(context.Background(), ExecutionConfig{
+				Language:    "python",
+				Code:        tt.code,
+				Timeout:     20 * time.Second,
+				MemoryLimit: 128 * 1024 * 1024,
+				CPULimit:    0.5,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			switch {
+			case strings.Contains(result.Stdout, "FAIL"):
+				t.Errorf("abuse succeeded: %s", result.Stdout)
+			case result.Timeout:
+				t.Errorf("timed out")
+			case tt.killed && result.ExitCode == 0:
+				t.Errorf("expected the process to be killed")
+			case !tt.killed && !strings.HasPrefix(result.Stdout, "OK"):
+				t.Errorf("unexpected output, exit code %d\nstdout: %s\nstderr: %s", result.ExitCode, result.Stdout, result.Stderr)
+			}
+		})
+	}
+}
+
+func TestTmpStaysBounded(t *testing.T) {
+	m := newTestManager(t)
+
+	tests := []struct {
+		name   string
+		config ExecutionConfig
+	}{
+		{
+			name: "compiled language",
+			config: ExecutionConfig{
+				Language: "go",
+				Code: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	chunk := make([]byte, 1024*1024)
+	f, err := os.Create("/tmp/fill")
+	if err != nil {
+		fmt.Println("FAIL", err)
+		return
+	}
+	written := 0
+	for ; written < 1024; written++ {
+		if _, err := f.Write(chunk); err != nil {
+			break
+		}
+	}
+	if written < 1024 {
+		fmt.Println("OK", written)
+	} else {
+		fmt.Println("FAIL", written)
+	}
+}`,
+			},
+		},
+		{
+			name: "artifacts requested",
+			config: ExecutionConfig{
+				Language: "python",
+				Code: `chunk = b"x" * (1024 * 1024)
+written = 0
+try:
+    with open("/tmp/fill", "wb") as f:
+        for _ in range(1024):
+            f.write(chunk)
+            f.flush()
+            written += 1
+except OSError:
+    pass
+print("OK" if written < 1024 else "FAIL", written)`,
+				Artifacts: []string{"*.csv"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Timeout = 60 * time.Second
+			config.MemoryLimit = 512 * 1024 * 1024
+			config.CPULimit = 1
+			result, err := m.Execute(context.Background(), config)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.HasPrefix(result.Stdout, "OK") {
+				t.Errorf("/tmp is not size-limited, exit code %d\nstdout: %s\nstderr: %s", result.ExitCode, result.Stdout, result.Stderr)
+			}
+		})
+	}
+}
+
+func TestCompiledProgramReadsFixture(t *testing.T) {
+	m := newTestManager(t)
+
+	result, err := m.Execute(context.Background(), ExecutionConfig{
+		Language: "go",
+		Code: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	data, err := os.ReadFile("input.txt")
+	if err != nil {
+		fmt.Println("FAIL", err)
+		return
+	}
+	fmt.Printf("OK %s", data)
+}`,
+		Fixtures:    []Fixture{{Name: "input.txt", Data: []byte("fixture")}},
+		Timeout:     60 * time.Second,
+		MemoryLimit: 512 * 1024 * 1024,
+		CPULimit:    1,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Stdout != "OK fixture" {
+		t.Errorf("unexpected output, exit code %d\nstdout: %s\nstderr: %s", result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
@@ -0,0 +1,262 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// usagePollInterval is how often the usage of a running program is sampled
+// to enforce its CPU time limit and track its peak memory
+const usagePollInterval = 100 * time.Millisecond
+
+// Limits of usage series
+const (
+	MinUsageInterval = 50 * time.Millisecond
+	MaxUsagePoints   = 120 // Points are merged in pairs beyond this
+)
+
+// idleMargin keeps the idle process of a run container alive past the
+// timeout, so the cgroup can still be read after the program exits. Leaked
+// containers stop on their own once it ends.
+const idleMargin = 60 * time.Second
+
+// usage is the CPU time, memory, processes and block I/O of a container from
+// its cgroup statistics
+type usage struct {
+	user    time.Duration // CPU time in user mode
+	system  time.Duration // CPU time in kernel mode
+	memory  int64         // Peak memory seen
+	rss     int64         // Resident memory at the sample
+	pids    int64         // Processes and threads at the sample
+	ioRead  int64         // Bytes read from block devices
+	ioWrite int64         // Bytes written to block devices
+}
+
+// UsagePoint is a sample of the resource usage of a run
+type UsagePoint struct {
+	Offset     time.Duration // Time since the program started
+	CPUPercent float64       // CPU use since the previous point, 100 is one full core
+	RSS        int64         // Resident memory in bytes
+	Pids       int64         // Processes and threads of the program
+	IORead     int64         // Bytes read from block devices since the program started
+	IOWrite    int64         // Bytes written to block devices since the program started
+
+	cpu time.Duration // CPU time of the run at the point
+}
+
+// cpu returns the total CPU time
+func (u usage) cpu() time.Duration {
+	return u.user + u.system
+}
+
+// containerUsage reads the cgroup statistics of a running container
+func (m *Manager) containerUsage(ctx context.Context, containerID string) (usage, error) {
+	stats, err := m.client.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return usage{}, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	defer stats.Body.Close()
+
+	var response container.StatsResponse
+	if err := json.NewDecoder(stats.Body).Decode(&response); err != nil {
+		return usage{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+	// cgroup v1 reports the peak, v2 only the current usage
+	sample := usage{
+		user:   time.Duration(response.CPUStats.CPUUsage.UsageInUsermode),
+		system: time.Duration(response.CPUStats.CPUUsage.UsageInKernelmode),
+		memory: int64(max(response.MemoryStats.MaxUsage, response.MemoryStats.Usage)),
+		rss:    int64(response.MemoryStats.Usage),
+		pids:   int64(response.PidsStats.Current),
+	}
+	// Anonymous memory is the resident set on cgroup v2, rss on v1
+	for _, key := range []string{"anon", "rss"} {
+		if rss, ok := response.MemoryStats.Stats[key]; ok {
+			sample.rss = int64(rss)
+			break
+		}
+	}
+	// cgroup v1 also reports a total, v2 spells operations in lower case
+	for _, entry := range response.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			sample.ioRead += int64(entry.Value)
+		case "write":
+			sample.ioWrite += int64(entry.Value)
+		}
+	}
+	return sample, nil
+}
+
+// usageInterval returns the interval of a usage series, at least MinUsageInterval
+func usageInterval(interval time.Duration) time.Duration {
+	return max(interval, MinUsageInterval)
+}
+
+// usageSeries is the time series of a run's usage. It holds at most
+// MaxUsagePoints: beyond that, adjacent points are merged and the interval
+// doubles, so points stay evenly spaced.
+type usageSeries struct {
+	interval time.Duration
+	points   []UsagePoint
+}
+
+// mergePoints returns a point covering two adjacent points, keeping the peaks
+// of the gauges; CPU use is averaged over both when the series is read
+func mergePoints(earlier, later UsagePoint) UsagePoint {
+	later.RSS = max(earlier.RSS, later.RSS)
+	later.Pids = max(earlier.Pids, later.Pids)
+	return later
+}
+
+// add adds a point unless it is within the interval of the previous one.
+// The final point is merged into the previous one instead.
+func (s *usageSeries) add(point UsagePoint, final bool) {
+	if n := len(s.points); n > 0 {
+		last := s.points[n-1]
+		if point.Offset <= last.Offset {
+			return // A sample that finished after a later one
+		}
+		if point.Offset < last.Offset+s.interval {
+			if final {
+				s.points[n-1] = mergePoints(last, point)
+			}
+			return
+		}
+	}
+	s.points = append(s.points, point)
+
+	if len(s.points) > MaxUsagePoints {
+		merged := s.points[:0]
+		for i := 0; i < len(s.points); i += 2 {
+			if i+1 == len(s.points) {
+				merged = append(merged, s.points[i])
+				break
+			}
+			merged = append(merged, mergePoints(s.points[i], s.points[i+1]))
+		}
+		s.points = merged
+		s.interval *= 2
+	}
+}
+
+// result returns the points with the CPU use between consecutive points
+func (s *usageSeries) result() []UsagePoint {
+	points := make([]UsagePoint, len(s.points))
+	var previous UsagePoint
+	for i, point := range s.points {
+		if elapsed := point.Offset - previous.Offset; elapsed > 0 {
+			point.CPUPercent = float64(point.cpu-previous.cpu) / float64(elapsed) * 100
+		}
+		points[i] = point
+		previous = point
+	}
+	return points
+}
+
+// runUsage is the usage of the run phase: the container's usage since the
+// program started, with the peak memory and resident memory over all samples
+type runUsage struct {
+	mu       sync.Mutex
+	baseline usage
+	used     usage
+	start    time.Time    // When the program started
+	series   *usageSeries // Nil unless a usage series was requested
+}
+
+// record adds a sample of the container's usage taken at a time and returns
+// the usage of the run so far. The final sample ends the usage series.
+func (r *runUsage) record(sample usage, at time.Time, final bool) usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.used.user = max(r.used.user, sample.user-r.baseline.user)
+	r.used.system = max(r.used.system, sample.system-r.baseline.system)
+	r.used.memory = max(r.used.memory, sample.memory)
+	r.used.rss = max(r.used.rss, sample.rss)
+
+	if r.series != nil {
+		r.series.add(UsagePoint{
+			Offset:  at.Sub(r.start),
+			RSS:     sample.rss,
+			Pids:    max(sample.pids-r.baseline.pids, 0), // Without the idle process
+			IORead:  sample.ioRead - r.baseline.ioRead,
+			IOWrite: sample.ioWrite - r.baseline.ioWrite,
+			cpu:     (sample.user - r.baseline.user) + (sample.system - r.baseline.system),
+		}, final)
+	}
+	return r.used
+}
+
+// result returns the usage of the run from the samples recorded
+func (r *runUsage) result() usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.used
+}
+
+// points returns the usage series, nil unless one was requested
+func (r *runUsage) points() []UsagePoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.series == nil {
+		return nil
+	}
+	return r.series.result()
+}
+
+// pollInterval returns how often the run is sampled: the poll interval, or
+// the interval of the usage series if shorter
+func (r *runUsage) pollInterval() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.series == nil {
+		return usagePollInterval
+	}
+	return min(usagePollInterval, r.series.interval)
+}
+
+// watchUsage samples the usage of a container until ctx is done. Once the run
+// exceeds a positive CPU time limit, exceeded is called.
+func (m *Manager) watchUsage(ctx context.Context, containerID string, run *runUsage, limit time.Duration, exceeded func()) {
+	ticker := time.NewTicker(run.pollInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sample, err := m.containerUsage(ctx, containerID)
+		if err != nil {
+			continue // The container may have stopped, the final sample decides
+		}
+		if used := run.record(sample, time.Now(), false); limit > 0 && used.cpu() > limit {
+			exceeded()
+			return
+		}
+	}
+}
+
+// execExitCode waits for a command run with exec to finish and returns its exit code
+func (m *Manager) execExitCode(ctx context.Context, execID string) (int, error) {
+	for {
+		inspect, err := m.client.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect exec: %w", err)
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"code-executor/internal/auth"
+	pb "code-executor/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes maps each RPC method to the scope it requires. Methods
+// that are not listed, such as Health, need no authentication.
+var methodScopes = map[string]auth.Scope{
+	pb.CodeExecutor_Execute_FullMethodName:           auth.ScopeExecute,
+	pb.CodeExecutor_GetHint_FullMethodName:           auth.ScopeReview,
+	pb.CodeExecutor_ListLanguages_FullMethodName:     auth.ScopeExecute,
+	pb.CodeExecutor_Replay_FullMethodName:            auth.ScopeAdmin,
+	pb.CodeExecutor_AnalyzeComplexity_FullMethodName: auth.ScopeExecute,
+	pb.CodeExecutor_Benchmark_FullMethodName:         auth.ScopeExecute,
+}
+
+// UnaryAuthInterceptor authenticates unary calls and stores the caller's identity in the context
+func UnaryAuthInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming calls and stores the caller's identity in the context
+func StreamAuthInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// identityStream is a server stream carrying the authenticated context
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the authenticated context
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize authenticates the caller and checks the scope required by the method
+func authorize(ctx context.Context, authenticator auth.Authenticator, method string) (context.Context, error) {
+	scope, protected := methodScopes[method]
+	if !protected {
+		return ctx, nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = auth.BearerToken(values[0])
+		}
+		if values := md.Get("x-api-key"); token == "" && len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	identity, err := authenticator.Authenticate(ctx, token)
+	if errors.Is(err, auth.ErrMissingCredentials) || errors.Is(err, auth.ErrInvalidCredentials) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "authentication failed: %v", err)
+	}
+	if !identity.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "missing scope: %s", scope)
+	}
+
+	return auth.WithIdentity(ctx, identity), nil
+}
@@ -0,0 +1,125 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/benchmark"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	pb "code-executor/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Benchmark implements the Benchmark RPC method. It runs a submission
+// repeatedly in a warm container and summarizes its CPU time and memory,
+// optionally against a reference solution.
+func (s *Server) Benchmark(ctx context.Context, req *pb.BenchmarkRequest) (*pb.BenchmarkResponse, error) {
+	if req.CpuTimeLimitMs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "cpu_time_limit_ms must not be negative")
+	}
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(ctx).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMb*1024*1024, // Convert MB to bytes
+		req.CpuLimit,
+	)
+	bench := benchmark.Request{
+		Config: docker.ExecutionConfig{
+			Language:     req.Language,
+			Code:         req.Code,
+			Input:        req.Input,
+			Args:         req.Args,
+			Timeout:      timeout,
+			MemoryLimit:  memoryLimit,
+			CPULimit:     cpuLimit,
+			CPUTimeLimit: time.Duration(req.CpuTimeLimitMs) * time.Millisecond,
+			Trusted:      auth.IsTrusted(ctx),
+		},
+		Runs:      int(req.Runs),
+		Reference: req.ReferenceCode,
+	}
+	if req.Warmup != nil {
+		warmup := int(req.GetWarmup())
+		bench.Warmup = &warmup
+	}
+	if err := benchmark.Validate(bench); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Every run reserves an execution and its worst-case CPU time in the
+	// caller's quotas and is audited
+	caller := audit.CallerFromContext(ctx, clientIP(ctx))
+	var rejected error
+	record := func(entry audit.Entry) {
+		if s.auditLog != nil {
+			if _, err := s.auditLog.Append(entry); err != nil {
+				log.Printf("Failed to write audit entry: %v", err)
+			}
+		}
+	}
+	run := func(ctx context.Context, config docker.ExecutionConfig, runs int) ([]*docker.ExecutionResult, error) {
+		var reservation ratelimit.Reservation
+		if reservation, rejected = s.reserveExecutions(ctx, runs, time.Duration(runs)*ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit)); rejected != nil {
+			return nil, rejected
+		}
+		results, err := s.dockerManager.Benchmark(ctx, config, runs)
+		s.settleCPUTime(ctx, reservation, results...)
+		if err != nil {
+			record(audit.ExecutionEntry(caller, config, nil, err))
+		}
+		for _, result := range results {
+			record(audit.ExecutionEntry(caller, config, result, nil))
+		}
+		return results, err
+	}
+	result, err := benchmark.Run(ctx, run, bench)
+
+	if rejected != nil {
+		return nil, rejected
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, benchmark.ErrReference) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "benchmark failed: %v", err)
+	}
+
+	response := &pb.BenchmarkResponse{
+		Submission: benchmarkMeasurements(&result.Submission),
+		Score:      result.Score,
+		Noisy:      result.Noisy,
+	}
+	if result.Reference != nil {
+		response.Reference = benchmarkMeasurements(result.Reference)
+	}
+	return response, nil
+}
+
+// benchmarkMeasurements returns the message of the measurements of a program
+func benchmarkMeasurements(measurements *benchmark.Measurements) *pb.BenchmarkMeasurements {
+	return &pb.BenchmarkMeasurements{
+		Runs:        int32(measurements.Runs),
+		CpuTimeUs:   benchmarkSummary(measurements.CPU),
+		MemoryBytes: benchmarkSummary(measurements.Memory),
+		Error:       measurements.Error,
+	}
+}
+
+// benchmarkSummary returns the message of a summary
+func benchmarkSummary(summary benchmark.Summary) *pb.BenchmarkSummary {
+	return &pb.BenchmarkSummary{
+		Min:    summary.Min,
+		Median: summary.Median,
+		P90:    summary.P90,
+		Stddev: summary.StdDev,
+		Noisy:  summary.Noisy,
+	}
+}
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/complexity"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	pb "code-executor/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AnalyzeComplexity implements the AnalyzeComplexity RPC method. It runs a
+// submission on generated inputs of increasing size and fits its CPU time and
+// memory to complexity classes.
+func (s *Server) AnalyzeComplexity(ctx context.Context, req *pb.ComplexityRequest) (*pb.ComplexityResponse, error) {
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(ctx).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMb*1024*1024, // Convert MB to bytes
+		req.CpuLimit,
+	)
+	analysis := complexity.Request{
+		Submission: complexity.Program{Language: req.Language, Code: req.Code},
+		Generator:  complexity.Program{Language: req.GetGenerator().GetLanguage(), Code: req.GetGenerator().GetCode()},
+		Repeats:    int(req.Repeats),
+		Limits: docker.ExecutionConfig{
+			Timeout:     timeout,
+			MemoryLimit: memoryLimit,
+			CPULimit:    cpuLimit,
+			Trusted:     auth.IsTrusted(ctx),
+		},
+	}
+	for _, size := range req.Sizes {
+		analysis.Sizes = append(analysis.Sizes, int(size))
+	}
+	if err := complexity.Validate(analysis); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Every run reserves an execution and its worst-case CPU time in the
+	// caller's quotas and is audited
+	caller := audit.CallerFromContext(ctx, clientIP(ctx))
+	var rejected error
+	execute := func(ctx context.Context, config docker.ExecutionConfig) (*docker.ExecutionResult, error) {
+		var reservation ratelimit.Reservation
+		if reservation, rejected = s.reserveExecutions(ctx, 1, ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit)); rejected != nil {
+			return nil, rejected
+		}
+		result, err := s.dockerManager.Execute(ctx, config)
+		s.settleCPUTime(ctx, reservation, result)
+		if s.auditLog != nil {
+			if _, auditErr := s.auditLog.Append(audit.ExecutionEntry(caller, config, result, err)); auditErr != nil {
+				log.Printf("Failed to write audit entry: %v", auditErr)
+			}
+		}
+		return result, err
+	}
+	result, err := complexity.Analyze(ctx, execute, analysis)
+
+	if rejected != nil {
+		return nil, rejected
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, complexity.ErrGenerator) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "complexity analysis failed: %v", err)
+	}
+
+	response := &pb.ComplexityResponse{
+		Cpu:    complexityFit(result.CPU),
+		Memory: complexityFit(result.Memory),
+	}
+	for _, measurement := range result.Measurements {
+		response.Measurements = append(response.Measurements, &pb.ComplexityMeasurement{
+			Size:        int64(measurement.Size),
+			InputBytes:  measurement.InputBytes,
+			CpuTimeUs:   measurement.CPUTime.Microseconds(),
+			MemoryBytes: measurement.Memory,
+			Error:       measurement.Error,
+		})
+	}
+	return response, nil
+}
+
+// complexityFit returns the message of a fit, nil if there is none
+func complexityFit(fit *complexity.Fit) *pb.ComplexityFit {
+	if fit == nil {
+		return nil
+	}
+	message := &pb.ComplexityFit{
+		Class:      fit.Class,
+		Confidence: fit.Confidence,
+	}
+	for _, candidate := range fit.Candidates {
+		message.Candidates = append(message.Candidates, &pb.ComplexityCandidate{
+			Class:       candidate.Class,
+			Probability: candidate.Probability,
+		})
+	}
+	return message
+}
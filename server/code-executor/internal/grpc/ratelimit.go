@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// UnaryRateLimitInterceptor rejects unary calls of callers exceeding their request rate
+func UnaryRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allowRequest(ctx, limiter); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor rejects streaming calls of callers exceeding their request rate
+func StreamRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowRequest(ss.Context(), limiter); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowRequest takes a token from the caller's request bucket
+func allowRequest(ctx context.Context, limiter *ratelimit.Limiter) error {
+	decision, err := limiter.Allow(ctx, subject(ctx))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return decisionError(ctx, decision)
+}
+
+// reserveExecutions reserves executions and their worst-case CPU time in the
+// caller's quotas, rejecting callers that used up their quotas
+func (s *Server) reserveExecutions(ctx context.Context, executions int, cpu time.Duration) (ratelimit.Reservation, error) {
+	if s.limiter == nil {
+		return ratelimit.Reservation{}, nil
+	}
+
+	reservation, err := s.limiter.ReserveExecutions(ctx, subject(ctx), executions, cpu)
+	if err != nil {
+		return reservation, status.Error(codes.Internal, err.Error())
+	}
+	return reservation, decisionError(ctx, reservation.Decision)
+}
+
+// settleCPUTime replaces the CPU time reserved for executions with the CPU
+// time of their results
+func (s *Server) settleCPUTime(ctx context.Context, reservation ratelimit.Reservation, results ...*docker.ExecutionResult) {
+	if s.limiter == nil {
+		return
+	}
+
+	var used time.Duration
+	for _, result := range results {
+		if result != nil {
+			used += result.CPUTime()
+		}
+	}
+	if err := s.limiter.SettleCPU(ctx, reservation, used); err != nil {
+		log.Printf("Failed to settle CPU quota: %v", err)
+	}
+}
+
+// subject returns the rate limiting subject of the caller
+func subject(ctx context.Context) string {
+	return ratelimit.Subject(ctx, clientIP(ctx))
+}
+
+// hintLearner returns whom hints are revealed to: the caller's user or the
+// user named by a service caller, else the caller's quota subject
+func hintLearner(ctx context.Context, userID string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	return subject(ctx)
+}
+
+// clientIP returns the address of the caller without its port
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// decisionError sends the ratelimit-* headers and returns ResourceExhausted
+// with retry information if the request was not allowed
+func decisionError(ctx context.Context, decision ratelimit.Decision) error {
+	if decision.Limit > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(decision.Limit),
+			"ratelimit-remaining", strconv.Itoa(decision.Remaining),
+			"ratelimit-reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))),
+		))
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	st, err := status.New(codes.ResourceExhausted, decision.Reason).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(decision.RetryAfter),
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, decision.Reason)
+	}
+	return st.Err()
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"code-executor/internal/records"
+	pb "code-executor/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storeRecord stores the record of an execution and returns its ID, empty if
+// records are not enabled or storing failed
+func (s *Server) storeRecord(caller audit.Caller, config docker.ExecutionConfig, result *docker.ExecutionResult) string {
+	if s.records == nil {
+		return ""
+	}
+	id, err := s.records.Put(records.New(caller, config, result))
+	if err != nil {
+		log.Printf("Failed to store execution record: %v", err)
+		return ""
+	}
+	return id
+}
+
+// Replay implements the Replay RPC method. It runs a stored execution again
+// with the same code, inputs, limits, image and determinism settings and
+// diffs the result with the stored one.
+func (s *Server) Replay(ctx context.Context, req *pb.ReplayRequest) (*pb.ReplayResponse, error) {
+	if s.records == nil {
+		return nil, status.Error(codes.Unimplemented, "execution records are not enabled")
+	}
+	record, err := s.records.Get(req.ExecutionId)
+	if errors.Is(err, records.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load execution record: %v", err)
+	}
+
+	config := record.Config.Execution()
+	if req.LatestImage || req.Version != "" {
+		config.ImageDigest = ""
+		if req.Version != "" {
+			name, _, _ := strings.Cut(config.Language, "@")
+			config.Language = name + "@" + req.Version
+		}
+		if _, err := docker.ImageFor(config.Language); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	// Reserve the execution and its worst-case CPU time in the caller's quotas
+	reservation, err := s.reserveExecutions(ctx, 1, ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit))
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.dockerManager.Execute(ctx, config)
+	s.settleCPUTime(ctx, reservation, result)
+	if s.auditLog != nil {
+		if _, auditErr := s.auditLog.Append(audit.ExecutionEntry(audit.CallerFromContext(ctx, clientIP(ctx)), config, result, err)); auditErr != nil {
+			log.Printf("Failed to write audit entry: %v", auditErr)
+		}
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, docker.ErrImageUnavailable) || errors.Is(err, docker.ErrUnsupportedVersion) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, docker.ErrInvalidArguments) || errors.Is(err, docker.ErrInvalidManifest) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, docker.ErrDependencyInstall) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "replay failed: %v", err)
+	}
+
+	differences := records.Diff(record.Result, records.NewResult(result))
+	response := &pb.ReplayResponse{
+		ExecutionId: record.ID,
+		Identical:   len(differences) == 0,
+		Result:      executeResponse(result, config.MemoryLimit),
+	}
+	for _, difference := range differences {
+		response.Differences = append(response.Differences, &pb.Difference{
+			Field:    difference.Field,
+			Stored:   difference.Stored,
+			Replayed: difference.Replayed,
+		})
+	}
+	return response, nil
+}
@@ -2,10 +2,18 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/blob"
 	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"code-executor/internal/records"
+	"code-executor/internal/review"
 	pb "code-executor/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -16,12 +24,30 @@ import (
 type Server struct {
 	pb.UnimplementedCodeExecutorServer
 	dockerManager *docker.Manager
+	hints         *review.HintService
+	limiter       *ratelimit.Limiter
+	auditLog      *audit.Log
+	fixtureStore  *blob.Store
+	records       *records.Store
+}
+
+// Options contains the optional dependencies of the gRPC server
+type Options struct {
+	Limiter      *ratelimit.Limiter // Nil disables execution quotas
+	AuditLog     *audit.Log         // Nil disables the audit log
+	FixtureStore *blob.Store        // Nil disables fixtures referenced by ID
+	Records      *records.Store     // Nil disables execution records and replays
 }
 
 // NewServer creates a new gRPC server
-func NewServer(dockerManager *docker.Manager) *Server {
+func NewServer(dockerManager *docker.Manager, hints *review.HintService, opts Options) *Server {
 	return &Server{
 		dockerManager: dockerManager,
+		hints:         hints,
+		limiter:       opts.Limiter,
+		auditLog:      opts.AuditLog,
+		fixtureStore:  opts.FixtureStore,
+		records:       opts.Records,
 	}
 }
 
@@ -34,102 +60,327 @@ func (s *Server) Execute(ctx context.Context, req *pb.ExecuteRequest) (*pb.Execu
 	if req.Code == "" {
 		return nil, status.Error(codes.InvalidArgument, "code is required")
 	}
-
-	// Set default values
-	timeout := time.Duration(req.TimeoutSeconds) * time.Second
-	if timeout == 0 {
-		timeout = 30 * time.Second
+	if err := docker.ValidateArtifactPatterns(req.Artifacts); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	if timeout > 120*time.Second {
-		timeout = 120 * time.Second // Maximum 2 minutes
+	if err := docker.ValidateArgs(req.Args, req.Env); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-
-	memoryLimit := req.MemoryLimitMb * 1024 * 1024 // Convert MB to bytes
-	if memoryLimit == 0 {
-		memoryLimit = 128 * 1024 * 1024 // Default 128MB
+	if _, err := docker.ImageFor(req.Language); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	if memoryLimit > 1024*1024*1024 {
-		memoryLimit = 1024 * 1024 * 1024 // Maximum 1GB
+	if req.CpuTimeLimitMs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "cpu_time_limit_ms must not be negative")
 	}
-
-	cpuLimit := req.CpuLimit
-	if cpuLimit == 0 {
-		cpuLimit = 0.5 // Default 50% CPU
+	if req.UsageIntervalMs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "usage_interval_ms must not be negative")
+	}
+	fixtures, err := s.resolveFixtures(req.Fixtures)
+	if errors.Is(err, docker.ErrInvalidFixture) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load fixtures: %v", err)
+	}
+	var manifest *docker.Manifest
+	if req.Manifest != nil {
+		manifest = &docker.Manifest{Name: req.Manifest.Name, Data: []byte(req.Manifest.Content)}
 	}
-	if cpuLimit > 1.0 {
-		cpuLimit = 1.0 // Maximum 100% CPU
+	if err := docker.ValidateManifest(manifest, req.Language, fixtures); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	determinism := determinismConfig(req.Determinism)
+	if err := docker.ValidateDeterminism(determinism, req.Language, req.Env); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Set default values, capped by the caller's limits
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(ctx).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMb*1024*1024, // Convert MB to bytes
+		req.CpuLimit,
+	)
 
-	// Ensure Docker image is available
-	imageName := getImageName(req.Language)
-	if err := s.dockerManager.EnsureImage(ctx, imageName); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to ensure Docker image: %v", err)
+	// Reserve the execution and its worst-case CPU time in the caller's quotas
+	reservation, err := s.reserveExecutions(ctx, 1, ratelimit.WorstCaseCPU(timeout, cpuLimit))
+	if err != nil {
+		return nil, err
 	}
 
 	// Execute code
 	config := docker.ExecutionConfig{
-		Language:    req.Language,
-		Code:        req.Code,
-		Input:       req.Input,
-		Timeout:     timeout,
-		MemoryLimit: memoryLimit,
-		CPULimit:    cpuLimit,
+		Language:       req.Language,
+		Code:           req.Code,
+		Input:          req.Input,
+		Timeout:        timeout,
+		MemoryLimit:    memoryLimit,
+		CPULimit:       cpuLimit,
+		CPUTimeLimit:   time.Duration(req.CpuTimeLimitMs) * time.Millisecond,
+		UsageInterval:  time.Duration(req.UsageIntervalMs) * time.Millisecond,
+		Trusted:        auth.IsTrusted(ctx),
+		CombinedOutput: req.CombinedOutput,
+		Artifacts:      req.Artifacts,
+		Fixtures:       fixtures,
+		Args:           req.Args,
+		Env:            req.Env,
+		Manifest:       manifest,
+		Determinism:    determinism,
 	}
 
 	result, err := s.dockerManager.Execute(ctx, config)
+	s.settleCPUTime(ctx, reservation, result)
+	if s.auditLog != nil {
+		if _, auditErr := s.auditLog.Append(audit.ExecutionEntry(audit.CallerFromContext(ctx, clientIP(ctx)), config, result, err)); auditErr != nil {
+			log.Printf("Failed to write audit entry: %v", auditErr)
+		}
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, docker.ErrInvalidManifest) || errors.Is(err, docker.ErrInvalidArguments) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, docker.ErrDependencyInstall) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "execution failed: %v", err)
 	}
 
-	// Build response
+	// Build response, with the record the execution can be replayed from
+	response := executeResponse(result, memoryLimit)
+	response.ExecutionId = s.storeRecord(audit.CallerFromContext(ctx, clientIP(ctx)), config, result)
+	return response, nil
+}
+
+// executeResponse returns the response of an execution
+func executeResponse(result *docker.ExecutionResult, memoryLimit int64) *pb.ExecuteResponse {
 	response := &pb.ExecuteResponse{
-		Stdout:          result.Stdout,
-		Stderr:          result.Stderr,
-		ExitCode:        int32(result.ExitCode),
-		Timeout:         result.Timeout,
-		MemoryExceeded:  result.MemoryUsed > memoryLimit,
-		ExecutionTimeMs: result.ExecutionTime.Milliseconds(),
-		MemoryUsedMb:    result.MemoryUsed / (1024 * 1024),
+		Stdout:             result.Stdout,
+		Stderr:             result.Stderr,
+		ExitCode:           int32(result.ExitCode),
+		Timeout:            result.Timeout,
+		MemoryExceeded:     result.MemoryUsed > memoryLimit,
+		ExecutionTimeMs:    result.ExecutionTime.Milliseconds(),
+		CpuTimeMs:          result.CPUTime().Milliseconds(),
+		CpuUserTimeMs:      result.CPUUserTime.Milliseconds(),
+		CpuSystemTimeMs:    result.CPUSystemTime.Milliseconds(),
+		CpuTimeExceeded:    result.CPUTimeExceeded,
+		StartupTimeMs:      result.StartupTime.Milliseconds(),
+		ImagePullTimeMs:    result.PullTime.Milliseconds(),
+		MemoryUsedMb:       result.MemoryUsed / (1024 * 1024),
+		StdoutTruncated:    result.StdoutTruncated,
+		StderrTruncated:    result.StderrTruncated,
+		StdoutBytes:        result.StdoutBytes,
+		StderrBytes:        result.StderrBytes,
+		ArtifactsTruncated: result.ArtifactsTruncated,
+		Version:            result.Version,
+		ImageDigest:        result.ImageDigest,
+		DependenciesCached: result.DependenciesCached,
+		InstallTimeMs:      result.InstallTime.Milliseconds(),
+		BuildCached:        result.BuildCached,
+		CompileTimeMs:      result.CompileTime.Milliseconds(),
+		Determinism:        determinismInfo(result.Determinism),
+	}
+	for _, chunk := range result.Output {
+		response.Output = append(response.Output, &pb.OutputChunk{
+			Stream:   chunk.Stream,
+			Data:     chunk.Data,
+			OffsetMs: chunk.Offset.Milliseconds(),
+		})
+	}
+	for _, point := range result.Usage {
+		response.Usage = append(response.Usage, &pb.UsagePoint{
+			OffsetMs:     point.Offset.Milliseconds(),
+			CpuPercent:   point.CPUPercent,
+			RssBytes:     point.RSS,
+			Pids:         point.Pids,
+			IoReadBytes:  point.IORead,
+			IoWriteBytes: point.IOWrite,
+		})
+	}
+	for _, artifact := range result.Artifacts {
+		response.Artifacts = append(response.Artifacts, &pb.Artifact{
+			Path:    artifact.Path,
+			Size:    artifact.Size,
+			Content: artifact.Data,
+		})
+	}
+	for _, digest := range result.Fixtures {
+		response.Fixtures = append(response.Fixtures, &pb.FixtureDigest{
+			Name:   digest.Name,
+			Size:   digest.Size,
+			Sha256: digest.SHA256,
+		})
+	}
+
+	return response
+}
+
+// resolveFixtures returns the fixtures of a request with their content, loading
+// referenced ones from the fixture store
+func (s *Server) resolveFixtures(fixtures []*pb.Fixture) ([]docker.Fixture, error) {
+	resolved := make([]docker.Fixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		data := fixture.Content
+		if fixture.Id != "" {
+			if len(fixture.Content) > 0 {
+				return nil, fmt.Errorf("%w: %q has both content and an ID", docker.ErrInvalidFixture, fixture.Name)
+			}
+			if s.fixtureStore == nil {
+				return nil, fmt.Errorf("%w: fixture store is not enabled", docker.ErrInvalidFixture)
+			}
+			var err error
+			data, err = s.fixtureStore.Get(fixture.Id)
+			if errors.Is(err, blob.ErrNotFound) {
+				return nil, fmt.Errorf("%w: unknown fixture ID %q", docker.ErrInvalidFixture, fixture.Id)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		resolved = append(resolved, docker.Fixture{Name: fixture.Name, Data: data})
+	}
+
+	if err := docker.ValidateFixtures(resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// determinismConfig returns the deterministic settings of a request, nil for a normal run
+func determinismConfig(d *pb.Determinism) *docker.Determinism {
+	if d == nil {
+		return nil
+	}
+	determinism := &docker.Determinism{Seed: d.Seed, PinCPU: d.PinCpu, CPUSet: d.CpuSet}
+	if d.FakeTimeUnix != 0 {
+		determinism.FakeTime = time.Unix(d.FakeTimeUnix, 0).UTC()
+	}
+	return determinism
+}
+
+// determinismInfo returns the settings a run applied, nil for a normal run
+func determinismInfo(d *docker.Determinism) *pb.Determinism {
+	if d == nil {
+		return nil
+	}
+	info := &pb.Determinism{
+		Seed:     d.Seed,
+		PinCpu:   d.PinCPU,
+		Timezone: d.Timezone,
+		Locale:   d.Locale,
+		CpuSet:   d.CPUSet,
+	}
+	if !d.FakeTime.IsZero() {
+		info.FakeTimeUnix = d.FakeTime.Unix()
+	}
+	return info
+}
+
+// ListLanguages implements the ListLanguages RPC method
+func (s *Server) ListLanguages(ctx context.Context, req *pb.ListLanguagesRequest) (*pb.ListLanguagesResponse, error) {
+	languages, err := s.dockerManager.Languages(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list languages: %v", err)
 	}
 
+	response := &pb.ListLanguagesResponse{}
+	for _, language := range languages {
+		info := &pb.LanguageInfo{Name: language.Name, Aliases: language.Aliases}
+		for _, version := range language.Versions {
+			info.Versions = append(info.Versions, &pb.VersionInfo{
+				Version:   version.Version,
+				Image:     version.Image,
+				Digest:    version.Digest,
+				Installed: version.Installed,
+				Default:   version.Default,
+				Pinned:    version.Pinned,
+			})
+		}
+		response.Languages = append(response.Languages, info)
+	}
 	return response, nil
 }
 
-// Health implements the Health RPC method
+// Health implements the Health RPC method. The service is not ready while
+// required execution images are missing.
 func (s *Server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
-	return &pb.HealthResponse{
+	missing, err := s.dockerManager.MissingImages(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to check images: %v", err)
+	}
+
+	response := &pb.HealthResponse{
 		Status:  "healthy",
 		Version: "1.0.0",
-	}, nil
+	}
+	if len(missing) > 0 {
+		response.Status = "not_ready"
+		response.MissingImages = missing
+	}
+	return response, nil
 }
 
-// getImageName returns the appropriate Docker image for the language
-func getImageName(language string) string {
-	switch language {
-	case "python", "python3":
-		return "python:3.11-alpine"
-	case "javascript", "js", "node":
-		return "node:18-alpine"
-	case "go", "golang":
-		return "golang:1.21-alpine"
-	case "java":
-		return "openjdk:11-alpine"
-	case "c":
-		return "gcc:alpine"
-	case "cpp", "c++":
-		return "gcc:alpine"
-	case "rust":
-		return "rust:alpine"
-	case "ruby":
-		return "ruby:3.2-alpine"
-	case "php":
-		return "php:8.2-alpine"
-	default:
-		return "python:3.11-alpine"
+// GetHint implements the GetHint RPC method
+func (s *Server) GetHint(ctx context.Context, req *pb.HintRequest) (*pb.HintResponse, error) {
+	// Validate request
+	if req.SubmissionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
+	}
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	if req.RevealedLevel < 0 {
+		return nil, status.Error(codes.InvalidArgument, "revealed_level must not be negative")
+	}
+
+	// A verified platform token takes precedence over the user named in the request
+	userID := req.UserId
+	if identity, ok := auth.FromContext(ctx); ok && identity.UserID != "" {
+		userID = identity.UserID
+	}
+
+	hintReq := review.HintRequest{
+		Submission: review.Submission{
+			ID:       req.SubmissionId,
+			UserID:   userID,
+			CourseID: req.CourseId,
+			Language: req.Language,
+			Code:     req.Code,
+		},
+		Learner:       hintLearner(ctx, userID),
+		RevealedLevel: int(req.RevealedLevel),
+	}
+	if exec := req.LastExecution; exec != nil {
+		hintReq.Execution = &review.ExecutionSummary{
+			Stdout:         exec.Stdout,
+			Stderr:         exec.Stderr,
+			ExitCode:       int(exec.ExitCode),
+			Timeout:        exec.Timeout,
+			MemoryExceeded: exec.MemoryExceeded,
+		}
+	}
+
+	hint, err := s.hints.Next(ctx, hintReq)
+	if errors.Is(err, review.ErrHintsExhausted) || errors.Is(err, review.ErrHintLevel) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "hint generation failed: %v", err)
+	}
+
+	return &pb.HintResponse{
+		Hint:         hint.Text,
+		Level:        int32(hint.Level),
+		MaxLevel:     int32(hint.MaxLevel),
+		HintsUsed:    int32(hint.HintsUsed),
+		InputTokens:  hint.Usage.InputTokens,
+		OutputTokens: hint.Usage.OutputTokens,
+	}, nil
 }
 
 // RegisterServer registers the gRPC server
-func RegisterServer(s *grpc.Server, dockerManager *docker.Manager) {
-	pb.RegisterCodeExecutorServer(s, NewServer(dockerManager))
+func RegisterServer(s *grpc.Server, dockerManager *docker.Manager, hints *review.HintService, opts Options) {
+	pb.RegisterCodeExecutorServer(s, NewServer(dockerManager, hints, opts))
 }
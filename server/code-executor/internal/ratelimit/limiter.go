@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"code-executor/internal/auth"
+)
+
+const (
+	executionWindow = time.Hour
+	cpuWindow       = 24 * time.Hour
+)
+
+// Config contains request rates and execution quotas. Zero values disable a limit.
+type Config struct {
+	RequestsPerSecond float64 // Sustained request rate per subject
+	Burst             int     // Requests a subject may make at once
+	ExecutionsPerHour int     // Executions per subject in a rolling hour
+	CPUSecondsPerDay  float64 // CPU-seconds per subject in a rolling day
+}
+
+// Decision is the outcome of a rate limit or quota check
+type Decision struct {
+	Allowed    bool
+	Limit      int           // Size of the limit that applied
+	Remaining  int           // What is left of the limit
+	Reset      time.Duration // Time until the limit is fully available again
+	RetryAfter time.Duration // Time to wait before retrying, if not allowed
+	Reason     string        // Which limit rejected the request
+}
+
+// Limiter enforces request rates and execution quotas per subject
+type Limiter struct {
+	config Config
+	store  Store
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter keeping its state in store
+func NewLimiter(config Config, store Store) *Limiter {
+	return &Limiter{
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// Subject returns the key limits are tracked under: the platform user, the
+// API key, or the client IP for unauthenticated callers
+func Subject(ctx context.Context, clientIP string) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		if identity.UserID != "" {
+			return "user:" + identity.UserID
+		}
+		if identity.KeyID != "" {
+			return "key:" + identity.KeyID
+		}
+	}
+	return "ip:" + clientIP
+}
+
+// Allow takes a token from the subject's request bucket
+func (l *Limiter) Allow(ctx context.Context, subject string) (Decision, error) {
+	if l.config.RequestsPerSecond <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+
+	burst := l.config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	state, err := l.store.TakeToken(ctx, "rate:"+subject, l.config.RequestsPerSecond, burst, l.now())
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	missing := float64(burst - state.Remaining)
+	decision := Decision{
+		Allowed:    state.Allowed,
+		Limit:      burst,
+		Remaining:  state.Remaining,
+		Reset:      time.Duration(missing / l.config.RequestsPerSecond * float64(time.Second)),
+		RetryAfter: state.RetryAfter,
+	}
+	if !state.Allowed {
+		decision.Reason = "request rate limit exceeded"
+	}
+	return decision, nil
+}
+
+// Reservation is what ReserveExecutions took from a subject's quotas. The CPU
+// time reserved is settled with SettleCPU once the executions finished.
+type Reservation struct {
+	Decision
+	subject string
+	cpu     float64   // CPU-seconds reserved
+	at      time.Time // When the quota was reserved
+}
+
+// WorstCaseCPU returns the most CPU time an execution with a timeout and CPU
+// limit can use
+func WorstCaseCPU(timeout time.Duration, cpuLimit float64) time.Duration {
+	return time.Duration(float64(timeout) * cpuLimit)
+}
+
+// ReserveExecutions reserves executions and their worst-case CPU time in the
+// subject's quotas. Each check and reservation is one store operation, so
+// concurrent requests cannot overrun the quotas. A CPU reservation larger than
+// the whole quota is capped, so such executions run once nothing else is counted.
+func (l *Limiter) ReserveExecutions(ctx context.Context, subject string, executions int, cpu time.Duration) (Reservation, error) {
+	now := l.now()
+	reservation := Reservation{Decision: Decision{Allowed: true}, subject: subject, at: now}
+
+	if l.config.ExecutionsPerHour > 0 {
+		state, err := l.store.ReserveInWindow(ctx, "executions:"+subject, float64(executions), float64(l.config.ExecutionsPerHour), executionWindow, now)
+		if err != nil {
+			return Reservation{}, fmt.Errorf("failed to reserve executions: %w", err)
+		}
+		reservation.Decision = Decision{
+			Allowed:   state.Allowed,
+			Limit:     l.config.ExecutionsPerHour,
+			Remaining: max(l.config.ExecutionsPerHour-int(state.Total), 0),
+			Reset:     state.Reset,
+		}
+		if !state.Allowed {
+			reservation.RetryAfter = state.Reset
+			reservation.Reason = "hourly execution quota exceeded"
+			return reservation, nil
+		}
+	}
+
+	if l.config.CPUSecondsPerDay > 0 {
+		amount := math.Min(cpu.Seconds(), l.config.CPUSecondsPerDay)
+		state, err := l.store.ReserveInWindow(ctx, "cpu:"+subject, amount, l.config.CPUSecondsPerDay, cpuWindow, now)
+		if err != nil {
+			l.releaseExecutions(ctx, subject, executions, now)
+			return Reservation{}, fmt.Errorf("failed to reserve CPU time: %w", err)
+		}
+		if !state.Allowed {
+			if err := l.releaseExecutions(ctx, subject, executions, now); err != nil {
+				return Reservation{}, err
+			}
+			return Reservation{Decision: Decision{
+				Allowed:    false,
+				Limit:      int(l.config.CPUSecondsPerDay),
+				Remaining:  max(int(l.config.CPUSecondsPerDay-state.Total), 0),
+				Reset:      state.Reset,
+				RetryAfter: state.Reset,
+				Reason:     "daily CPU quota exceeded",
+			}}, nil
+		}
+		reservation.cpu = amount
+	}
+	return reservation, nil
+}
+
+// releaseExecutions gives back executions reserved at now
+func (l *Limiter) releaseExecutions(ctx context.Context, subject string, executions int, now time.Time) error {
+	if l.config.ExecutionsPerHour <= 0 {
+		return nil
+	}
+	if err := l.store.AddToWindow(ctx, "executions:"+subject, -float64(executions), executionWindow, now); err != nil {
+		return fmt.Errorf("failed to release executions: %w", err)
+	}
+	return nil
+}
+
+// SettleCPU replaces the CPU time reserved for executions with the CPU time
+// they used. The difference is booked at the time of the reservation, so a
+// refund leaves the rolling window together with the amount it corrects.
+func (l *Limiter) SettleCPU(ctx context.Context, reservation Reservation, used time.Duration) error {
+	if l.config.CPUSecondsPerDay <= 0 || reservation.at.IsZero() {
+		return nil
+	}
+	difference := used.Seconds() - reservation.cpu
+	if difference == 0 {
+		return nil
+	}
+	if err := l.store.AddToWindow(ctx, "cpu:"+reservation.subject, difference, cpuWindow, reservation.at); err != nil {
+		return fmt.Errorf("failed to settle CPU usage: %w", err)
+	}
+	return nil
+}
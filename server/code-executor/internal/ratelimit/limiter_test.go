@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter backed by a memory store at a fixed time
+func newTestLimiter(config Config, now *time.Time) *Limiter {
+	limiter := NewLimiter(config, NewMemoryStore())
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestLimiterReservesWorstCaseCPU(t *testing.T) {
+	now := start
+	limiter := newTestLimiter(Config{CPUSecondsPerDay: 100}, &now)
+	ctx := context.Background()
+
+	// Concurrent executions with a 30s timeout on one CPU fit three times into 100s
+	const callers = 10
+	var wg sync.WaitGroup
+	reservations := make(chan Reservation, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := limiter.ReserveExecutions(ctx, "user:a", 1, WorstCaseCPU(30*time.Second, 1))
+			if err != nil {
+				t.Errorf("ReserveExecutions() error = %v", err)
+				return
+			}
+			reservations <- reservation
+		}()
+	}
+	wg.Wait()
+	close(reservations)
+
+	var allowed []Reservation
+	for reservation := range reservations {
+		if reservation.Allowed {
+			allowed = append(allowed, reservation)
+		} else if reservation.Reason != "daily CPU quota exceeded" {
+			t.Errorf("rejected for %q", reservation.Reason)
+		}
+	}
+	if len(allowed) != 3 {
+		t.Fatalf("%d of %d executions allowed, want 3", len(allowed), callers)
+	}
+
+	// An execution that used 5s gives back 25s, making room for another one
+	if err := limiter.SettleCPU(ctx, allowed[0], 5*time.Second); err != nil {
+		t.Fatalf("SettleCPU() error = %v", err)
+	}
+	reservation, err := limiter.ReserveExecutions(ctx, "user:a", 1, WorstCaseCPU(30*time.Second, 1))
+	if err != nil {
+		t.Fatalf("ReserveExecutions() error = %v", err)
+	}
+	if !reservation.Allowed {
+		t.Errorf("execution rejected after the refund: %s", reservation.Reason)
+	}
+	if used, _, _ := limiter.store.WindowTotal(ctx, "cpu:user:a", cpuWindow, now); used != 95 {
+		t.Errorf("CPU quota used = %v, want 95", used)
+	}
+}
+
+func TestLimiterCapsReservationsAtTheQuota(t *testing.T) {
+	now := start
+	limiter := newTestLimiter(Config{CPUSecondsPerDay: 10}, &now)
+	ctx := context.Background()
+
+	reservation, err := limiter.ReserveExecutions(ctx, "user:a", 1, time.Minute)
+	if err != nil || !reservation.Allowed {
+		t.Fatalf("first execution allowed %v, error %v, want it to run", reservation.Allowed, err)
+	}
+	if reservation, _ := limiter.ReserveExecutions(ctx, "user:a", 1, time.Second); reservation.Allowed {
+		t.Error("second execution allowed while the first holds the whole quota")
+	}
+}
+
+func TestLimiterReleasesExecutionsOnCPURejection(t *testing.T) {
+	now := start
+	limiter := newTestLimiter(Config{ExecutionsPerHour: 10, CPUSecondsPerDay: 10}, &now)
+	ctx := context.Background()
+
+	if reservation, _ := limiter.ReserveExecutions(ctx, "user:a", 1, 10*time.Second); !reservation.Allowed {
+		t.Fatalf("first execution rejected: %s", reservation.Reason)
+	}
+	reservation, err := limiter.ReserveExecutions(ctx, "user:a", 1, 10*time.Second)
+	if err != nil {
+		t.Fatalf("ReserveExecutions() error = %v", err)
+	}
+	if reservation.Allowed || reservation.Reason != "daily CPU quota exceeded" {
+		t.Errorf("second execution allowed %v for %q, want a CPU quota rejection", reservation.Allowed, reservation.Reason)
+	}
+	if executions, _, _ := limiter.store.WindowTotal(ctx, "executions:user:a", executionWindow, now); executions != 1 {
+		t.Errorf("executions counted = %v, want 1", executions)
+	}
+}
+
+func TestLimiterExecutionQuota(t *testing.T) {
+	now := start
+	limiter := newTestLimiter(Config{ExecutionsPerHour: 5}, &now)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		executions    int
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{name: "single execution", executions: 1, wantAllowed: true, wantRemaining: 4},
+		{name: "benchmark runs", executions: 4, wantAllowed: true, wantRemaining: 0},
+		{name: "quota used up", executions: 1, at: 30 * time.Minute, wantAllowed: false},
+		{name: "quota rolled over", executions: 5, at: time.Hour, wantAllowed: true, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		now = start.Add(tt.at)
+		reservation, err := limiter.ReserveExecutions(ctx, "user:a", tt.executions, 0)
+		if err != nil {
+			t.Fatalf("%s: ReserveExecutions() error = %v", tt.name, err)
+		}
+		if reservation.Allowed != tt.wantAllowed || reservation.Remaining != tt.wantRemaining {
+			t.Errorf("%s: allowed %v with %d remaining, want %v with %d", tt.name, reservation.Allowed, reservation.Remaining, tt.wantAllowed, tt.wantRemaining)
+		}
+		if !reservation.Allowed && reservation.RetryAfter != 30*time.Minute {
+			t.Errorf("%s: RetryAfter = %v, want 30m", tt.name, reservation.RetryAfter)
+		}
+	}
+}
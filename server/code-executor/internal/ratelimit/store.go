@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// windowBuckets is the number of buckets a rolling window is split into
+const windowBuckets = 60
+
+// sweepInterval is how often the memory store drops keys without state
+const sweepInterval = time.Minute
+
+// BucketState is the result of taking a token from a bucket
+type BucketState struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // Time until the next token is available, if not allowed
+}
+
+// WindowState is the result of reserving an amount in a rolling window
+type WindowState struct {
+	Allowed bool
+	Total   float64       // Total of the window, including the amount if it was reserved
+	Reset   time.Duration // Time until the oldest amount expires
+}
+
+// Store keeps rate limiting state. Replicas that share a Store share limits and quotas.
+type Store interface {
+	// TakeToken takes one token from the bucket refilled at rate per second up to burst
+	TakeToken(ctx context.Context, key string, rate float64, burst int, now time.Time) (BucketState, error)
+	// AddToWindow adds amount to a rolling window counter
+	AddToWindow(ctx context.Context, key string, amount float64, window time.Duration, now time.Time) error
+	// WindowTotal returns the total of a rolling window and the time until its oldest amount expires
+	WindowTotal(ctx context.Context, key string, window time.Duration, now time.Time) (float64, time.Duration, error)
+	// ReserveInWindow adds amount to a rolling window counter in one step
+	// with checking it, only if the total stays within limit
+	ReserveInWindow(ctx context.Context, key string, amount, limit float64, window time.Duration, now time.Time) (WindowState, error)
+}
+
+// bucket is a token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // When the bucket is refilled, it is no different from a new one then
+}
+
+// window is a rolling window counter split into fixed-size buckets
+type window struct {
+	size    time.Duration     // Size of one bucket
+	amounts map[int64]float64 // Amount per bucket index
+}
+
+// MemoryStore is a Store local to one process. It also stands in for a shared
+// store in tests. Full buckets and empty windows are dropped periodically.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	windows map[string]*window
+	swept   time.Time
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		windows: make(map[string]*window),
+	}
+}
+
+// TakeToken takes one token from the bucket
+func (s *MemoryStore) TakeToken(ctx context.Context, key string, rate float64, burst int, now time.Time) (BucketState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time passed since the last request
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+		return BucketState{Allowed: false, Remaining: 0, RetryAfter: wait}, nil
+	}
+
+	b.tokens--
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+	return BucketState{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// AddToWindow adds amount to a rolling window counter
+func (s *MemoryStore) AddToWindow(ctx context.Context, key string, amount float64, span time.Duration, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	w := s.window(key, span)
+	w.prune(now)
+	w.amounts[w.index(now)] += amount
+	return nil
+}
+
+// ReserveInWindow adds amount to a rolling window counter if the total stays within limit
+func (s *MemoryStore) ReserveInWindow(ctx context.Context, key string, amount, limit float64, span time.Duration, now time.Time) (WindowState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	w := s.window(key, span)
+	w.prune(now)
+	if total, reset := w.total(now); total+amount > limit {
+		return WindowState{Allowed: false, Total: total, Reset: reset}, nil
+	}
+	w.amounts[w.index(now)] += amount
+	total, reset := w.total(now)
+	return WindowState{Allowed: true, Total: total, Reset: reset}, nil
+}
+
+// WindowTotal returns the total of a rolling window
+func (s *MemoryStore) WindowTotal(ctx context.Context, key string, span time.Duration, now time.Time) (float64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.windows[key]
+	if !ok {
+		return 0, 0, nil
+	}
+	w.prune(now)
+	total, reset := w.total(now)
+	return total, reset, nil
+}
+
+// window returns the counter of a key, creating it if it does not exist. The
+// caller must hold the lock.
+func (s *MemoryStore) window(key string, span time.Duration) *window {
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{size: span / windowBuckets, amounts: make(map[int64]float64)}
+		s.windows[key] = w
+	}
+	return w
+}
+
+// sweep drops full buckets and empty windows at most once per sweepInterval,
+// so keys of callers that went away do not pile up. The caller must hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < sweepInterval {
+		return
+	}
+	s.swept = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if w.prune(now); len(w.amounts) == 0 {
+			delete(s.windows, key)
+		}
+	}
+}
+
+// index returns the bucket index of a time
+func (w *window) index(t time.Time) int64 {
+	return t.UnixNano() / int64(w.size)
+}
+
+// total returns the total of the window and the time until its oldest amount expires
+func (w *window) total(now time.Time) (float64, time.Duration) {
+	var total float64
+	oldest := int64(math.MaxInt64)
+	for index, amount := range w.amounts {
+		total += amount
+		if index < oldest {
+			oldest = index
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+
+	// The oldest bucket leaves the window once the window has moved past it
+	expires := time.Unix(0, (oldest+windowBuckets)*int64(w.size))
+	return total, expires.Sub(now)
+}
+
+// prune drops buckets that have left the window
+func (w *window) prune(now time.Time) {
+	current := w.index(now)
+	for index := range w.amounts {
+		if index <= current-windowBuckets {
+			delete(w.amounts, index)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// start is aligned to the window buckets, so amounts leave windows at whole minutes
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMemoryStoreTakeToken(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{name: "first of the burst", wantAllowed: true, wantRemaining: 2},
+		{name: "second of the burst", wantAllowed: true, wantRemaining: 1},
+		{name: "last of the burst", wantAllowed: true, wantRemaining: 0},
+		{name: "burst used up", wantAllowed: false},
+		{name: "half a token refilled", at: 500 * time.Millisecond, wantAllowed: false},
+		{name: "one token refilled", at: time.Second, wantAllowed: true, wantRemaining: 0},
+		{name: "refilled up to the burst", at: time.Hour, wantAllowed: true, wantRemaining: 2},
+	}
+	for _, tt := range tests {
+		state, err := store.TakeToken(ctx, "rate:a", 1, 3, start.Add(tt.at))
+		if err != nil {
+			t.Fatalf("%s: TakeToken() error = %v", tt.name, err)
+		}
+		if state.Allowed != tt.wantAllowed || state.Remaining != tt.wantRemaining {
+			t.Errorf("%s: allowed %v with %d remaining, want %v with %d", tt.name, state.Allowed, state.Remaining, tt.wantAllowed, tt.wantRemaining)
+		}
+		if !state.Allowed && state.RetryAfter <= 0 {
+			t.Errorf("%s: RetryAfter = %v, want a wait", tt.name, state.RetryAfter)
+		}
+	}
+
+	// Buckets are kept per key
+	if state, _ := store.TakeToken(ctx, "rate:b", 1, 3, start); !state.Allowed {
+		t.Error("bucket of another key is empty")
+	}
+}
+
+func TestMemoryStoreWindowRollover(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := store.AddToWindow(ctx, "cpu:a", 5, time.Hour, start); err != nil {
+		t.Fatalf("AddToWindow() error = %v", err)
+	}
+	if err := store.AddToWindow(ctx, "cpu:a", 3, time.Hour, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("AddToWindow() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Duration
+		wantTotal float64
+		wantReset time.Duration
+	}{
+		{name: "both amounts", at: 30 * time.Minute, wantTotal: 8, wantReset: 30 * time.Minute},
+		{name: "just before the first expires", at: 59 * time.Minute, wantTotal: 8, wantReset: time.Minute},
+		{name: "first expired", at: time.Hour, wantTotal: 3, wantReset: 30 * time.Minute},
+		{name: "both expired", at: 90 * time.Minute, wantTotal: 0, wantReset: 0},
+	}
+	for _, tt := range tests {
+		total, reset, err := store.WindowTotal(ctx, "cpu:a", time.Hour, start.Add(tt.at))
+		if err != nil {
+			t.Fatalf("%s: WindowTotal() error = %v", tt.name, err)
+		}
+		if total != tt.wantTotal || reset != tt.wantReset {
+			t.Errorf("%s: total %v resetting in %v, want %v in %v", tt.name, total, reset, tt.wantTotal, tt.wantReset)
+		}
+	}
+}
+
+func TestMemoryStoreReserveInWindow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	tests := []struct {
+		amount      float64
+		wantAllowed bool
+		wantTotal   float64
+	}{
+		{amount: 3, wantAllowed: true, wantTotal: 3},
+		{amount: 2, wantAllowed: true, wantTotal: 5},
+		{amount: 1, wantAllowed: false, wantTotal: 5},
+		{amount: -2, wantAllowed: true, wantTotal: 3},
+		{amount: 2, wantAllowed: true, wantTotal: 5},
+	}
+	for i, tt := range tests {
+		state, err := store.ReserveInWindow(ctx, "executions:a", tt.amount, 5, time.Hour, start)
+		if err != nil {
+			t.Fatalf("reservation %d: error = %v", i, err)
+		}
+		if state.Allowed != tt.wantAllowed || state.Total != tt.wantTotal {
+			t.Errorf("reservation %d of %v: allowed %v with total %v, want %v with %v", i, tt.amount, state.Allowed, state.Total, tt.wantAllowed, tt.wantTotal)
+		}
+	}
+}
+
+func TestMemoryStoreConcurrentReservations(t *testing.T) {
+	store := NewMemoryStore()
+
+	const callers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			state, err := store.ReserveInWindow(context.Background(), "executions:a", 1, 5, time.Hour, start)
+			if err != nil {
+				t.Errorf("ReserveInWindow() error = %v", err)
+				return
+			}
+			if state.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 5 {
+		t.Errorf("%d of %d reservations allowed, want 5", allowed, callers)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	store.TakeToken(ctx, "rate:a", 1, 3, start)
+	store.AddToWindow(ctx, "cpu:a", 5, time.Hour, start)
+
+	// Neither the bucket nor the window is empty yet
+	store.TakeToken(ctx, "rate:b", 1, 3, start.Add(2*sweepInterval))
+	if _, ok := store.windows["cpu:a"]; !ok {
+		t.Error("window dropped while it still counts")
+	}
+
+	// An hour later the bucket is full again and the window empty
+	store.TakeToken(ctx, "rate:b", 1, 3, start.Add(2*time.Hour))
+	if _, ok := store.buckets["rate:a"]; ok {
+		t.Error("full bucket was not dropped")
+	}
+	if _, ok := store.windows["cpu:a"]; ok {
+		t.Error("empty window was not dropped")
+	}
+}
@@ -0,0 +1,261 @@
+package records
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/docker"
+)
+
+// Record is a stored execution: everything needed to run it again and the
+// outcome to compare a new run against
+type Record struct {
+	ID     string       `json:"-"` // Assigned by the store
+	Time   time.Time    `json:"time"`
+	Caller audit.Caller `json:"caller"`
+	Config Config       `json:"config"`
+	Result Result       `json:"result"`
+}
+
+// Config is what an execution ran with
+type Config struct {
+	Language         string            `json:"language"` // Language with the version the code ran with, e.g. python@3.12
+	Code             string            `json:"code"`
+	Input            string            `json:"input,omitempty"`
+	TimeoutMs        int64             `json:"timeout_ms"`
+	CPUTimeLimitMs   int64             `json:"cpu_time_limit_ms,omitempty"`
+	UsageIntervalMs  int64             `json:"usage_interval_ms,omitempty"`
+	MemoryBytes      int64             `json:"memory_bytes"`
+	CPU              float64           `json:"cpu"`
+	StdoutLimit      int64             `json:"stdout_limit,omitempty"`
+	StderrLimit      int64             `json:"stderr_limit,omitempty"`
+	CombinedOutput   bool              `json:"combined_output,omitempty"`
+	Trusted          bool              `json:"trusted,omitempty"`
+	Runtime          string            `json:"runtime,omitempty"` // OCI runtime the code ran with
+	Artifacts        []string          `json:"artifacts,omitempty"`
+	ArtifactMaxFiles int               `json:"artifact_max_files,omitempty"`
+	ArtifactMaxBytes int64             `json:"artifact_max_bytes,omitempty"`
+	Fixtures         []Fixture         `json:"fixtures,omitempty"`
+	Args             []string          `json:"args,omitempty"`
+	Env              map[string]string `json:"env,omitempty"`
+	Manifest         *Fixture          `json:"manifest,omitempty"`
+	Determinism      *Determinism      `json:"determinism,omitempty"` // Settings the run applied
+	ImageDigest      string            `json:"image_digest"`
+}
+
+// Fixture is a file placed in /tmp before the run
+type Fixture struct {
+	Name string `json:"name"`
+	Data []byte `json:"data"`
+}
+
+// Determinism are the settings of a deterministic run
+type Determinism struct {
+	Seed     uint32    `json:"seed"`
+	FakeTime time.Time `json:"fake_time"`
+	PinCPU   bool      `json:"pin_cpu,omitempty"`
+	Timezone string    `json:"timezone"`
+	Locale   string    `json:"locale"`
+	CPUSet   string    `json:"cpu_set,omitempty"`
+}
+
+// Result is the outcome of an execution. Timings are kept for reference but
+// not compared, as they differ between runs.
+type Result struct {
+	Stdout             string     `json:"stdout"`
+	Stderr             string     `json:"stderr"`
+	ExitCode           int        `json:"exit_code"`
+	Timeout            bool       `json:"timeout,omitempty"`
+	CPUTimeExceeded    bool       `json:"cpu_time_exceeded,omitempty"`
+	StdoutTruncated    bool       `json:"stdout_truncated,omitempty"`
+	StderrTruncated    bool       `json:"stderr_truncated,omitempty"`
+	StdoutBytes        int64      `json:"stdout_bytes"`
+	StderrBytes        int64      `json:"stderr_bytes"`
+	Artifacts          []Artifact `json:"artifacts,omitempty"`
+	ArtifactsTruncated bool       `json:"artifacts_truncated,omitempty"`
+	Version            string     `json:"version"`
+	ImageDigest        string     `json:"image_digest"`
+	ExecutionTimeMs    int64      `json:"execution_time_ms"`
+	CPUTimeMs          int64      `json:"cpu_time_ms"`
+}
+
+// Artifact is a file the program left in /tmp, identified by its content hash
+type Artifact struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Difference is a field of the result that changed between the stored run and a replay
+type Difference struct {
+	Field    string `json:"field"`
+	Stored   string `json:"stored"`
+	Replayed string `json:"replayed"`
+}
+
+// New returns the record of an execution
+func New(caller audit.Caller, config docker.ExecutionConfig, result *docker.ExecutionResult) Record {
+	record := Record{
+		Time:   time.Now().UTC(),
+		Caller: caller,
+		Config: Config{
+			Language:         config.Language,
+			Code:             config.Code,
+			Input:            config.Input,
+			TimeoutMs:        config.Timeout.Milliseconds(),
+			CPUTimeLimitMs:   config.CPUTimeLimit.Milliseconds(),
+			UsageIntervalMs:  config.UsageInterval.Milliseconds(),
+			MemoryBytes:      config.MemoryLimit,
+			CPU:              config.CPULimit,
+			StdoutLimit:      config.StdoutLimit,
+			StderrLimit:      config.StderrLimit,
+			CombinedOutput:   config.CombinedOutput,
+			Trusted:          config.Trusted,
+			Runtime:          result.Runtime,
+			Artifacts:        config.Artifacts,
+			ArtifactMaxFiles: config.ArtifactMaxFiles,
+			ArtifactMaxBytes: config.ArtifactMaxBytes,
+			Args:             config.Args,
+			Env:              config.Env,
+			ImageDigest:      result.ImageDigest,
+		},
+		Result: NewResult(result),
+	}
+	if language, _, err := docker.ResolveLanguage(config.Language); err == nil && result.Version != "" {
+		record.Config.Language = language.Name + "@" + result.Version
+	}
+	for _, fixture := range config.Fixtures {
+		record.Config.Fixtures = append(record.Config.Fixtures, Fixture{Name: fixture.Name, Data: fixture.Data})
+	}
+	if config.Manifest != nil {
+		record.Config.Manifest = &Fixture{Name: config.Manifest.Name, Data: config.Manifest.Data}
+	}
+	if d := result.Determinism; d != nil {
+		record.Config.Determinism = &Determinism{
+			Seed:     d.Seed,
+			FakeTime: d.FakeTime,
+			PinCPU:   d.PinCPU,
+			Timezone: d.Timezone,
+			Locale:   d.Locale,
+			CPUSet:   d.CPUSet,
+		}
+	}
+	return record
+}
+
+// NewResult returns the outcome of an execution to store or compare
+func NewResult(result *docker.ExecutionResult) Result {
+	stored := Result{
+		Stdout:             result.Stdout,
+		Stderr:             result.Stderr,
+		ExitCode:           result.ExitCode,
+		Timeout:            result.Timeout,
+		CPUTimeExceeded:    result.CPUTimeExceeded,
+		StdoutTruncated:    result.StdoutTruncated,
+		StderrTruncated:    result.StderrTruncated,
+		StdoutBytes:        result.StdoutBytes,
+		StderrBytes:        result.StderrBytes,
+		ArtifactsTruncated: result.ArtifactsTruncated,
+		Version:            result.Version,
+		ImageDigest:        result.ImageDigest,
+		ExecutionTimeMs:    result.ExecutionTime.Milliseconds(),
+		CPUTimeMs:          result.CPUTime().Milliseconds(),
+	}
+	for _, artifact := range result.Artifacts {
+		sum := sha256.Sum256(artifact.Data)
+		stored.Artifacts = append(stored.Artifacts, Artifact{
+			Path:   artifact.Path,
+			Size:   artifact.Size,
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	return stored
+}
+
+// Execution returns the configuration repeating the execution with the same
+// code, inputs, limits, image and determinism settings
+func (c Config) Execution() docker.ExecutionConfig {
+	config := docker.ExecutionConfig{
+		Language:         c.Language,
+		Code:             c.Code,
+		Input:            c.Input,
+		Timeout:          time.Duration(c.TimeoutMs) * time.Millisecond,
+		CPUTimeLimit:     time.Duration(c.CPUTimeLimitMs) * time.Millisecond,
+		UsageInterval:    time.Duration(c.UsageIntervalMs) * time.Millisecond,
+		MemoryLimit:      c.MemoryBytes,
+		CPULimit:         c.CPU,
+		StdoutLimit:      c.StdoutLimit,
+		StderrLimit:      c.StderrLimit,
+		CombinedOutput:   c.CombinedOutput,
+		Trusted:          c.Trusted,
+		Runtime:          c.Runtime,
+		Artifacts:        c.Artifacts,
+		ArtifactMaxFiles: c.ArtifactMaxFiles,
+		ArtifactMaxBytes: c.ArtifactMaxBytes,
+		Args:             c.Args,
+		Env:              c.Env,
+		ImageDigest:      c.ImageDigest,
+	}
+	for _, fixture := range c.Fixtures {
+		config.Fixtures = append(config.Fixtures, docker.Fixture{Name: fixture.Name, Data: fixture.Data})
+	}
+	if c.Manifest != nil {
+		config.Manifest = &docker.Manifest{Name: c.Manifest.Name, Data: c.Manifest.Data}
+	}
+	if d := c.Determinism; d != nil {
+		config.Determinism = &docker.Determinism{
+			Seed:     d.Seed,
+			FakeTime: d.FakeTime,
+			PinCPU:   d.PinCPU,
+			CPUSet:   d.CPUSet,
+		}
+	}
+	return config
+}
+
+// Diff returns the fields of the result that differ between the stored run
+// and a replay, in a fixed order
+func Diff(stored, replayed Result) []Difference {
+	var differences []Difference
+	add := func(field string, a, b interface{}) {
+		if before, after := fmt.Sprint(a), fmt.Sprint(b); before != after {
+			differences = append(differences, Difference{Field: field, Stored: before, Replayed: after})
+		}
+	}
+	add("stdout", stored.Stdout, replayed.Stdout)
+	add("stderr", stored.Stderr, replayed.Stderr)
+	add("exit_code", stored.ExitCode, replayed.ExitCode)
+	add("timeout", stored.Timeout, replayed.Timeout)
+	add("cpu_time_exceeded", stored.CPUTimeExceeded, replayed.CPUTimeExceeded)
+	add("stdout_truncated", stored.StdoutTruncated, replayed.StdoutTruncated)
+	add("stderr_truncated", stored.StderrTruncated, replayed.StderrTruncated)
+	add("stdout_bytes", stored.StdoutBytes, replayed.StdoutBytes)
+	add("stderr_bytes", stored.StderrBytes, replayed.StderrBytes)
+	add("artifacts_truncated", stored.ArtifactsTruncated, replayed.ArtifactsTruncated)
+	add("version", stored.Version, replayed.Version)
+	add("image_digest", stored.ImageDigest, replayed.ImageDigest)
+
+	// Artifacts are compared by path, so one changed file is one difference
+	artifacts := make(map[string]Artifact)
+	for _, artifact := range replayed.Artifacts {
+		artifacts[artifact.Path] = artifact
+	}
+	for _, artifact := range stored.Artifacts {
+		other, ok := artifacts[artifact.Path]
+		delete(artifacts, artifact.Path)
+		if !ok {
+			add("artifacts/"+artifact.Path, artifact.SHA256, "")
+			continue
+		}
+		add("artifacts/"+artifact.Path, artifact.SHA256, other.SHA256)
+	}
+	for _, artifact := range replayed.Artifacts {
+		if _, ok := artifacts[artifact.Path]; ok {
+			add("artifacts/"+artifact.Path, "", artifact.SHA256)
+		}
+	}
+	return differences
+}
@@ -0,0 +1,59 @@
+package records
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code-executor/internal/blob"
+)
+
+// ErrNotFound is returned for records that do not exist or have expired
+var ErrNotFound = errors.New("execution record not found")
+
+// Store keeps execution records as JSON blobs for a limited time
+type Store struct {
+	blobs *blob.Store
+}
+
+// NewStore creates a store in dir keeping records for ttl, forever if zero
+func NewStore(dir string, ttl time.Duration) (*Store, error) {
+	blobs, err := blob.NewStore(dir, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{blobs: blobs}, nil
+}
+
+// Put stores a record and returns its ID
+func (s *Store) Put(record Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode execution record: %w", err)
+	}
+	return s.blobs.Put(data)
+}
+
+// Get returns the record with the ID
+func (s *Store) Get(id string) (Record, error) {
+	data, err := s.blobs.Get(id)
+	if errors.Is(err, blob.ErrNotFound) {
+		return Record{}, ErrNotFound
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Record{}, fmt.Errorf("failed to decode execution record: %w", err)
+	}
+	record.ID = id
+	return record, nil
+}
+
+// Sweep removes expired records and returns how many were removed
+func (s *Store) Sweep(now time.Time) (int, error) {
+	return s.blobs.Sweep(now)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+
+	"code-executor/internal/blob"
+	"code-executor/internal/docker"
+	"github.com/gin-gonic/gin"
+)
+
+// Artifact represents a file produced by the program
+type Artifact struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Content []byte `json:"content_base64,omitempty"` // Inline content without an artifact store
+	URL     string `json:"url,omitempty"`            // Download URL with an artifact store
+}
+
+// artifactResponses returns the artifacts of a run, stored for download if an
+// artifact store is configured and inline otherwise
+func (s *Server) artifactResponses(artifacts []docker.Artifact) ([]Artifact, error) {
+	responses := make([]Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		response := Artifact{Path: artifact.Path, Size: artifact.Size}
+		if s.artifactStore == nil {
+			response.Content = artifact.Data
+		} else {
+			id, err := s.artifactStore.Put(artifact.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store artifact %s: %w", artifact.Path, err)
+			}
+			response.URL = "/api/v1/artifacts/" + id + "/" + url.PathEscape(path.Base(artifact.Path))
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// downloadArtifact serves a stored artifact. The name only sets the file name
+// of the download, the random ID identifies the artifact.
+func (s *Server) downloadArtifact(c *gin.Context) {
+	if s.artifactStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "artifact store is not enabled"})
+		return
+	}
+
+	file, err := s.artifactStore.Open(c.Param("id"))
+	if errors.Is(err, blob.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := c.Param("name")
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.DataFromReader(http.StatusOK, info.Size(), contentType, file, nil)
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"code-executor/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// recordAudit appends an entry to the audit log, if enabled
+func (s *Server) recordAudit(entry audit.Entry) {
+	if s.auditLog == nil {
+		return
+	}
+	if _, err := s.auditLog.Append(entry); err != nil {
+		log.Printf("Failed to write audit entry: %v", err)
+	}
+}
+
+// exportAudit streams the audit entries recorded in a time range as JSON lines
+func (s *Server) exportAudit(c *gin.Context) {
+	if s.auditLog == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "audit log is not enabled"})
+		return
+	}
+
+	var from, to time.Time
+	for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + " time, expected RFC 3339: " + err.Error()})
+			return
+		}
+		*t = parsed
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	if _, err := s.auditLog.Export(c.Writer, from, to); err != nil {
+		// The status is already sent, so the export just ends early
+		log.Printf("Failed to export audit log: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"code-executor/internal/auth"
+	"code-executor/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// identityContextKey is the gin context key holding the caller's identity
+const identityContextKey = "identity"
+
+// authenticate verifies the API key or bearer token of a request and stores
+// the caller's identity in the gin and request contexts
+func (s *Server) authenticate(c *gin.Context) {
+	if s.authenticator == nil {
+		c.Next()
+		return
+	}
+
+	token := auth.BearerToken(c.GetHeader("Authorization"))
+	if token == "" {
+		token = c.GetHeader("X-API-Key")
+	}
+
+	identity, err := s.authenticator.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		status := http.StatusUnauthorized
+		if !errors.Is(err, auth.ErrMissingCredentials) && !errors.Is(err, auth.ErrInvalidCredentials) {
+			status = http.StatusInternalServerError
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="code-executor"`)
+		c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(identityContextKey, identity)
+	c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+	c.Next()
+}
+
+// requireScope rejects callers that were not granted the scope
+func (s *Server) requireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.authenticator == nil {
+			c.Next()
+			return
+		}
+
+		identity, ok := auth.FromContext(c.Request.Context())
+		if !ok || !identity.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing scope: " + string(scope)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// callerUserID returns the user of a verified platform token, falling back
+// to the user named in the request for callers such as service API keys
+func callerUserID(c *gin.Context, requested string) string {
+	if identity, ok := auth.FromContext(c.Request.Context()); ok && identity.UserID != "" {
+		return identity.UserID
+	}
+	return requested
+}
+
+// hintLearner returns whom hints are revealed to: the caller's user or the
+// user named by a service caller, else the caller's quota subject
+func hintLearner(c *gin.Context, requested string) string {
+	if userID := callerUserID(c, requested); userID != "" {
+		return "user:" + userID
+	}
+	return ratelimit.Subject(c.Request.Context(), c.ClientIP())
+}
+
+// cors allows cross-origin requests from the configured origins only
+func (s *Server) cors(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	if origin != "" && s.allowsOrigin(origin) {
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Allow-Methods", "POST, GET, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		c.Header("Vary", "Origin")
+	}
+
+	if c.Request.Method == "OPTIONS" {
+		c.AbortWithStatus(204)
+		return
+	}
+
+	c.Next()
+}
+
+// allowsOrigin reports whether cross-origin requests from origin are allowed
+func (s *Server) allowsOrigin(origin string) bool {
+	for _, allowed := range s.corsOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/benchmark"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// BenchmarkRequest represents the REST API request for a benchmark
+type BenchmarkRequest struct {
+	Language       string   `json:"language" binding:"required"`
+	Code           string   `json:"code" binding:"required"`
+	Input          string   `json:"input,omitempty"`
+	Args           []string `json:"args,omitempty"`
+	Runs           int      `json:"runs,omitempty"`           // Measured runs
+	Warmup         *int     `json:"warmup,omitempty"`         // Runs before the measured ones, 0 for none and 1 if missing
+	ReferenceCode  string   `json:"reference_code,omitempty"` // Reference solution in the same language to compare against
+	TimeoutSeconds int32    `json:"timeout_seconds,omitempty"`
+	MemoryLimitMB  int64    `json:"memory_limit_mb,omitempty"`
+	CPULimit       float64  `json:"cpu_limit,omitempty"`
+	CPUTimeLimitMs int64    `json:"cpu_time_limit_ms,omitempty" binding:"min=0"`
+}
+
+// BenchmarkResponse represents the summarized runs of a submission and its reference
+type BenchmarkResponse struct {
+	Submission BenchmarkMeasurements  `json:"submission"`
+	Reference  *BenchmarkMeasurements `json:"reference,omitempty"`
+	Score      float64                `json:"score,omitempty"` // Median CPU time of the reference relative to the submission's
+	Noisy      bool                   `json:"noisy"`
+}
+
+// BenchmarkMeasurements represents the usage of a program over the measured runs
+type BenchmarkMeasurements struct {
+	Runs        int               `json:"runs"`
+	CPUTimeUs   benchmark.Summary `json:"cpu_time_us"`
+	MemoryBytes benchmark.Summary `json:"memory_bytes"` // Peak resident memory
+	Error       string            `json:"error,omitempty"`
+}
+
+// runBenchmark runs a submission repeatedly in a warm container and summarizes
+// its CPU time and memory, optionally against a reference solution
+func (s *Server) runBenchmark(c *gin.Context) {
+	var req BenchmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(c.Request.Context()).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMB*1024*1024, // Convert MB to bytes
+		req.CPULimit,
+	)
+	bench := benchmark.Request{
+		Config: docker.ExecutionConfig{
+			Language:     req.Language,
+			Code:         req.Code,
+			Input:        req.Input,
+			Args:         req.Args,
+			Timeout:      timeout,
+			MemoryLimit:  memoryLimit,
+			CPULimit:     cpuLimit,
+			CPUTimeLimit: time.Duration(req.CPUTimeLimitMs) * time.Millisecond,
+			Trusted:      auth.IsTrusted(c.Request.Context()),
+		},
+		Runs:      req.Runs,
+		Warmup:    req.Warmup,
+		Reference: req.ReferenceCode,
+	}
+	if err := benchmark.Validate(bench); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Every run reserves an execution and its worst-case CPU time in the
+	// caller's quotas and is audited
+	caller := audit.CallerFromContext(c.Request.Context(), c.ClientIP())
+	run := func(ctx context.Context, config docker.ExecutionConfig, runs int) ([]*docker.ExecutionResult, error) {
+		reservation, ok := s.reserveExecutions(c, runs, time.Duration(runs)*ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit))
+		if !ok {
+			return nil, errQuotaExceeded
+		}
+		results, err := s.dockerManager.Benchmark(ctx, config, runs)
+		s.settleCPUTime(c, reservation, results...)
+		if err != nil {
+			s.recordAudit(audit.ExecutionEntry(caller, config, nil, err))
+		}
+		for _, result := range results {
+			s.recordAudit(audit.ExecutionEntry(caller, config, result, nil))
+		}
+		return results, err
+	}
+	result, err := benchmark.Run(c.Request.Context(), run, bench)
+
+	if errors.Is(err, errQuotaExceeded) {
+		return
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, benchmark.ErrReference) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "benchmark failed: " + err.Error()})
+		return
+	}
+
+	response := BenchmarkResponse{
+		Submission: benchmarkMeasurements(result.Submission),
+		Score:      result.Score,
+		Noisy:      result.Noisy,
+	}
+	if result.Reference != nil {
+		reference := benchmarkMeasurements(*result.Reference)
+		response.Reference = &reference
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// benchmarkMeasurements returns the response of the measurements of a program
+func benchmarkMeasurements(measurements benchmark.Measurements) BenchmarkMeasurements {
+	return BenchmarkMeasurements{
+		Runs:        measurements.Runs,
+		CPUTimeUs:   measurements.CPU,
+		MemoryBytes: measurements.Memory,
+		Error:       measurements.Error,
+	}
+}
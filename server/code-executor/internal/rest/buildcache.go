@@ -0,0 +1,25 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buildCacheStats returns the build cache metrics
+func (s *Server) buildCacheStats(c *gin.Context) {
+	if s.buildCache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "build cache is not enabled"})
+		return
+	}
+	c.JSON(http.StatusOK, s.buildCache.Stats())
+}
+
+// clearBuildCache drops all cached build outputs
+func (s *Server) clearBuildCache(c *gin.Context) {
+	if s.buildCache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "build cache is not enabled"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"removed": s.buildCache.Clear()})
+}
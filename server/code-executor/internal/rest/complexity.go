@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/complexity"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// ComplexityRequest represents the REST API request for a complexity analysis
+type ComplexityRequest struct {
+	Language       string            `json:"language" binding:"required"`
+	Code           string            `json:"code" binding:"required"`
+	Generator      ComplexityProgram `json:"generator" binding:"required"` // Prints the input of the size in its first argument
+	Sizes          []int             `json:"sizes" binding:"required"`
+	Repeats        int               `json:"repeats,omitempty"` // Runs per size, the fastest counts
+	TimeoutSeconds int32             `json:"timeout_seconds,omitempty"`
+	MemoryLimitMB  int64             `json:"memory_limit_mb,omitempty"`
+	CPULimit       float64           `json:"cpu_limit,omitempty"`
+}
+
+// ComplexityProgram represents code in a language
+type ComplexityProgram struct {
+	Language string `json:"language" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// ComplexityResponse represents the measurements and fitted classes of a submission
+type ComplexityResponse struct {
+	Measurements []ComplexityMeasurement `json:"measurements"`
+	CPU          *ComplexityFit          `json:"cpu,omitempty"`    // Omitted if the submission failed before enough sizes
+	Memory       *ComplexityFit          `json:"memory,omitempty"` // Omitted if the submission failed before enough sizes
+}
+
+// ComplexityMeasurement represents the usage of the submission at an input size
+type ComplexityMeasurement struct {
+	Size        int    `json:"size"`
+	InputBytes  int64  `json:"input_bytes"`
+	CPUTimeUs   int64  `json:"cpu_time_us"`
+	MemoryBytes int64  `json:"memory_bytes"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ComplexityFit represents the class that best explains a series of measurements
+type ComplexityFit struct {
+	Class      string                 `json:"class"`
+	Confidence float64                `json:"confidence"`
+	Candidates []complexity.Candidate `json:"candidates"`
+}
+
+// analyzeComplexity runs a submission on generated inputs of increasing size
+// and fits its CPU time and memory to complexity classes
+func (s *Server) analyzeComplexity(c *gin.Context) {
+	var req ComplexityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(c.Request.Context()).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMB*1024*1024, // Convert MB to bytes
+		req.CPULimit,
+	)
+	analysis := complexity.Request{
+		Submission: complexity.Program{Language: req.Language, Code: req.Code},
+		Generator:  complexity.Program{Language: req.Generator.Language, Code: req.Generator.Code},
+		Sizes:      req.Sizes,
+		Repeats:    req.Repeats,
+		Limits: docker.ExecutionConfig{
+			Timeout:     timeout,
+			MemoryLimit: memoryLimit,
+			CPULimit:    cpuLimit,
+			Trusted:     auth.IsTrusted(c.Request.Context()),
+		},
+	}
+	if err := complexity.Validate(analysis); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Every run reserves an execution and its worst-case CPU time in the
+	// caller's quotas and is audited
+	caller := audit.CallerFromContext(c.Request.Context(), c.ClientIP())
+	execute := func(ctx context.Context, config docker.ExecutionConfig) (*docker.ExecutionResult, error) {
+		reservation, ok := s.reserveExecutions(c, 1, ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit))
+		if !ok {
+			return nil, errQuotaExceeded
+		}
+		result, err := s.dockerManager.Execute(ctx, config)
+		s.settleCPUTime(c, reservation, result)
+		s.recordAudit(audit.ExecutionEntry(caller, config, result, err))
+		return result, err
+	}
+	result, err := complexity.Analyze(c.Request.Context(), execute, analysis)
+
+	if errors.Is(err, errQuotaExceeded) {
+		return
+	}
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, complexity.ErrGenerator) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "complexity analysis failed: " + err.Error()})
+		return
+	}
+
+	response := ComplexityResponse{
+		Measurements: []ComplexityMeasurement{},
+		CPU:          complexityFit(result.CPU),
+		Memory:       complexityFit(result.Memory),
+	}
+	for _, measurement := range result.Measurements {
+		response.Measurements = append(response.Measurements, ComplexityMeasurement{
+			Size:        measurement.Size,
+			InputBytes:  measurement.InputBytes,
+			CPUTimeUs:   measurement.CPUTime.Microseconds(),
+			MemoryBytes: measurement.Memory,
+			Error:       measurement.Error,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// complexityFit returns the response of a fit, nil if there is none
+func complexityFit(fit *complexity.Fit) *ComplexityFit {
+	if fit == nil {
+		return nil
+	}
+	return &ComplexityFit{
+		Class:      fit.Class,
+		Confidence: fit.Confidence,
+		Candidates: fit.Candidates,
+	}
+}
@@ -0,0 +1,17 @@
+package rest
+
+import "code-executor/internal/docker"
+
+// Manifest represents a dependency manifest, e.g. requirements.txt, submitted with the code
+type Manifest struct {
+	Name    string `json:"name" binding:"required"`
+	Content string `json:"content"`
+}
+
+// toDocker returns the manifest for the Docker manager, nil without one
+func (m *Manifest) toDocker() *docker.Manifest {
+	if m == nil {
+		return nil
+	}
+	return &docker.Manifest{Name: m.Name, Data: []byte(m.Content)}
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"time"
+
+	"code-executor/internal/docker"
+)
+
+// Determinism represents the settings of a deterministic run. Requests set the
+// seed, fake time and CPU pinning, responses also report what the run fixed.
+type Determinism struct {
+	Seed     uint32     `json:"seed,omitempty"`      // Seed of the language runtime, 1 if zero
+	FakeTime *time.Time `json:"fake_time,omitempty"` // Start of a faked wall clock
+	PinCPU   bool       `json:"pin_cpu,omitempty"`   // Run on a single CPU
+	Timezone string     `json:"timezone,omitempty"`
+	Locale   string     `json:"locale,omitempty"`
+	CPUSet   string     `json:"cpu_set,omitempty"` // CPU the run was pinned to
+}
+
+// toDocker returns the settings for the Docker manager, nil for a normal run
+func (d *Determinism) toDocker() *docker.Determinism {
+	if d == nil {
+		return nil
+	}
+	determinism := &docker.Determinism{Seed: d.Seed, PinCPU: d.PinCPU, CPUSet: d.CPUSet}
+	if d.FakeTime != nil {
+		determinism.FakeTime = *d.FakeTime
+	}
+	return determinism
+}
+
+// determinismResponse returns the settings a run applied, nil for a normal run
+func determinismResponse(d *docker.Determinism) *Determinism {
+	if d == nil {
+		return nil
+	}
+	response := &Determinism{
+		Seed:     d.Seed,
+		PinCPU:   d.PinCPU,
+		Timezone: d.Timezone,
+		Locale:   d.Locale,
+		CPUSet:   d.CPUSet,
+	}
+	if !d.FakeTime.IsZero() {
+		fakeTime := d.FakeTime
+		response.FakeTime = &fakeTime
+	}
+	return response
+}
@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"code-executor/internal/auth"
+	"code-executor/internal/blob"
+	"code-executor/internal/docker"
+	"github.com/gin-gonic/gin"
+)
+
+// Fixture represents a data file attached to an execution, uploaded inline or referenced by ID
+type Fixture struct {
+	Name    string `json:"name"`
+	Content []byte `json:"content_base64,omitempty"`
+	ID      string `json:"id,omitempty"` // Fixture uploaded to the fixture store
+}
+
+// FixtureDigest represents the integrity of a fixture the program ran with
+type FixtureDigest struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// FixtureUploadResponse represents a fixture stored for later executions
+type FixtureUploadResponse struct {
+	ID     string `json:"id"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// resolveFixtures returns the fixtures of a request with their content, loading
+// referenced ones from the fixture store
+func (s *Server) resolveFixtures(fixtures []Fixture) ([]docker.Fixture, error) {
+	resolved := make([]docker.Fixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		data := fixture.Content
+		if fixture.ID != "" {
+			if fixture.Content != nil {
+				return nil, fmt.Errorf("%w: %q has both content and an ID", docker.ErrInvalidFixture, fixture.Name)
+			}
+			if s.fixtureStore == nil {
+				return nil, fmt.Errorf("%w: fixture store is not enabled", docker.ErrInvalidFixture)
+			}
+			var err error
+			data, err = s.fixtureStore.Get(fixture.ID)
+			if errors.Is(err, blob.ErrNotFound) {
+				return nil, fmt.Errorf("%w: unknown fixture ID %q", docker.ErrInvalidFixture, fixture.ID)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		resolved = append(resolved, docker.Fixture{Name: fixture.Name, Data: data})
+	}
+
+	if err := docker.ValidateFixtures(resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// uploadFixture stores the request body as a fixture executions can reference
+func (s *Server) uploadFixture(c *gin.Context) {
+	if s.fixtureStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fixture store is not enabled"})
+		return
+	}
+	// Fixtures are kept indefinitely, so only trusted callers such as instructors may add them
+	if s.authenticator != nil && !auth.IsTrusted(c.Request.Context()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only trusted callers may upload fixtures"})
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, docker.MaxFixtureBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("fixtures must not exceed %d bytes", docker.MaxFixtureBytes)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read fixture: " + err.Error()})
+		return
+	}
+
+	id, err := s.fixtureStore.Put(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	c.JSON(http.StatusCreated, FixtureUploadResponse{
+		ID:     id,
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"code-executor/internal/docker"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadyResponse represents the readiness check response
+type ReadyResponse struct {
+	Status        string   `json:"status"`
+	MissingImages []string `json:"missing_images,omitempty"`
+}
+
+// ImageResponse represents an execution image of the language registry
+type ImageResponse struct {
+	Image      string     `json:"image"`
+	Languages  []string   `json:"languages"`
+	Required   bool       `json:"required"`
+	Built      bool       `json:"built"`
+	Pinned     string     `json:"pinned,omitempty"`
+	Installed  bool       `json:"installed"`
+	ID         string     `json:"id,omitempty"`
+	Digest     string     `json:"digest,omitempty"`
+	Size       int64      `json:"size,omitempty"`
+	LastPulled *time.Time `json:"last_pulled,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// ImagePullRequest represents a request to pull an execution image
+type ImagePullRequest struct {
+	Image string `json:"image" binding:"required"`
+}
+
+// ready handles readiness checks. Executions would wait for image pulls
+// until the required images are installed, so the service reports not ready.
+func (s *Server) ready(c *gin.Context) {
+	missing, err := s.dockerManager.MissingImages(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to check images: " + err.Error()})
+		return
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusServiceUnavailable, ReadyResponse{Status: "not_ready", MissingImages: missing})
+		return
+	}
+	c.JSON(http.StatusOK, ReadyResponse{Status: "ready"})
+}
+
+// listImages returns the status of the execution images
+func (s *Server) listImages(c *gin.Context) {
+	images, err := s.dockerManager.Images(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list images: " + err.Error()})
+		return
+	}
+
+	response := make([]ImageResponse, 0, len(images))
+	for _, image := range images {
+		info := ImageResponse{
+			Image:     image.Image,
+			Languages: image.Languages,
+			Required:  image.Required,
+			Built:     image.Built,
+			Pinned:    image.Pinned,
+			Installed: image.Installed,
+			ID:        image.ID,
+			Digest:    image.Digest,
+			Size:      image.Size,
+			LastError: image.LastError,
+		}
+		if !image.LastPulled.IsZero() {
+			info.LastPulled = &image.LastPulled
+		}
+		response = append(response, info)
+	}
+	c.JSON(http.StatusOK, gin.H{"images": response})
+}
+
+// pullImage pulls an execution image, updating an unpinned tag, or rebuilds a defined one
+func (s *Server) pullImage(c *gin.Context) {
+	var req ImagePullRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := s.dockerManager.PullImage(c.Request.Context(), req.Image)
+	if errors.Is(err, docker.ErrUnknownImage) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"image": req.Image, "pulled": true})
+}
+
+// removeImage removes an execution image. It is pulled again before the next
+// execution that needs it.
+func (s *Server) removeImage(c *gin.Context) {
+	tag := c.Query("image")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image is required"})
+		return
+	}
+
+	err := s.dockerManager.RemoveImage(c.Request.Context(), tag)
+	if errors.Is(err, docker.ErrUnknownImage) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"image": tag, "removed": true})
+}
@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageResponse represents a supported language and its versions
+type LanguageResponse struct {
+	Name     string            `json:"name"`
+	Aliases  []string          `json:"aliases,omitempty"`
+	Versions []VersionResponse `json:"versions"`
+}
+
+// VersionResponse represents a version of a language
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Image     string `json:"image"`
+	Digest    string `json:"digest,omitempty"`
+	Installed bool   `json:"installed"`
+	Default   bool   `json:"default"`
+	Pinned    string `json:"pinned,omitempty"`
+}
+
+// listLanguages returns the supported languages, their versions and which are installed
+func (s *Server) listLanguages(c *gin.Context) {
+	languages, err := s.dockerManager.Languages(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list languages: " + err.Error()})
+		return
+	}
+
+	response := make([]LanguageResponse, 0, len(languages))
+	for _, language := range languages {
+		info := LanguageResponse{Name: language.Name, Aliases: language.Aliases}
+		for _, version := range language.Versions {
+			info.Versions = append(info.Versions, VersionResponse{
+				Version:   version.Version,
+				Image:     version.Image,
+				Digest:    version.Digest,
+				Installed: version.Installed,
+				Default:   version.Default,
+				Pinned:    version.Pinned,
+			})
+		}
+		response = append(response, info)
+	}
+	c.JSON(http.StatusOK, gin.H{"languages": response})
+}
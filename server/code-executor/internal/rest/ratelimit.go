@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimit rejects requests of callers exceeding their request rate
+func (s *Server) rateLimit(c *gin.Context) {
+	if s.limiter == nil {
+		c.Next()
+		return
+	}
+
+	subject := ratelimit.Subject(c.Request.Context(), c.ClientIP())
+	decision, err := s.limiter.Allow(c.Request.Context(), subject)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !s.applyDecision(c, decision) {
+		return
+	}
+	c.Next()
+}
+
+// reserveExecutions reserves executions and their worst-case CPU time in the
+// caller's quotas, rejecting callers that used up their quotas
+func (s *Server) reserveExecutions(c *gin.Context, executions int, cpu time.Duration) (ratelimit.Reservation, bool) {
+	if s.limiter == nil {
+		return ratelimit.Reservation{}, true
+	}
+
+	subject := ratelimit.Subject(c.Request.Context(), c.ClientIP())
+	reservation, err := s.limiter.ReserveExecutions(c.Request.Context(), subject, executions, cpu)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return reservation, false
+	}
+	return reservation, s.applyDecision(c, reservation.Decision)
+}
+
+// errQuotaExceeded ends a request running several executions once one of them
+// was rejected; the rejection has already been written
+var errQuotaExceeded = errors.New("execution quota exceeded")
+
+// settleCPUTime replaces the CPU time reserved for executions with the CPU
+// time of their results
+func (s *Server) settleCPUTime(c *gin.Context, reservation ratelimit.Reservation, results ...*docker.ExecutionResult) {
+	if s.limiter == nil {
+		return
+	}
+
+	var used time.Duration
+	for _, result := range results {
+		if result != nil {
+			used += result.CPUTime()
+		}
+	}
+	if err := s.limiter.SettleCPU(c.Request.Context(), reservation, used); err != nil {
+		log.Printf("Failed to settle CPU quota: %v", err)
+	}
+}
+
+// applyDecision sets the RateLimit-* headers and aborts the request if it was not allowed
+func (s *Server) applyDecision(c *gin.Context, decision ratelimit.Decision) bool {
+	if decision.Limit > 0 {
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(decision.Reset)))
+	}
+
+	if !decision.Allowed {
+		c.Header("Retry-After", strconv.Itoa(seconds(decision.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": decision.Reason})
+		return false
+	}
+	return true
+}
+
+// seconds rounds a duration up to whole seconds for use in headers
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"code-executor/internal/audit"
+	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"code-executor/internal/records"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplayRequest represents the options of replaying a stored execution
+type ReplayRequest struct {
+	// Run on the current image instead of the recorded one, optionally of
+	// another version, e.g. to check a toolchain upgrade
+	LatestImage bool   `json:"latest_image,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+// ReplayResponse represents the result of a replay compared with the stored one
+type ReplayResponse struct {
+	ExecutionID string               `json:"execution_id"`
+	Identical   bool                 `json:"identical"`
+	Differences []records.Difference `json:"differences,omitempty"`
+	Stored      records.Result       `json:"stored"`
+	Result      ExecuteResponse      `json:"result"`
+}
+
+// storeRecord stores the record of an execution and returns its ID, empty if
+// records are not enabled or storing failed
+func (s *Server) storeRecord(caller audit.Caller, config docker.ExecutionConfig, result *docker.ExecutionResult) string {
+	if s.records == nil {
+		return ""
+	}
+	id, err := s.records.Put(records.New(caller, config, result))
+	if err != nil {
+		log.Printf("Failed to store execution record: %v", err)
+		return ""
+	}
+	return id
+}
+
+// replayExecution runs a stored execution again with the same code, inputs,
+// limits, image and determinism settings and diffs the result with the stored one
+func (s *Server) replayExecution(c *gin.Context) {
+	if s.records == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution records are not enabled"})
+		return
+	}
+
+	var req ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	record, err := s.records.Get(c.Param("id"))
+	if errors.Is(err, records.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load execution record: " + err.Error()})
+		return
+	}
+
+	config := record.Config.Execution()
+	if req.LatestImage || req.Version != "" {
+		config.ImageDigest = ""
+		if req.Version != "" {
+			name, _, _ := strings.Cut(config.Language, "@")
+			config.Language = name + "@" + req.Version
+		}
+		if _, err := docker.ImageFor(config.Language); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	reservation, ok := s.reserveExecutions(c, 1, ratelimit.WorstCaseCPU(config.Timeout, config.CPULimit))
+	if !ok {
+		return
+	}
+	result, err := s.dockerManager.Execute(c.Request.Context(), config)
+	s.settleCPUTime(c, reservation, result)
+	s.recordAudit(audit.ExecutionEntry(audit.CallerFromContext(c.Request.Context(), c.ClientIP()), config, result, err))
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, docker.ErrImageUnavailable) || errors.Is(err, docker.ErrUnsupportedVersion) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, docker.ErrInvalidArguments) || errors.Is(err, docker.ErrInvalidManifest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, docker.ErrDependencyInstall) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "replay failed: " + err.Error()})
+		return
+	}
+
+	response, err := s.executeResponse(result, config.MemoryLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	differences := records.Diff(record.Result, records.NewResult(result))
+	c.JSON(http.StatusOK, ReplayResponse{
+		ExecutionID: record.ID,
+		Identical:   len(differences) == 0,
+		Differences: differences,
+		Stored:      record.Result,
+		Result:      response,
+	})
+}
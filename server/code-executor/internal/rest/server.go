@@ -2,51 +2,153 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"code-executor/internal/audit"
+	"code-executor/internal/auth"
+	"code-executor/internal/blob"
+	"code-executor/internal/buildcache"
 	"code-executor/internal/docker"
+	"code-executor/internal/ratelimit"
+	"code-executor/internal/records"
+	"code-executor/internal/review"
+	"code-executor/internal/usage"
 	"github.com/gin-gonic/gin"
 )
 
 // Server implements the REST API server
 type Server struct {
-	dockerManager  *docker.Manager
-	router         *gin.Engine
-	submissionRepo *SubmissionsRepository
+	dockerManager *docker.Manager
+	router        *gin.Engine
+	reviewCache   *review.Cache
+	reviewer      review.Reviewer
+	hints         *review.HintService
+	usageTracker  *usage.Tracker
+	authenticator auth.Authenticator
+	limiter       *ratelimit.Limiter
+	auditLog      *audit.Log
+	artifactStore *blob.Store
+	fixtureStore  *blob.Store
+	buildCache    *buildcache.Cache
+	records       *records.Store
+	corsOrigins   []string
 }
 
 // ReviewRequest represents the REST API request for code review
 type ReviewRequest struct {
-	Code string `json:"code" binding:"required"`
+	UserID   string `json:"user_id,omitempty"`
+	CourseID string `json:"course_id,omitempty"`
+	Language string `json:"language"`
+	Code     string `json:"code" binding:"required"`
 }
 
 // ReviewResponse represents the REST API response for the code review
 type ReviewResponse struct {
-	ReviewResult string `json:"review_result"`
-	Cached       bool   `json:"cached"`
+	ReviewResult string             `json:"review_result"`
+	Cached       bool               `json:"cached"`
+	Reviewer     string             `json:"reviewer,omitempty"`
+	Usage        *review.TokenUsage `json:"usage,omitempty"`
+}
+
+// HintRequest represents the REST API request for the next hint
+type HintRequest struct {
+	SubmissionID  string           `json:"submission_id" binding:"required"`
+	UserID        string           `json:"user_id,omitempty"`
+	CourseID      string           `json:"course_id,omitempty"`
+	Language      string           `json:"language"`
+	Code          string           `json:"code" binding:"required"`
+	LastExecution *ExecuteResponse `json:"last_execution,omitempty"`
+	RevealedLevel int              `json:"revealed_level" binding:"min=0"`
+}
+
+// HintResponse represents the REST API response for a hint
+type HintResponse struct {
+	Hint      string            `json:"hint"`
+	Level     int               `json:"level"`
+	MaxLevel  int               `json:"max_level"`
+	HintsUsed int               `json:"hints_used"`
+	Usage     review.TokenUsage `json:"usage"`
+}
+
+// HintLedgerResponse represents the hints revealed for a submission
+type HintLedgerResponse struct {
+	SubmissionID string             `json:"submission_id"`
+	HintsUsed    int                `json:"hints_used"`
+	Hints        []review.HintEntry `json:"hints"`
 }
 
 // ExecuteRequest represents the REST API request for code execution
 type ExecuteRequest struct {
-	Language      string  `json:"language" binding:"required"`
-	Code          string  `json:"code" binding:"required"`
-	Input         string  `json:"input,omitempty"`
-	TimeoutSeconds int32  `json:"timeout_seconds,omitempty"`
-	MemoryLimitMB int64   `json:"memory_limit_mb,omitempty"`
-	CPULimit      float64 `json:"cpu_limit,omitempty"`
+	Language        string            `json:"language" binding:"required"`
+	Code            string            `json:"code" binding:"required"`
+	Input           string            `json:"input,omitempty"`
+	TimeoutSeconds  int32             `json:"timeout_seconds,omitempty"`
+	MemoryLimitMB   int64             `json:"memory_limit_mb,omitempty"`
+	CPULimit        float64           `json:"cpu_limit,omitempty"`
+	CPUTimeLimitMs  int64             `json:"cpu_time_limit_ms,omitempty" binding:"min=0"` // CPU time limit of the run, none if zero
+	UsageIntervalMs int64             `json:"usage_interval_ms,omitempty" binding:"min=0"` // Sample a resource usage series at this interval
+	CombinedOutput  bool              `json:"combined_output,omitempty"`
+	Artifacts       []string          `json:"artifacts,omitempty"`   // Glob patterns of files in /tmp to return
+	Fixtures        []Fixture         `json:"fixtures,omitempty"`    // Data files placed read-only in /tmp
+	Args            []string          `json:"args,omitempty"`        // Program arguments
+	Env             map[string]string `json:"env,omitempty"`         // Environment variables of the program
+	Manifest        *Manifest         `json:"manifest,omitempty"`    // Dependency manifest installed before the run
+	Determinism     *Determinism      `json:"determinism,omitempty"` // Fix seeds, time zone and locale for reproducible results
 }
 
 // ExecuteResponse represents the REST API response for code execution
 type ExecuteResponse struct {
-	Stdout          string `json:"stdout"`
-	Stderr          string `json:"stderr"`
-	ExitCode        int    `json:"exit_code"`
-	Timeout         bool   `json:"timeout"`
-	MemoryExceeded  bool   `json:"memory_exceeded"`
-	ExecutionTimeMs int64  `json:"execution_time_ms"`
-	MemoryUsedMB    int64  `json:"memory_used_mb"`
+	Stdout             string          `json:"stdout"`
+	Stderr             string          `json:"stderr"`
+	ExitCode           int             `json:"exit_code"`
+	Timeout            bool            `json:"timeout"`
+	MemoryExceeded     bool            `json:"memory_exceeded"`
+	ExecutionTimeMs    int64           `json:"execution_time_ms"` // Wall time of the run
+	CPUTimeMs          int64           `json:"cpu_time_ms"`
+	CPUUserTimeMs      int64           `json:"cpu_user_time_ms"`
+	CPUSystemTimeMs    int64           `json:"cpu_system_time_ms"`
+	CPUTimeExceeded    bool            `json:"cpu_time_exceeded"`
+	StartupTimeMs      int64           `json:"startup_time_ms"`
+	ImagePullTimeMs    int64           `json:"image_pull_time_ms,omitempty"`
+	MemoryUsedMB       int64           `json:"memory_used_mb"`
+	StdoutTruncated    bool            `json:"stdout_truncated"`
+	StderrTruncated    bool            `json:"stderr_truncated"`
+	StdoutBytes        int64           `json:"stdout_bytes"`
+	StderrBytes        int64           `json:"stderr_bytes"`
+	Output             []OutputChunk   `json:"output,omitempty"`
+	Usage              []UsagePoint    `json:"usage,omitempty"`
+	Artifacts          []Artifact      `json:"artifacts,omitempty"`
+	ArtifactsTruncated bool            `json:"artifacts_truncated,omitempty"`
+	Fixtures           []FixtureDigest `json:"fixtures,omitempty"`
+	Version            string          `json:"version,omitempty"`
+	ImageDigest        string          `json:"image_digest,omitempty"`
+	DependenciesCached bool            `json:"dependencies_cached,omitempty"`
+	InstallTimeMs      int64           `json:"install_time_ms,omitempty"`
+	BuildCached        bool            `json:"build_cached,omitempty"`
+	CompileTimeMs      int64           `json:"compile_time_ms,omitempty"`
+	Determinism        *Determinism    `json:"determinism,omitempty"`
+	ExecutionID        string          `json:"execution_id,omitempty"` // Stored record the execution can be replayed from
+}
+
+// OutputChunk represents a piece of output of one stream
+type OutputChunk struct {
+	Stream   string `json:"stream"`
+	Data     string `json:"data"`
+	OffsetMs int64  `json:"offset_ms"`
+}
+
+// UsagePoint represents a sample of the resource usage of a run
+type UsagePoint struct {
+	OffsetMs     int64   `json:"t_ms"`
+	CPUPercent   float64 `json:"cpu_percent"`
+	RSSBytes     int64   `json:"rss_bytes"`
+	Pids         int64   `json:"pids"`
+	IOReadBytes  int64   `json:"io_read_bytes"`
+	IOWriteBytes int64   `json:"io_write_bytes"`
 }
 
 // HealthResponse represents the health check response
@@ -55,15 +157,42 @@ type HealthResponse struct {
 	Version string `json:"version"`
 }
 
+// Options contains the dependencies and settings of the REST API server
+type Options struct {
+	Reviewer      review.Reviewer
+	ReviewCache   *review.Cache
+	Hints         *review.HintService
+	UsageTracker  *usage.Tracker
+	Authenticator auth.Authenticator // Nil disables authentication
+	Limiter       *ratelimit.Limiter // Nil disables rate limiting and quotas
+	AuditLog      *audit.Log         // Nil disables the audit log
+	ArtifactStore *blob.Store        // Nil returns artifacts inline instead of by download URL
+	FixtureStore  *blob.Store        // Nil disables fixtures referenced by ID
+	BuildCache    *buildcache.Cache  // Build cache the Docker manager uses, nil if disabled
+	Records       *records.Store     // Nil disables execution records and replays
+	CORSOrigins   []string           // Origins allowed to make cross-origin requests
+}
+
 // NewServer creates a new REST API server
-func NewServer(dockerManager *docker.Manager) *Server {
+func NewServer(dockerManager *docker.Manager, opts Options) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
 	
 	server := &Server{
-		dockerManager:  dockerManager,
-		router:         router,
-		submissionRepo: NewSubmissionsRepository(),
+		dockerManager: dockerManager,
+		router:        router,
+		reviewCache:   opts.ReviewCache,
+		reviewer:      opts.Reviewer,
+		hints:         opts.Hints,
+		usageTracker:  opts.UsageTracker,
+		authenticator: opts.Authenticator,
+		limiter:       opts.Limiter,
+		auditLog:      opts.AuditLog,
+		artifactStore: opts.ArtifactStore,
+		fixtureStore:  opts.FixtureStore,
+		buildCache:    opts.BuildCache,
+		records:       opts.Records,
+		corsOrigins:   opts.CORSOrigins,
 	}
 	
 	server.setupRoutes()
@@ -73,29 +202,39 @@ func NewServer(dockerManager *docker.Manager) *Server {
 // setupRoutes configures the API routes
 func (s *Server) setupRoutes() {
 	// Add CORS middleware
-	s.router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
+	s.router.Use(s.cors)
 
 	// API routes
 	v1 := s.router.Group("/api/v1")
 	{
-		v1.POST("/execute", s.execute)
-	v1.GET("/health", s.health)
-		v1.POST("/review", s.review)
+		v1.GET("/health", s.health)
+		v1.GET("/ready", s.ready)
+
+		api := v1.Group("", s.authenticate, s.rateLimit)
+		api.POST("/execute", s.requireScope(auth.ScopeExecute), s.execute)
+		api.POST("/review", s.requireScope(auth.ScopeReview), s.review)
+		api.GET("/review/cache", s.requireScope(auth.ScopeAdmin), s.reviewCacheStats)
+		api.DELETE("/review/cache", s.requireScope(auth.ScopeAdmin), s.invalidateReviewCache)
+		api.POST("/hints", s.requireScope(auth.ScopeReview), s.hint)
+		api.GET("/hints/:submission_id", s.requireScope(auth.ScopeReview), s.hintLedger)
+		api.GET("/usage", s.requireScope(auth.ScopeAdmin), s.getUsage)
+		api.GET("/audit", s.requireScope(auth.ScopeAdmin), s.exportAudit)
+		api.GET("/artifacts/:id/:name", s.requireScope(auth.ScopeExecute), s.downloadArtifact)
+		api.POST("/fixtures", s.requireScope(auth.ScopeExecute), s.uploadFixture)
+		api.GET("/languages", s.requireScope(auth.ScopeExecute), s.listLanguages)
+		api.GET("/images", s.requireScope(auth.ScopeAdmin), s.listImages)
+		api.POST("/images/pull", s.requireScope(auth.ScopeAdmin), s.pullImage)
+		api.DELETE("/images", s.requireScope(auth.ScopeAdmin), s.removeImage)
+		api.GET("/build-cache", s.requireScope(auth.ScopeAdmin), s.buildCacheStats)
+		api.DELETE("/build-cache", s.requireScope(auth.ScopeAdmin), s.clearBuildCache)
+		api.POST("/executions/:id/replay", s.requireScope(auth.ScopeAdmin), s.replayExecution)
+		api.POST("/complexity", s.requireScope(auth.ScopeExecute), s.analyzeComplexity)
+		api.POST("/benchmark", s.requireScope(auth.ScopeExecute), s.runBenchmark)
 	}
 	
-	// Root health check
+	// Root health and readiness checks
 	s.router.GET("/health", s.health)
+	s.router.GET("/ready", s.ready)
 }
 
 // execute handles code execution requests
@@ -105,69 +244,165 @@ func (s *Server) execute(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Set default values
-	timeout := time.Duration(req.TimeoutSeconds) * time.Second
-	if timeout == 0 {
-		timeout = 30 * time.Second
+	if err := docker.ValidateArtifactPatterns(req.Artifacts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if timeout > 120*time.Second {
-		timeout = 120 * time.Second // Maximum 2 minutes
+	if err := docker.ValidateArgs(req.Args, req.Env); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	memoryLimit := req.MemoryLimitMB * 1024 * 1024 // Convert MB to bytes
-	if memoryLimit == 0 {
-		memoryLimit = 128 * 1024 * 1024 // Default 128MB
+	if _, err := docker.ImageFor(req.Language); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if memoryLimit > 1024*1024*1024 {
-		memoryLimit = 1024 * 1024 * 1024 // Maximum 1GB
+	fixtures, err := s.resolveFixtures(req.Fixtures)
+	if errors.Is(err, docker.ErrInvalidFixture) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	cpuLimit := req.CPULimit
-	if cpuLimit == 0 {
-		cpuLimit = 0.5 // Default 50% CPU
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load fixtures: " + err.Error()})
+		return
+	}
+	manifest := req.Manifest.toDocker()
+	if err := docker.ValidateManifest(manifest, req.Language, fixtures); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if cpuLimit > 1.0 {
-		cpuLimit = 1.0 // Maximum 100% CPU
+	determinism := req.Determinism.toDocker()
+	if err := docker.ValidateDeterminism(determinism, req.Language, req.Env); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Ensure Docker image is available
-	imageName := getImageName(req.Language)
-	if err := s.dockerManager.EnsureImage(c.Request.Context(), imageName); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to ensure Docker image: " + err.Error()})
+	// Set default values, capped by the caller's limits
+	timeout, memoryLimit, cpuLimit := auth.LimitsFor(c.Request.Context()).Apply(
+		time.Duration(req.TimeoutSeconds)*time.Second,
+		req.MemoryLimitMB*1024*1024, // Convert MB to bytes
+		req.CPULimit,
+	)
+
+	// Reserve the execution and its worst-case CPU time in the caller's quotas
+	reservation, ok := s.reserveExecutions(c, 1, ratelimit.WorstCaseCPU(timeout, cpuLimit))
+	if !ok {
 		return
 	}
 
 	// Execute code
 	config := docker.ExecutionConfig{
-		Language:    req.Language,
-		Code:        req.Code,
-		Input:       req.Input,
-		Timeout:     timeout,
-		MemoryLimit: memoryLimit,
-		CPULimit:    cpuLimit,
+		Language:       req.Language,
+		Code:           req.Code,
+		Input:          req.Input,
+		Timeout:        timeout,
+		MemoryLimit:    memoryLimit,
+		CPULimit:       cpuLimit,
+		CPUTimeLimit:   time.Duration(req.CPUTimeLimitMs) * time.Millisecond,
+		UsageInterval:  time.Duration(req.UsageIntervalMs) * time.Millisecond,
+		Trusted:        auth.IsTrusted(c.Request.Context()),
+		CombinedOutput: req.CombinedOutput,
+		Artifacts:      req.Artifacts,
+		Fixtures:       fixtures,
+		Args:           req.Args,
+		Env:            req.Env,
+		Manifest:       manifest,
+		Determinism:    determinism,
 	}
 
 	result, err := s.dockerManager.Execute(c.Request.Context(), config)
+	s.settleCPUTime(c, reservation, result)
+	s.recordAudit(audit.ExecutionEntry(audit.CallerFromContext(c.Request.Context(), c.ClientIP()), config, result, err))
+	if errors.Is(err, docker.ErrRuntimeUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, docker.ErrInvalidManifest) || errors.Is(err, docker.ErrInvalidArguments) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, docker.ErrDependencyInstall) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "execution failed: " + err.Error()})
 		return
 	}
 
-	// Build response
-	response := ExecuteResponse{
-		Stdout:          result.Stdout,
-		Stderr:          result.Stderr,
-		ExitCode:        result.ExitCode,
-		Timeout:         result.Timeout,
-		MemoryExceeded:  result.MemoryUsed > memoryLimit,
-		ExecutionTimeMs: result.ExecutionTime.Milliseconds(),
-		MemoryUsedMB:    result.MemoryUsed / (1024 * 1024),
+	// Build response, with the record the execution can be replayed from
+	response, err := s.executeResponse(result, memoryLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	response.ExecutionID = s.storeRecord(audit.CallerFromContext(c.Request.Context(), c.ClientIP()), config, result)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// executeResponse returns the response of an execution
+func (s *Server) executeResponse(result *docker.ExecutionResult, memoryLimit int64) (ExecuteResponse, error) {
+	response := ExecuteResponse{
+		Stdout:             result.Stdout,
+		Stderr:             result.Stderr,
+		ExitCode:           result.ExitCode,
+		Timeout:            result.Timeout,
+		MemoryExceeded:     result.MemoryUsed > memoryLimit,
+		ExecutionTimeMs:    result.ExecutionTime.Milliseconds(),
+		CPUTimeMs:          result.CPUTime().Milliseconds(),
+		CPUUserTimeMs:      result.CPUUserTime.Milliseconds(),
+		CPUSystemTimeMs:    result.CPUSystemTime.Milliseconds(),
+		CPUTimeExceeded:    result.CPUTimeExceeded,
+		StartupTimeMs:      result.StartupTime.Milliseconds(),
+		ImagePullTimeMs:    result.PullTime.Milliseconds(),
+		MemoryUsedMB:       result.MemoryUsed / (1024 * 1024),
+		StdoutTruncated:    result.StdoutTruncated,
+		StderrTruncated:    result.StderrTruncated,
+		StdoutBytes:        result.StdoutBytes,
+		StderrBytes:        result.StderrBytes,
+		ArtifactsTruncated: result.ArtifactsTruncated,
+		Version:            result.Version,
+		ImageDigest:        result.ImageDigest,
+		DependenciesCached: result.DependenciesCached,
+		InstallTimeMs:      result.InstallTime.Milliseconds(),
+		BuildCached:        result.BuildCached,
+		CompileTimeMs:      result.CompileTime.Milliseconds(),
+		Determinism:        determinismResponse(result.Determinism),
+	}
+	for _, chunk := range result.Output {
+		response.Output = append(response.Output, OutputChunk{
+			Stream:   chunk.Stream,
+			Data:     chunk.Data,
+			OffsetMs: chunk.Offset.Milliseconds(),
+		})
+	}
+	for _, point := range result.Usage {
+		response.Usage = append(response.Usage, UsagePoint{
+			OffsetMs:     point.Offset.Milliseconds(),
+			CPUPercent:   point.CPUPercent,
+			RSSBytes:     point.RSS,
+			Pids:         point.Pids,
+			IOReadBytes:  point.IORead,
+			IOWriteBytes: point.IOWrite,
+		})
+	}
+	if len(result.Artifacts) > 0 {
+		artifacts, err := s.artifactResponses(result.Artifacts)
+		if err != nil {
+			return ExecuteResponse{}, err
+		}
+		response.Artifacts = artifacts
+	}
+	for _, digest := range result.Fixtures {
+		response.Fixtures = append(response.Fixtures, FixtureDigest{
+			Name:   digest.Name,
+			Size:   digest.Size,
+			SHA256: digest.SHA256,
+		})
+	}
+	return response, nil
+}
+
 // review handles code review requests
 func (s *Server) review(c *gin.Context) {
 	var req ReviewRequest
@@ -176,19 +411,126 @@ func (s *Server) review(c *gin.Context) {
 		return
 	}
 
+	req.UserID = callerUserID(c, req.UserID)
+
 	// Check cache for submission
-	if result, cached := s.submissionRepo.Get(req.Code); cached {
+	cacheKey := review.CacheKey(req.Language, req.Code, s.reviewer.Version())
+	if result, cached := s.reviewCache.Get(cacheKey); cached {
+		s.recordAudit(audit.ReviewEntry(audit.CallerFromContext(c.Request.Context(), c.ClientIP()), req.Language, req.Code, s.reviewer.Version(), true, nil))
 		c.JSON(http.StatusOK, ReviewResponse{ReviewResult: result, Cached: true})
 		return
 	}
 
-	// Send to Claude API (mocked for the purpose of this implementation)
-	reviewResult := "Style: Good\nCorrectness: Needs improvement\nSuggestions: Consider refactoring."
+	result, err := s.reviewer.Review(c.Request.Context(), review.Submission{
+		UserID:   req.UserID,
+		CourseID: req.CourseID,
+		Language: req.Language,
+		Code:     req.Code,
+	})
+	reviewer := s.reviewer.Version()
+	if result != nil {
+		reviewer = result.Version
+	}
+	s.recordAudit(audit.ReviewEntry(audit.CallerFromContext(c.Request.Context(), c.ClientIP()), req.Language, req.Code, reviewer, false, err))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "review failed: " + err.Error()})
+		return
+	}
+
+	// Store to cache under the version that actually produced the review, so
+	// degraded reviews are not served once the budget allows model calls again
+	s.reviewCache.Store(review.CacheKey(req.Language, req.Code, result.Version), result.Text)
+
+	c.JSON(http.StatusOK, ReviewResponse{
+		ReviewResult: result.Text,
+		Cached:       false,
+		Reviewer:     result.Version,
+		Usage:        &result.Usage,
+	})
+}
+
+// reviewCacheStats returns the review cache metrics
+func (s *Server) reviewCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, s.reviewCache.Stats())
+}
+
+// invalidateReviewCache drops cached reviews, optionally only for one language
+func (s *Server) invalidateReviewCache(c *gin.Context) {
+	prefix := ""
+	if language := c.Query("language"); language != "" {
+		prefix = strings.ToLower(language) + ":"
+	}
+
+	removed := s.reviewCache.Invalidate(prefix)
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+// hint handles progressive hint requests
+func (s *Server) hint(c *gin.Context) {
+	var req HintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	learner := hintLearner(c, req.UserID)
+	req.UserID = callerUserID(c, req.UserID)
+
+	hintReq := review.HintRequest{
+		Submission: review.Submission{
+			ID:       req.SubmissionID,
+			UserID:   req.UserID,
+			CourseID: req.CourseID,
+			Language: req.Language,
+			Code:     req.Code,
+		},
+		Learner:       learner,
+		RevealedLevel: req.RevealedLevel,
+	}
+	if exec := req.LastExecution; exec != nil {
+		hintReq.Execution = &review.ExecutionSummary{
+			Stdout:         exec.Stdout,
+			Stderr:         exec.Stderr,
+			ExitCode:       exec.ExitCode,
+			Timeout:        exec.Timeout,
+			MemoryExceeded: exec.MemoryExceeded,
+		}
+	}
 
-	// Store to cache
-	s.submissionRepo.Store(req.Code, reviewResult)
+	hint, err := s.hints.Next(c.Request.Context(), hintReq)
+	if errors.Is(err, review.ErrHintsExhausted) || errors.Is(err, review.ErrHintLevel) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "hint generation failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, HintResponse{
+		Hint:      hint.Text,
+		Level:     hint.Level,
+		MaxLevel:  hint.MaxLevel,
+		HintsUsed: hint.HintsUsed,
+		Usage:     hint.Usage,
+	})
+}
+
+// hintLedger returns the hints of a submission revealed to the caller, or to
+// the user named by a service caller
+func (s *Server) hintLedger(c *gin.Context) {
+	submissionID := c.Param("submission_id")
+	entries := s.hints.Ledger().Entries(hintLearner(c, c.Query("user_id")), submissionID)
 
-	c.JSON(http.StatusOK, ReviewResponse{ReviewResult: reviewResult, Cached: false})
+	c.JSON(http.StatusOK, HintLedgerResponse{
+		SubmissionID: submissionID,
+		HintsUsed:    len(entries),
+		Hints:        entries,
+	})
+}
+
+// getUsage returns token usage and budget status, optionally for a user and course
+func (s *Server) getUsage(c *gin.Context) {
+	c.JSON(http.StatusOK, s.usageTracker.Report(c.Query("user_id"), c.Query("course_id")))
 }
 
 // health handles health check requests
@@ -199,32 +541,6 @@ func (s *Server) health(c *gin.Context) {
 	})
 }
 
-// getImageName returns the appropriate Docker image for the language
-func getImageName(language string) string {
-	switch language {
-	case "python", "python3":
-		return "python:3.11-alpine"
-	case "javascript", "js", "node":
-		return "node:18-alpine"
-	case "go", "golang":
-		return "golang:1.21-alpine"
-	case "java":
-		return "openjdk:11-alpine"
-	case "c":
-		return "gcc:alpine"
-	case "cpp", "c++":
-		return "gcc:alpine"
-	case "rust":
-		return "rust:alpine"
-	case "ruby":
-		return "ruby:3.2-alpine"
-	case "php":
-		return "php:8.2-alpine"
-	default:
-		return "python:3.11-alpine"
-	}
-}
-
 // Start starts the REST API server
 func (s *Server) Start(address string) error {
 	return s.router.Run(address)
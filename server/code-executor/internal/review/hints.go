@@ -0,0 +1,140 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxHintLevel is the most specific hint that can be revealed
+	MaxHintLevel = 4
+	// maxHintLength caps the size of a hint so it cannot carry a solution
+	maxHintLength = 400
+	// maxCodeLines is the number of code-like lines a hint may contain
+	maxCodeLines = 1
+)
+
+var (
+	// ErrHintsExhausted is returned when every hint level has been revealed
+	ErrHintsExhausted = errors.New("all hints have been revealed")
+	// ErrHintLevel is returned when the revealed level of a request is not the
+	// highest level revealed to the learner
+	ErrHintLevel = errors.New("revealed level does not match the hints revealed")
+
+	codeFencePattern = regexp.MustCompile("(?s)```.*?```")
+	codeLinePattern  = regexp.MustCompile(`[;{}]\s*$|^\s*(def|func|fn|function|class|public|private|return|for|while|if)\b.*[:({]\s*$`)
+)
+
+// HintRequest asks for the next hint of a submission
+type HintRequest struct {
+	Submission    Submission
+	Execution     *ExecutionSummary
+	Learner       string // Caller the hint is revealed to, the ledger is kept per learner
+	RevealedLevel int    // Highest level the learner has seen, 0 for none
+}
+
+// Hint is a single progressive hint
+type Hint struct {
+	Text      string
+	Level     int
+	MaxLevel  int
+	HintsUsed int // Number of distinct hints revealed for the submission
+	Usage     TokenUsage
+}
+
+// HintService produces progressive hints and records them in a ledger
+type HintService struct {
+	reviewer Reviewer
+	ledger   *Ledger
+}
+
+// NewHintService creates a new hint service
+func NewHintService(reviewer Reviewer, ledger *Ledger) *HintService {
+	return &HintService{
+		reviewer: reviewer,
+		ledger:   ledger,
+	}
+}
+
+// Next returns the hint following the level already revealed to the learner.
+// The level is reserved in the ledger before the reviewer runs, so concurrent
+// requests cannot reveal the same level twice or skip one.
+func (s *HintService) Next(ctx context.Context, req HintRequest) (*Hint, error) {
+	level, err := s.ledger.Reserve(req.Learner, req.Submission.ID, req.RevealedLevel, MaxHintLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.reviewer.Hint(ctx, HintPrompt{
+		Submission: req.Submission,
+		Execution:  req.Execution,
+		Level:      level,
+		MaxLevel:   MaxHintLevel,
+	})
+	if err != nil {
+		s.ledger.Release(req.Learner, req.Submission.ID, level)
+		return nil, fmt.Errorf("failed to generate hint: %w", err)
+	}
+
+	// Never pass on something that looks like a solution
+	text := result.Text
+	if !isSafeHint(text) {
+		text = fallbackHint(level)
+	}
+
+	s.ledger.Reveal(req.Learner, req.Submission.ID, HintEntry{
+		Level:      level,
+		Hint:       text,
+		RevealedAt: time.Now(),
+	})
+
+	return &Hint{
+		Text:      text,
+		Level:     level,
+		MaxLevel:  MaxHintLevel,
+		HintsUsed: s.ledger.Count(req.Learner, req.Submission.ID),
+		Usage:     result.Usage,
+	}, nil
+}
+
+// Ledger returns the hint ledger
+func (s *HintService) Ledger() *Ledger {
+	return s.ledger
+}
+
+// isSafeHint reports whether a hint is short and free of code blocks
+func isSafeHint(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || len(text) > maxHintLength {
+		return false
+	}
+	if codeFencePattern.MatchString(text) {
+		return false
+	}
+
+	codeLines := 0
+	for _, line := range strings.Split(text, "\n") {
+		if codeLinePattern.MatchString(line) {
+			codeLines++
+		}
+	}
+	return codeLines <= maxCodeLines
+}
+
+// fallbackHint returns a generic hint for the level
+func fallbackHint(level int) string {
+	switch level {
+	case 1:
+		return "Re-read the exercise and check which part of it your program does not handle yet."
+	case 2:
+		return "Run your program with a small input and compare each step with what you expect."
+	case 3:
+		return "Focus on the part of your code that runs right before the wrong result or error appears."
+	default:
+		return "Explain your code line by line to yourself. The line you cannot explain is usually the one to fix."
+	}
+}
@@ -0,0 +1,119 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// failingReviewer fails every hint, like an unreachable model backend
+type failingReviewer struct {
+	StubReviewer
+}
+
+func (r *failingReviewer) Hint(ctx context.Context, prompt HintPrompt) (*Result, error) {
+	return nil, errors.New("backend unavailable")
+}
+
+func hintRequest(learner string, revealed int) HintRequest {
+	return HintRequest{
+		Submission:    Submission{ID: "sub-1", Language: "python", Code: "print(total)"},
+		Learner:       learner,
+		RevealedLevel: revealed,
+	}
+}
+
+func TestHintServiceNext(t *testing.T) {
+	service := NewHintService(NewStubReviewer(), NewLedger())
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		learner   string
+		revealed  int
+		wantLevel int
+		wantErr   error
+	}{
+		{name: "first hint", learner: "user:a", revealed: 0, wantLevel: 1},
+		{name: "skips a level", learner: "user:a", revealed: 2, wantErr: ErrHintLevel},
+		{name: "stale level", learner: "user:a", revealed: 0, wantErr: ErrHintLevel},
+		{name: "second hint", learner: "user:a", revealed: 1, wantLevel: 2},
+		{name: "other learner starts over", learner: "user:b", revealed: 0, wantLevel: 1},
+		{name: "third hint", learner: "user:a", revealed: 2, wantLevel: 3},
+		{name: "last hint", learner: "user:a", revealed: 3, wantLevel: MaxHintLevel},
+		{name: "exhausted", learner: "user:a", revealed: MaxHintLevel, wantErr: ErrHintsExhausted},
+	}
+	for _, tt := range tests {
+		hint, err := service.Next(ctx, hintRequest(tt.learner, tt.revealed))
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: Next() error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: Next() error = %v", tt.name, err)
+		}
+		if hint.Level != tt.wantLevel || hint.HintsUsed != tt.wantLevel {
+			t.Errorf("%s: level %d with %d hints used, want %d", tt.name, hint.Level, hint.HintsUsed, tt.wantLevel)
+		}
+	}
+
+	if entries := service.Ledger().Entries("user:a", "sub-1"); len(entries) != MaxHintLevel {
+		t.Errorf("ledger has %d entries, want %d", len(entries), MaxHintLevel)
+	}
+}
+
+func TestHintServiceConcurrentRequests(t *testing.T) {
+	service := NewHintService(NewStubReviewer(), NewLedger())
+
+	const requests = 20
+	var wg sync.WaitGroup
+	levels := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hint, err := service.Next(context.Background(), hintRequest("user:a", 0))
+			if err == nil {
+				levels <- hint.Level
+			} else if !errors.Is(err, ErrHintLevel) {
+				t.Errorf("Next() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(levels)
+
+	var revealed []int
+	for level := range levels {
+		revealed = append(revealed, level)
+	}
+	if len(revealed) != 1 || revealed[0] != 1 {
+		t.Errorf("revealed levels %v, want only level 1", revealed)
+	}
+	if count := service.Ledger().Count("user:a", "sub-1"); count != 1 {
+		t.Errorf("Count() = %d, want 1", count)
+	}
+}
+
+func TestHintServiceReleasesFailedLevel(t *testing.T) {
+	ledger := NewLedger()
+	ctx := context.Background()
+
+	if _, err := NewHintService(&failingReviewer{}, ledger).Next(ctx, hintRequest("user:a", 0)); err == nil {
+		t.Fatal("Next() succeeded with a failing reviewer")
+	}
+	if count := ledger.Count("user:a", "sub-1"); count != 0 {
+		t.Errorf("Count() = %d after a failed hint, want 0", count)
+	}
+
+	hint, err := NewHintService(NewStubReviewer(), ledger).Next(ctx, hintRequest("user:a", 0))
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if hint.Level != 1 {
+		t.Errorf("level %d after a failed hint, want 1", hint.Level)
+	}
+}
@@ -0,0 +1,121 @@
+package review
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HintEntry records a hint revealed for a submission
+type HintEntry struct {
+	Level      int       `json:"level"`
+	Hint       string    `json:"hint"`
+	RevealedAt time.Time `json:"revealed_at"`
+
+	pending bool // Reserved while its hint is produced
+}
+
+// Ledger keeps track of the hints revealed per learner and submission so
+// that the number of hints used can be taken into account when scoring
+type Ledger struct {
+	mu      sync.RWMutex
+	entries map[ledgerKey]map[int]HintEntry
+}
+
+// ledgerKey identifies the hints of a submission revealed to a learner
+type ledgerKey struct {
+	learner    string
+	submission string
+}
+
+// NewLedger creates a new in-memory hint ledger
+func NewLedger() *Ledger {
+	return &Ledger{
+		entries: make(map[ledgerKey]map[int]HintEntry),
+	}
+}
+
+// Reserve claims the level after the highest one of a submission revealed to
+// a learner, so concurrent requests never get the same level. revealed is the
+// level the learner last saw; any other level is rejected, so levels cannot be
+// skipped. The claimed level is completed with Reveal or given up with Release.
+func (l *Ledger) Reserve(learner, submissionID string, revealed, maxLevel int) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := ledgerKey{learner, submissionID}
+	levels, ok := l.entries[key]
+	if !ok {
+		levels = make(map[int]HintEntry)
+		l.entries[key] = levels
+	}
+	highest := 0
+	for level := range levels {
+		highest = max(highest, level)
+	}
+	if highest >= maxLevel {
+		return 0, ErrHintsExhausted
+	}
+	if revealed != highest {
+		return 0, fmt.Errorf("%w: level %d was revealed, not %d", ErrHintLevel, highest, revealed)
+	}
+	levels[highest+1] = HintEntry{Level: highest + 1, pending: true}
+	return highest + 1, nil
+}
+
+// Reveal records the hint of a level reserved for a learner
+func (l *Ledger) Reveal(learner, submissionID string, entry HintEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if levels, ok := l.entries[ledgerKey{learner, submissionID}]; ok {
+		levels[entry.Level] = entry
+	}
+}
+
+// Release gives up a level reserved for a learner whose hint could not be produced
+func (l *Ledger) Release(learner, submissionID string, level int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := ledgerKey{learner, submissionID}
+	if entry, ok := l.entries[key][level]; ok && entry.pending {
+		delete(l.entries[key], level)
+	}
+	if len(l.entries[key]) == 0 {
+		delete(l.entries, key)
+	}
+}
+
+// Entries returns the hints of a submission revealed to a learner ordered by level
+func (l *Ledger) Entries(learner, submissionID string) []HintEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	levels := l.entries[ledgerKey{learner, submissionID}]
+	entries := make([]HintEntry, 0, len(levels))
+	for _, entry := range levels {
+		if !entry.pending {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Level < entries[j].Level
+	})
+	return entries
+}
+
+// Count returns the number of distinct hints of a submission revealed to a learner
+func (l *Ledger) Count(learner, submissionID string) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	count := 0
+	for _, entry := range l.entries[ledgerKey{learner, submissionID}] {
+		if !entry.pending {
+			count++
+		}
+	}
+	return count
+}
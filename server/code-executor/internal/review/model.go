@@ -0,0 +1,199 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// modelPromptVersion changes whenever the prompts below change, so cached
+	// reviews of an older prompt are not reused
+	modelPromptVersion = "v1"
+	// defaultModelMaxTokens caps the length of an answer
+	defaultModelMaxTokens = 1024
+	// maxModelErrorBytes is how much of a failed response is reported
+	maxModelErrorBytes = 1024
+)
+
+// Prompts of the model reviewer
+const (
+	reviewSystemPrompt = `You review code submitted by learners. Answer in three lines:
+"Style: ...", "Correctness: ..." and "Suggestions: ...". Be brief and encouraging,
+and never include a corrected version of the code.`
+	hintSystemPrompt = `You give progressive hints to learners whose code does not work yet.
+Level 1 is the vaguest hint and the highest level the most specific. Answer with a
+single hint of at most two sentences for the requested level. Point at where the
+problem is, never say how to fix it, and never include code.`
+)
+
+// ModelConfig configures a reviewer backed by a chat completions API
+type ModelConfig struct {
+	URL       string `json:"url"`         // Chat completions endpoint, e.g. https://api.openai.com/v1/chat/completions
+	Model     string `json:"model"`       // Model name sent with every request
+	APIKeyEnv string `json:"api_key_env"` // Environment variable holding the API key
+	MaxTokens int    `json:"max_tokens"`  // Tokens per answer, 1024 if zero
+	Timeout   string `json:"timeout"`     // Timeout of a request, e.g. "30s"
+}
+
+// LoadModelConfig reads a model reviewer configuration from a JSON file
+func LoadModelConfig(path string) (*ModelConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reviewer config: %w", err)
+	}
+
+	var config ModelConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse reviewer config: %w", err)
+	}
+	return &config, nil
+}
+
+// ModelReviewer produces reviews and hints with a model behind a chat
+// completions API and reports the tokens each request used
+type ModelReviewer struct {
+	url       string
+	model     string
+	apiKey    string
+	maxTokens int
+	client    *http.Client
+}
+
+// NewModelReviewer creates a reviewer from the configuration
+func NewModelReviewer(config ModelConfig) (*ModelReviewer, error) {
+	if config.URL == "" || config.Model == "" {
+		return nil, fmt.Errorf("url and model are required")
+	}
+	reviewer := &ModelReviewer{
+		url:       config.URL,
+		model:     config.Model,
+		maxTokens: config.MaxTokens,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if reviewer.maxTokens <= 0 {
+		reviewer.maxTokens = defaultModelMaxTokens
+	}
+	if config.APIKeyEnv != "" {
+		reviewer.apiKey = os.Getenv(config.APIKeyEnv)
+		if reviewer.apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", config.APIKeyEnv)
+		}
+	}
+	if config.Timeout != "" {
+		timeout, err := time.ParseDuration(config.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		reviewer.client.Timeout = timeout
+	}
+	return reviewer, nil
+}
+
+// Version identifies the model and prompt version
+func (r *ModelReviewer) Version() string {
+	return r.model + "/" + modelPromptVersion
+}
+
+// Review asks the model for a review of the submission
+func (r *ModelReviewer) Review(ctx context.Context, submission Submission) (*Result, error) {
+	prompt := fmt.Sprintf("Language: %s\n\n%s", submission.Language, submission.Code)
+	return r.complete(ctx, reviewSystemPrompt, prompt)
+}
+
+// Hint asks the model for a hint of the requested level
+func (r *ModelReviewer) Hint(ctx context.Context, prompt HintPrompt) (*Result, error) {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hint level %d of %d.\nLanguage: %s\n\n%s\n", prompt.Level, prompt.MaxLevel, prompt.Submission.Language, prompt.Submission.Code)
+	if exec := prompt.Execution; exec != nil {
+		fmt.Fprintf(&text, "\nLast run: exit code %d, timeout %t, out of memory %t\n", exec.ExitCode, exec.Timeout, exec.MemoryExceeded)
+		if exec.Stdout != "" {
+			fmt.Fprintf(&text, "Stdout:\n%s\n", exec.Stdout)
+		}
+		if exec.Stderr != "" {
+			fmt.Fprintf(&text, "Stderr:\n%s\n", exec.Stderr)
+		}
+	}
+	return r.complete(ctx, hintSystemPrompt, text.String())
+}
+
+// chatRequest is the body of a chat completions request
+type chatRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	Messages  []chatMessage `json:"messages"`
+}
+
+// chatMessage is a message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatResponse is the part of a chat completions response the reviewer uses
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// complete sends a system and user prompt to the model and returns its answer
+func (r *ModelReviewer) complete(ctx context.Context, system, prompt string) (*Result, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:     r.model,
+		MaxTokens: r.maxTokens,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxModelErrorBytes))
+		return nil, fmt.Errorf("model returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var answer chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode model response: %w", err)
+	}
+	if len(answer.Choices) == 0 {
+		return nil, fmt.Errorf("model returned no answer")
+	}
+	return &Result{
+		Text:    strings.TrimSpace(answer.Choices[0].Message.Content),
+		Version: r.Version(),
+		Usage: TokenUsage{
+			InputTokens:  answer.Usage.PromptTokens,
+			OutputTokens: answer.Usage.CompletionTokens,
+		},
+	}, nil
+}
@@ -0,0 +1,70 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestModelReviewerHint(t *testing.T) {
+	t.Setenv("REVIEWER_API_KEY", "secret")
+
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q, want the API key", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Look at the loop. "}}],"usage":{"prompt_tokens":120,"completion_tokens":8}}`))
+	}))
+	defer server.Close()
+
+	reviewer, err := NewModelReviewer(ModelConfig{URL: server.URL, Model: "test-model", APIKeyEnv: "REVIEWER_API_KEY"})
+	if err != nil {
+		t.Fatalf("NewModelReviewer() error = %v", err)
+	}
+	result, err := reviewer.Hint(context.Background(), HintPrompt{
+		Submission: Submission{Language: "python", Code: "print(total)"},
+		Execution:  &ExecutionSummary{Stderr: "NameError: name 'total' is not defined", ExitCode: 1},
+		Level:      2,
+		MaxLevel:   MaxHintLevel,
+	})
+	if err != nil {
+		t.Fatalf("Hint() error = %v", err)
+	}
+
+	if result.Text != "Look at the loop." || result.Version != "test-model/"+modelPromptVersion {
+		t.Errorf("result %+v", result)
+	}
+	if result.Usage != (TokenUsage{InputTokens: 120, OutputTokens: 8}) {
+		t.Errorf("Usage = %+v, want 120 input and 8 output tokens", result.Usage)
+	}
+	if got.Model != "test-model" || len(got.Messages) != 2 {
+		t.Fatalf("request %+v", got)
+	}
+	for _, want := range []string{"Hint level 2 of 4", "print(total)", "NameError"} {
+		if !strings.Contains(got.Messages[1].Content, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, got.Messages[1].Content)
+		}
+	}
+}
+
+func TestModelReviewerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	reviewer, err := NewModelReviewer(ModelConfig{URL: server.URL, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("NewModelReviewer() error = %v", err)
+	}
+	if _, err := reviewer.Review(context.Background(), Submission{Code: "x"}); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("Review() error = %v, want the model's error", err)
+	}
+}
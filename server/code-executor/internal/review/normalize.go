@@ -0,0 +1,191 @@
+package review
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// commentSyntax describes how comments and string literals look in a language
+type commentSyntax struct {
+	lineComments  []string
+	blockComments [][2]string
+	quotes        string
+	// indentSensitive keeps leading whitespace, which carries meaning
+	indentSensitive bool
+}
+
+var (
+	hashSyntax = commentSyntax{
+		lineComments: []string{"#"},
+		quotes:       `'"`,
+	}
+	cSyntax = commentSyntax{
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        `'"`,
+	}
+)
+
+// syntaxFor returns the comment syntax for a language
+func syntaxFor(language string) commentSyntax {
+	switch strings.ToLower(language) {
+	case "python", "python3":
+		syntax := hashSyntax
+		syntax.indentSensitive = true
+		return syntax
+	case "ruby":
+		return hashSyntax
+	case "javascript", "js", "node", "go", "golang":
+		syntax := cSyntax
+		syntax.quotes = "'\"`"
+		return syntax
+	case "php":
+		syntax := cSyntax
+		syntax.lineComments = []string{"//", "#"}
+		return syntax
+	case "rust":
+		// Single quotes also start lifetimes, so only track double quotes
+		syntax := cSyntax
+		syntax.quotes = `"`
+		return syntax
+	case "java", "c", "cpp", "c++":
+		return cSyntax
+	default:
+		// Unknown languages only get whitespace normalization
+		return commentSyntax{quotes: `'"`}
+	}
+}
+
+// Normalize strips comments and insignificant whitespace from source code,
+// so that formatting-only changes map to the same cache entry. String
+// literals are kept as they are, whitespace in them changes the program.
+func Normalize(language, code string) string {
+	syntax := syntaxFor(language)
+
+	var out strings.Builder
+	var indent strings.Builder
+	lineStart := true // Nothing of the current line was written yet
+	space := false    // Whitespace between tokens of the current line
+	// write starts a line or separates a token before writing it
+	write := func(token string) {
+		if lineStart {
+			if out.Len() > 0 {
+				out.WriteByte('\n')
+			}
+			out.WriteString(indent.String())
+			indent.Reset()
+			lineStart = false
+		} else if space {
+			out.WriteByte(' ')
+		}
+		space = false
+		out.WriteString(token)
+	}
+
+	for _, part := range stripComments(syntax, strings.ReplaceAll(code, "\r\n", "\n")) {
+		if part.literal {
+			write(part.text)
+			continue
+		}
+		for i := 0; i < len(part.text); i++ {
+			switch c := part.text[i]; {
+			case c == '\n':
+				lineStart = true
+				space = false
+				indent.Reset()
+			case strings.IndexByte(" \t\r\v\f", c) >= 0:
+				if !lineStart {
+					space = true
+				} else if syntax.indentSensitive {
+					indent.WriteByte(c)
+				}
+			default:
+				write(part.text[i : i+1])
+			}
+		}
+	}
+	return out.String()
+}
+
+// CacheKey builds the review cache key for a submission
+func CacheKey(language, code, reviewerVersion string) string {
+	hash := sha256.Sum256([]byte(Normalize(language, code)))
+	return strings.ToLower(language) + ":" + reviewerVersion + ":" + hex.EncodeToString(hash[:])
+}
+
+// codePart is a string literal or the code between string literals
+type codePart struct {
+	text    string
+	literal bool
+}
+
+// stripComments removes comments and splits the code into string literals,
+// which are left untouched, and the code between them
+func stripComments(syntax commentSyntax, code string) []codePart {
+	var parts []codePart
+	var out strings.Builder
+	for i := 0; i < len(code); {
+		c := code[i]
+
+		// String literal: copy through to the closing quote
+		if strings.IndexByte(syntax.quotes, c) >= 0 {
+			end := i + 1
+			for end < len(code) && code[end] != c {
+				if code[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			// An escape at the end of unterminated code skips past it
+			end = min(end, len(code))
+			if end < len(code) {
+				end++
+			}
+			if out.Len() > 0 {
+				parts = append(parts, codePart{text: out.String()})
+				out.Reset()
+			}
+			parts = append(parts, codePart{text: code[i:end], literal: true})
+			i = end
+			continue
+		}
+
+		if end, ok := matchComment(syntax, code, i); ok {
+			// Keep line breaks so line structure is preserved
+			out.WriteString(strings.Repeat("\n", strings.Count(code[i:end], "\n")))
+			i = end
+			continue
+		}
+
+		out.WriteByte(c)
+		i++
+	}
+	if out.Len() > 0 {
+		parts = append(parts, codePart{text: out.String()})
+	}
+	return parts
+}
+
+// matchComment returns the end of a comment starting at position i
+func matchComment(syntax commentSyntax, code string, i int) (int, bool) {
+	for _, marker := range syntax.lineComments {
+		if strings.HasPrefix(code[i:], marker) {
+			end := strings.IndexByte(code[i:], '\n')
+			if end < 0 {
+				return len(code), true
+			}
+			return i + end, true
+		}
+	}
+	for _, markers := range syntax.blockComments {
+		if strings.HasPrefix(code[i:], markers[0]) {
+			end := strings.Index(code[i+len(markers[0]):], markers[1])
+			if end < 0 {
+				return len(code), true
+			}
+			return i + len(markers[0]) + end + len(markers[1]), true
+		}
+	}
+	return 0, false
+}
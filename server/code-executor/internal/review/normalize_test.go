@@ -0,0 +1,71 @@
+package review
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		code     string
+		want     string
+	}{
+		{
+			name:     "comments and blank lines",
+			language: "python",
+			code:     "# setup\nx = 1  # one\n\n\nprint(x)\n",
+			want:     "x = 1\nprint(x)",
+		},
+		{
+			name:     "indentation kept for python",
+			language: "python",
+			code:     "if x:\n    print(x)   \n",
+			want:     "if x:\n    print(x)",
+		},
+		{
+			name:     "indentation dropped for c",
+			language: "c",
+			code:     "int main() {\n    return   0; /* done */\n}\r\n",
+			want:     "int main() {\nreturn 0;\n}",
+		},
+		{
+			name:     "whitespace in string literals kept",
+			language: "python",
+			code:     `print("a  b")`,
+			want:     `print("a  b")`,
+		},
+		{
+			name:     "comment markers in string literals kept",
+			language: "javascript",
+			code:     "const url = \"http://x\" // link\nconst s = `a\n  b`",
+			want:     "const url = \"http://x\"\nconst s = `a\n  b`",
+		},
+		{
+			name:     "backslash ending an unterminated literal",
+			language: "python",
+			code:     `x = "abc\`,
+			want:     `x = "abc\`,
+		},
+		{
+			name:     "unterminated literal",
+			language: "go",
+			code:     "s := \"abc  ",
+			want:     "s := \"abc  ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.language, tt.code); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheKeyDistinguishesLiteralWhitespace(t *testing.T) {
+	if CacheKey("python", `print("a  b")`, "v1") == CacheKey("python", `print("a b")`, "v1") {
+		t.Error("programs differing in string literal whitespace share a cache key")
+	}
+	if CacheKey("python", "x = 1  # one", "v1") != CacheKey("python", "x = 1", "v1") {
+		t.Error("programs differing in comments have different cache keys")
+	}
+}
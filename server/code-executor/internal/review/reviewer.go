@@ -0,0 +1,52 @@
+package review
+
+import "context"
+
+// Submission is a piece of learner code sent for review or hints
+type Submission struct {
+	ID       string
+	UserID   string
+	CourseID string
+	Language string
+	Code     string
+}
+
+// ExecutionSummary is the latest execution result of a submission
+type ExecutionSummary struct {
+	Stdout         string
+	Stderr         string
+	ExitCode       int
+	Timeout        bool
+	MemoryExceeded bool
+}
+
+// HintPrompt contains everything a reviewer needs to produce a hint
+type HintPrompt struct {
+	Submission Submission
+	Execution  *ExecutionSummary
+	Level      int // Level of the hint to produce, starting at 1
+	MaxLevel   int
+}
+
+// TokenUsage is the number of model tokens consumed by a request
+type TokenUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+// Result is the output of a reviewer
+type Result struct {
+	Text    string
+	Version string // Version of the reviewer that produced the result
+	Usage   TokenUsage
+}
+
+// Reviewer is the backend producing code reviews and hints
+type Reviewer interface {
+	// Version identifies the model and prompt, so cached reviews are not reused across them
+	Version() string
+	// Review returns a review of the submission
+	Review(ctx context.Context, submission Submission) (*Result, error)
+	// Hint returns a hint for the requested level. Higher levels are more specific.
+	Hint(ctx context.Context, prompt HintPrompt) (*Result, error)
+}
@@ -0,0 +1,119 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lineNumberPattern matches line references in common stack traces and compiler errors
+var lineNumberPattern = regexp.MustCompile(`(?:line |\.\w+:)(\d+)`)
+
+// StubReviewer is an offline reviewer that needs no model access
+type StubReviewer struct{}
+
+// NewStubReviewer creates a new offline reviewer
+func NewStubReviewer() *StubReviewer {
+	return &StubReviewer{}
+}
+
+// Version returns the stub reviewer version
+func (r *StubReviewer) Version() string {
+	return "stub-v1"
+}
+
+// Review returns a canned review
+func (r *StubReviewer) Review(ctx context.Context, submission Submission) (*Result, error) {
+	return &Result{
+		Text:    "Style: Good\nCorrectness: Needs improvement\nSuggestions: Consider refactoring.",
+		Version: r.Version(),
+	}, nil
+}
+
+// Hint derives a hint from the execution result
+func (r *StubReviewer) Hint(ctx context.Context, prompt HintPrompt) (*Result, error) {
+	return &Result{
+		Text:    stubHint(prompt),
+		Version: r.Version(),
+	}, nil
+}
+
+// stubHint picks a hint for the level. Each level narrows down where the
+// problem is without ever saying how to fix it.
+func stubHint(prompt HintPrompt) string {
+	exec := prompt.Execution
+	if exec == nil {
+		exec = &ExecutionSummary{}
+	}
+
+	errorLine := lastNonEmptyLine(exec.Stderr)
+
+	switch prompt.Level {
+	case 1:
+		switch {
+		case exec.Timeout:
+			return "Your program did not finish in time. Think about whether every loop in your code eventually stops."
+		case exec.MemoryExceeded:
+			return "Your program ran out of memory. Think about which data structures keep growing while it runs."
+		case errorLine != "" || exec.ExitCode != 0:
+			return "Your program stopped with an error. Read the last lines of the error output carefully."
+		default:
+			return "Your program runs without errors. Compare its output with what the exercise expects, including edge cases."
+		}
+	case 2:
+		if errorLine != "" {
+			return fmt.Sprintf("The error reported is: %q. What does this error usually mean?", errorLine)
+		}
+		if exec.Timeout {
+			return "Look for a loop whose condition never becomes false, or a recursive call without a base case."
+		}
+		return "Try your code with the smallest possible input, such as an empty list or zero, and check the result by hand."
+	case 3:
+		if line := errorLineNumber(exec.Stderr); line > 0 {
+			return fmt.Sprintf("The problem shows up around line %d. Check the values of the variables used there.", line)
+		}
+		return "Add a print statement before and after the part you are least sure about, and check that the values are what you expect."
+	default:
+		if line := errorLineNumber(exec.Stderr); line > 0 {
+			if source := sourceLine(prompt.Submission.Code, line); source != "" {
+				return fmt.Sprintf("Look closely at `%s`. Which assumption about this line does not hold when the program runs?", source)
+			}
+		}
+		return "Write down, step by step, what your program does for one input and compare each step with what the exercise asks for."
+	}
+}
+
+// lastNonEmptyLine returns the last non-blank line of the text
+func lastNonEmptyLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// errorLineNumber returns the last line number referenced in the error output
+func errorLineNumber(stderr string) int {
+	matches := lineNumberPattern.FindAllStringSubmatch(stderr, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	line, err := strconv.Atoi(matches[len(matches)-1][1])
+	if err != nil {
+		return 0
+	}
+	return line
+}
+
+// sourceLine returns the trimmed source line with the given 1-based number
+func sourceLine(code string, line int) string {
+	lines := strings.Split(code, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
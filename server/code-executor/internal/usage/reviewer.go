@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"context"
+	"log"
+
+	"code-executor/internal/review"
+)
+
+// BudgetedReviewer sends requests to a model-backed reviewer while the
+// budgets allow it and falls back to an offline reviewer once they are exhausted
+type BudgetedReviewer struct {
+	primary  review.Reviewer
+	fallback review.Reviewer
+	tracker  *Tracker
+}
+
+// NewBudgetedReviewer creates a reviewer enforcing the tracker's budgets
+func NewBudgetedReviewer(primary, fallback review.Reviewer, tracker *Tracker) *BudgetedReviewer {
+	return &BudgetedReviewer{
+		primary:  primary,
+		fallback: fallback,
+		tracker:  tracker,
+	}
+}
+
+// Version returns the version of the primary reviewer
+func (r *BudgetedReviewer) Version() string {
+	return r.primary.Version()
+}
+
+// Review reviews the submission within budget
+func (r *BudgetedReviewer) Review(ctx context.Context, submission review.Submission) (*review.Result, error) {
+	reviewer := r.pick(submission)
+	result, err := reviewer.Review(ctx, submission)
+	if err != nil {
+		return nil, err
+	}
+	r.tracker.Record(submission.UserID, submission.CourseID, result.Usage)
+	return result, nil
+}
+
+// Hint produces a hint within budget
+func (r *BudgetedReviewer) Hint(ctx context.Context, prompt review.HintPrompt) (*review.Result, error) {
+	reviewer := r.pick(prompt.Submission)
+	result, err := reviewer.Hint(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	r.tracker.Record(prompt.Submission.UserID, prompt.Submission.CourseID, result.Usage)
+	return result, nil
+}
+
+// pick returns the reviewer to use for a submission
+func (r *BudgetedReviewer) pick(submission review.Submission) review.Reviewer {
+	switch r.tracker.Check(submission.UserID, submission.CourseID) {
+	case StatusHardLimit:
+		log.Printf("Budget exhausted for user %q course %q, using %s reviewer", submission.UserID, submission.CourseID, r.fallback.Version())
+		return r.fallback
+	case StatusSoftLimit:
+		log.Printf("Soft budget limit exceeded for user %q course %q", submission.UserID, submission.CourseID)
+	}
+	return r.primary
+}
@@ -0,0 +1,87 @@
+package usage
+
+import (
+	"context"
+	"testing"
+
+	"code-executor/internal/review"
+)
+
+// modelReviewer stands in for a model-backed reviewer, reporting the same
+// token usage for every request
+type modelReviewer struct {
+	usage review.TokenUsage
+	calls int
+}
+
+func (r *modelReviewer) Version() string {
+	return "model-v1"
+}
+
+func (r *modelReviewer) Review(ctx context.Context, submission review.Submission) (*review.Result, error) {
+	r.calls++
+	return &review.Result{Text: "Style: Good", Version: r.Version(), Usage: r.usage}, nil
+}
+
+func (r *modelReviewer) Hint(ctx context.Context, prompt review.HintPrompt) (*review.Result, error) {
+	r.calls++
+	return &review.Result{Text: "Look at the loop.", Version: r.Version(), Usage: r.usage}, nil
+}
+
+func TestBudgetedReviewerFallsBackOverBudget(t *testing.T) {
+	// Every request costs $0.50, a user may spend $1 a day
+	tracker := NewTracker(Config{
+		InputCostPerMillion:  1,
+		OutputCostPerMillion: 1,
+		PerUser:              PeriodLimits{Daily: Limit{Soft: 0.5, Hard: 1}},
+	})
+	primary := &modelReviewer{usage: review.TokenUsage{InputTokens: 400_000, OutputTokens: 100_000}}
+	fallback := review.NewStubReviewer()
+	reviewer := NewBudgetedReviewer(primary, fallback, tracker)
+
+	submission := review.Submission{UserID: "u-1", CourseID: "c-1", Language: "python", Code: "print(total)"}
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		hint        bool
+		wantVersion string
+		wantStatus  Status
+	}{
+		{name: "within budget", wantVersion: "model-v1", wantStatus: StatusSoftLimit},
+		{name: "over the soft limit", hint: true, wantVersion: "model-v1", wantStatus: StatusHardLimit},
+		{name: "over the hard limit", wantVersion: fallback.Version(), wantStatus: StatusHardLimit},
+		{name: "hint over the hard limit", hint: true, wantVersion: fallback.Version(), wantStatus: StatusHardLimit},
+	}
+	for _, tt := range tests {
+		var result *review.Result
+		var err error
+		if tt.hint {
+			result, err = reviewer.Hint(ctx, review.HintPrompt{Submission: submission, Level: 1, MaxLevel: review.MaxHintLevel})
+		} else {
+			result, err = reviewer.Review(ctx, submission)
+		}
+		if err != nil {
+			t.Fatalf("%s: error = %v", tt.name, err)
+		}
+		if result.Version != tt.wantVersion {
+			t.Errorf("%s: answered by %s, want %s", tt.name, result.Version, tt.wantVersion)
+		}
+		if status := tracker.Check(submission.UserID, submission.CourseID); status != tt.wantStatus {
+			t.Errorf("%s: status %s, want %s", tt.name, status, tt.wantStatus)
+		}
+	}
+
+	if primary.calls != 2 {
+		t.Errorf("model called %d times, want 2", primary.calls)
+	}
+	report := tracker.Report(submission.UserID, "")
+	if report.User.Daily.Totals.Requests != 4 || report.User.Daily.Totals.InputTokens != 800_000 {
+		t.Errorf("user totals %+v, want 4 requests with 800000 input tokens", report.User.Daily.Totals)
+	}
+
+	// Other users still reach the model
+	if result, err := reviewer.Review(ctx, review.Submission{UserID: "u-2", Code: "x"}); err != nil || result.Version != "model-v1" {
+		t.Errorf("other user answered by %v, error %v, want model-v1", result, err)
+	}
+}
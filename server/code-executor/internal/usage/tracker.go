@@ -0,0 +1,243 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"code-executor/internal/review"
+)
+
+// Status describes where spending stands relative to the budgets
+type Status string
+
+const (
+	StatusOK        Status = "ok"
+	StatusSoftLimit Status = "soft_limit" // Soft limit exceeded, requests still reach the model
+	StatusHardLimit Status = "hard_limit" // Hard limit exceeded, requests are degraded
+)
+
+// Limit is a spending limit in USD. Zero means unlimited.
+type Limit struct {
+	Soft float64 `json:"soft"`
+	Hard float64 `json:"hard"`
+}
+
+// PeriodLimits contains the limits for each budget period
+type PeriodLimits struct {
+	Daily   Limit `json:"daily"`
+	Monthly Limit `json:"monthly"`
+}
+
+// Config contains model pricing and budgets
+type Config struct {
+	InputCostPerMillion  float64      `json:"input_cost_per_million"`  // USD per million input tokens
+	OutputCostPerMillion float64      `json:"output_cost_per_million"` // USD per million output tokens
+	Global               PeriodLimits `json:"global"`
+	PerUser              PeriodLimits `json:"per_user"`
+	PerCourse            PeriodLimits `json:"per_course"`
+}
+
+// LoadConfig reads a budget configuration from a JSON file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse budget config: %w", err)
+	}
+	return &config, nil
+}
+
+// Totals is the usage accumulated in a budget period
+type Totals struct {
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost_usd"`
+}
+
+// PeriodReport is the usage of one scope in one budget period
+type PeriodReport struct {
+	Period string `json:"period"`
+	Totals Totals `json:"totals"`
+	Limit  Limit  `json:"limit"`
+	Status Status `json:"status"`
+}
+
+// ScopeReport is the usage of one scope for the current day and month
+type ScopeReport struct {
+	ID      string       `json:"id,omitempty"`
+	Daily   PeriodReport `json:"daily"`
+	Monthly PeriodReport `json:"monthly"`
+	Status  Status       `json:"status"`
+}
+
+// Report is the usage relevant to a user and course
+type Report struct {
+	Global ScopeReport  `json:"global"`
+	User   *ScopeReport `json:"user,omitempty"`
+	Course *ScopeReport `json:"course,omitempty"`
+	Status Status       `json:"status"`
+}
+
+// scope identifies whose spending a counter tracks
+type scope struct {
+	kind string // "global", "user" or "course"
+	id   string
+}
+
+// counterKey identifies the counter of a scope in a period
+type counterKey struct {
+	scope  scope
+	period string
+}
+
+// Tracker accumulates token usage and enforces budgets
+type Tracker struct {
+	mu       sync.Mutex
+	config   Config
+	counters map[counterKey]*Totals
+	now      func() time.Time
+}
+
+// NewTracker creates a new in-memory usage tracker
+func NewTracker(config Config) *Tracker {
+	return &Tracker{
+		config:   config,
+		counters: make(map[counterKey]*Totals),
+		now:      time.Now,
+	}
+}
+
+// Record adds the usage of one request for a user and course
+func (t *Tracker) Record(userID, courseID string, tokens review.TokenUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	cost := float64(tokens.InputTokens)*t.config.InputCostPerMillion/1e6 +
+		float64(tokens.OutputTokens)*t.config.OutputCostPerMillion/1e6
+
+	t.prune(now)
+	for _, s := range t.scopes(userID, courseID) {
+		for _, period := range []string{dayPeriod(now), monthPeriod(now)} {
+			key := counterKey{scope: s, period: period}
+			totals, ok := t.counters[key]
+			if !ok {
+				totals = &Totals{}
+				t.counters[key] = totals
+			}
+			totals.Requests++
+			totals.InputTokens += tokens.InputTokens
+			totals.OutputTokens += tokens.OutputTokens
+			totals.Cost += cost
+		}
+	}
+}
+
+// Check returns the most severe budget status for a user and course
+func (t *Tracker) Check(userID, courseID string) Status {
+	return t.Report(userID, courseID).Status
+}
+
+// Report returns the current usage for a user and course
+func (t *Tracker) Report(userID, courseID string) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	report := Report{
+		Global: t.scopeReport(scope{kind: "global"}, t.config.Global, now),
+	}
+	report.Status = report.Global.Status
+
+	if userID != "" {
+		user := t.scopeReport(scope{kind: "user", id: userID}, t.config.PerUser, now)
+		report.User = &user
+		report.Status = worst(report.Status, user.Status)
+	}
+	if courseID != "" {
+		course := t.scopeReport(scope{kind: "course", id: courseID}, t.config.PerCourse, now)
+		report.Course = &course
+		report.Status = worst(report.Status, course.Status)
+	}
+	return report
+}
+
+// scopes returns the scopes a request counts against. The caller must hold the lock.
+func (t *Tracker) scopes(userID, courseID string) []scope {
+	scopes := []scope{{kind: "global"}}
+	if userID != "" {
+		scopes = append(scopes, scope{kind: "user", id: userID})
+	}
+	if courseID != "" {
+		scopes = append(scopes, scope{kind: "course", id: courseID})
+	}
+	return scopes
+}
+
+// scopeReport builds the report of a scope. The caller must hold the lock.
+func (t *Tracker) scopeReport(s scope, limits PeriodLimits, now time.Time) ScopeReport {
+	report := ScopeReport{
+		ID:      s.id,
+		Daily:   t.periodReport(s, dayPeriod(now), limits.Daily),
+		Monthly: t.periodReport(s, monthPeriod(now), limits.Monthly),
+	}
+	report.Status = worst(report.Daily.Status, report.Monthly.Status)
+	return report
+}
+
+// periodReport builds the report of a scope in a period. The caller must hold the lock.
+func (t *Tracker) periodReport(s scope, period string, limit Limit) PeriodReport {
+	report := PeriodReport{
+		Period: period,
+		Limit:  limit,
+		Status: StatusOK,
+	}
+	if totals, ok := t.counters[counterKey{scope: s, period: period}]; ok {
+		report.Totals = *totals
+	}
+
+	switch {
+	case limit.Hard > 0 && report.Totals.Cost >= limit.Hard:
+		report.Status = StatusHardLimit
+	case limit.Soft > 0 && report.Totals.Cost >= limit.Soft:
+		report.Status = StatusSoftLimit
+	}
+	return report
+}
+
+// prune drops counters of past periods. The caller must hold the lock.
+func (t *Tracker) prune(now time.Time) {
+	day, month := dayPeriod(now), monthPeriod(now)
+	for key := range t.counters {
+		if key.period != day && key.period != month {
+			delete(t.counters, key)
+		}
+	}
+}
+
+// worst returns the more severe of two statuses
+func worst(a, b Status) Status {
+	severity := map[Status]int{StatusOK: 0, StatusSoftLimit: 1, StatusHardLimit: 2}
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// dayPeriod returns the daily budget period of a time
+func dayPeriod(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// monthPeriod returns the monthly budget period of a time
+func monthPeriod(t time.Time) string {
+	return t.Format("2006-01")
+}